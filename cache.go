@@ -2,11 +2,13 @@ package goi
 
 import (
 	"bytes"
-	"container/list"
+	"container/heap"
 	"context"
 	"encoding/gob"
+	"errors"
 	"math/rand"
 	"reflect"
+	"sort"
 	"sync"
 	"time"
 
@@ -57,65 +59,105 @@ var expirationPolicyNames = map[ExpirationPolicy]string{
 	Scheduled: "Scheduled",
 }
 
-// cache 缓存管理器
+// MemoryCacheSettings 内存缓存配置
 //
 // 字段:
 //   - EvictPolicy EvictPolicy: 缓存淘汰策略
 //   - ExpirationPolicy ExpirationPolicy: 过期策略
 //   - MaxSize int64: 缓存最大容量(字节)
-//   - usedSize int64: 当前已使用容量
-//   - list *list.List: 缓存项双向链表
-//   - dict map[string]*list.Element: 缓存键值映射
-//   - lock sync.RWMutex: 读写锁
-type cache struct {
+//   - SnapshotPath string: 快照文件路径，相对路径基于 Settings.BaseDir，为空时不启用快照
+//   - SnapshotInterval time.Duration: 定期快照间隔，0 表示仅在关闭服务时保存
+type MemoryCacheSettings struct {
 	EvictPolicy      EvictPolicy
 	ExpirationPolicy ExpirationPolicy
 	MaxSize          int64
-	usedSize         int64
-	list             *list.List
-	dict             map[string]*list.Element
-	lock             sync.RWMutex
+	SnapshotPath     string
+	SnapshotInterval time.Duration
+}
+
+// MemoryCache 内存缓存后端
+//
+// 字段:
+//   - *MemoryCacheSettings: 内存缓存配置
+//   - usedSize int64: 当前已使用容量
+//   - dict map[string]*cacheItems: 缓存键值映射
+//   - allKeys evictionIndex: 所有键的淘汰索引
+//   - volatile evictionIndex: 设置了过期时间的键的淘汰索引
+//   - ttl ttlHeap: 按过期时间排序的最小堆
+//   - stats CacheStats: 统计计数，Items、UsedSize、MaxSize 在 Stats 中计算
+//   - subscribers []*cacheSubscriber: 事件订阅者
+//   - lock sync.RWMutex: 读写锁
+//   - eventLock sync.RWMutex: 事件订阅者读写锁
+type MemoryCache struct {
+	*MemoryCacheSettings
+	usedSize    int64
+	dict        map[string]*cacheItems
+	allKeys     evictionIndex
+	volatile    evictionIndex
+	ttl         ttlHeap
+	stats       CacheStats
+	subscribers []*cacheSubscriber
+	lock        sync.RWMutex
+	eventLock   sync.RWMutex
 }
 
 // cacheItems 缓存项
 //
 // 字段:
 //   - key string: 缓存键
-//   - valueType reflect.Type: 值类型
+//   - kind reflect.Kind: 值类型的种类
 //   - bytes []byte: 序列化后的值
 //   - expires time.Time: 过期时间
-//   - mutex sync.Mutex: 互斥锁
 //   - lru time.Time: 最近访问时间
 //   - lfu uint8: 访问频率计数
+//   - allKeys indexElements: 在所有键淘汰索引中的位置
+//   - volatile indexElements: 在过期键淘汰索引中的位置
+//   - heapIndex int: 在过期时间堆中的下标，-1 表示不在堆中
 type cacheItems struct {
 	key       string
-	valueType reflect.Type
+	kind      reflect.Kind
 	bytes     []byte
 	expires   time.Time
-	mutex     sync.Mutex
 	lru       time.Time
 	lfu       uint8
+	allKeys   indexElements
+	volatile  indexElements
+	heapIndex int
+}
+
+// size 缓存项占用容量
+//
+// 返回:
+//   - int64: 键与值的字节数
+func (self *cacheItems) size() int64 {
+	return int64(len(self.key) + len(self.bytes))
 }
 
-// newCache 创建新的缓存管理器
+// NewMemoryCache 创建内存缓存
 //
 // 返回:
-//   - *cache: 新创建的缓存管理器实例
-func newCache() *cache {
-	cache := &cache{
-		EvictPolicy:      NoEviction,
-		ExpirationPolicy: Periodic,
-		MaxSize:          0,
-		usedSize:         0,
-		list:             list.New(),
-		dict:             make(map[string]*list.Element),
-		lock:             sync.RWMutex{},
+//   - *MemoryCache: 内存缓存实例
+func NewMemoryCache() *MemoryCache {
+	cache := &MemoryCache{
+		MemoryCacheSettings: &MemoryCacheSettings{
+			EvictPolicy:      NoEviction,
+			ExpirationPolicy: Periodic,
+			MaxSize:          0,
+			SnapshotPath:     "",
+			SnapshotInterval: 5 * time.Minute,
+		},
+		usedSize: 0,
+		dict:     make(map[string]*cacheItems),
+		lock:     sync.RWMutex{},
 	}
 	return cache
 }
 
 // initCache 初始化缓存配置
-func (self *cache) initCache() {
+//
+// 参数:
+//   - engine *Engine: 注册缓存后台任务与关闭回调的 Engine
+func (self *MemoryCache) initCache(engine *Engine) {
 	MaxSizeMsg := i18n.T("server.cache.max_size", map[string]any{
 		"max_size": FormatBytes(self.MaxSize),
 	})
@@ -129,48 +171,263 @@ func (self *cache) initCache() {
 	})
 	Log.Log(meta, ExpirationPolicyMsg)
 	if self.ExpirationPolicy == Periodic {
-		RegisterOnStartup(self)
+		engine.registerOnStartup(self)
+	}
+	if self.SnapshotPath != "" {
+		self.initSnapshot(engine)
+	}
+}
+
+// link 将缓存项加入淘汰索引与过期时间堆，调用前需持有写锁
+//
+// 参数:
+//   - cacheItem *cacheItems: 缓存项
+func (self *MemoryCache) link(cacheItem *cacheItems) {
+	self.allKeys.push(cacheItem, &cacheItem.allKeys)
+	cacheItem.heapIndex = -1
+	if !cacheItem.expires.IsZero() {
+		self.volatile.push(cacheItem, &cacheItem.volatile)
+		heap.Push(&self.ttl, cacheItem)
+	}
+}
+
+// unlink 将缓存项移出淘汰索引与过期时间堆，调用前需持有写锁
+//
+// 参数:
+//   - cacheItem *cacheItems: 缓存项
+func (self *MemoryCache) unlink(cacheItem *cacheItems) {
+	self.allKeys.remove(cacheItem, &cacheItem.allKeys)
+	if !cacheItem.expires.IsZero() {
+		self.volatile.remove(cacheItem, &cacheItem.volatile)
+	}
+	if cacheItem.heapIndex >= 0 {
+		heap.Remove(&self.ttl, cacheItem.heapIndex)
+	}
+}
+
+// touch 记录一次访问，更新 LRU 顺序与 LFU 计数，调用前需持有写锁
+//
+// 参数:
+//   - cacheItem *cacheItems: 缓存项
+//   - nowTime time.Time: 当前时间
+func (self *MemoryCache) touch(cacheItem *cacheItems, nowTime time.Time) {
+	counter := cacheItem.lfu
+	cacheItem.lru = nowTime
+	cacheItem.lfu = LFULogIncr(counter)
+	self.allKeys.touch(cacheItem, &cacheItem.allKeys, counter)
+	if !cacheItem.expires.IsZero() {
+		self.volatile.touch(cacheItem, &cacheItem.volatile, counter)
+	}
+}
+
+// setExpires 修改缓存项的过期时间，调用前需持有写锁
+//
+// 参数:
+//   - cacheItem *cacheItems: 缓存项
+//   - expiresTime time.Time: 过期时间，零值表示永不过期
+func (self *MemoryCache) setExpires(cacheItem *cacheItems, expiresTime time.Time) {
+	switch {
+	case cacheItem.expires.IsZero() && !expiresTime.IsZero():
+		cacheItem.expires = expiresTime
+		self.volatile.push(cacheItem, &cacheItem.volatile)
+		heap.Push(&self.ttl, cacheItem)
+	case !cacheItem.expires.IsZero() && expiresTime.IsZero():
+		self.volatile.remove(cacheItem, &cacheItem.volatile)
+		heap.Remove(&self.ttl, cacheItem.heapIndex)
+		cacheItem.expires = expiresTime
+	case !cacheItem.expires.IsZero():
+		cacheItem.expires = expiresTime
+		heap.Fix(&self.ttl, cacheItem.heapIndex)
+	}
+}
+
+// remove 删除缓存项，调用前需持有写锁
+//
+// 参数:
+//   - cacheItem *cacheItems: 要删除的缓存项
+func (self *MemoryCache) remove(cacheItem *cacheItems) {
+	if self.dict[cacheItem.key] != cacheItem {
+		return
 	}
+	self.unlink(cacheItem)
+	delete(self.dict, cacheItem.key)
+	self.usedSize -= cacheItem.size()
 }
 
-// get 获取缓存项
+// expire 删除已过期的缓存项，调用前需持有写锁
+//
+// 参数:
+//   - cacheItem *cacheItems: 已过期的缓存项
+func (self *MemoryCache) expire(cacheItem *cacheItems) {
+	self.remove(cacheItem)
+	self.stats.Expirations++
+	self.emit(CacheEventExpired, cacheItem.key)
+}
+
+// lookup 查找未过期的缓存项，已过期时删除，调用前需持有写锁
 //
 // 参数:
 //   - key string: 缓存键
+//   - nowTime time.Time: 当前时间
 //
 // 返回:
-//   - *list.Element: 缓存项元素
-//   - bool: 是否存在该键
-func (self *cache) get(key string) (*list.Element, bool) {
-	self.lock.RLock()
-	element, ok := self.dict[key]
-	self.lock.RUnlock()
-	return element, ok
+//   - *cacheItems: 缓存项
+//   - bool: 是否存在且未过期
+func (self *MemoryCache) lookup(key string, nowTime time.Time) (*cacheItems, bool) {
+	cacheItem, ok := self.dict[key]
+	if !ok {
+		return nil, false
+	}
+	if !cacheItem.expires.IsZero() && !cacheItem.expires.After(nowTime) {
+		self.expire(cacheItem)
+		return nil, false
+	}
+	return cacheItem, true
 }
 
-// set 设置缓存项
+// store 写入缓存项，超出最大容量时先按淘汰策略腾出空间，调用前需持有写锁
 //
 // 参数:
-//   - cacheItem *cacheItems: 要设置的缓存项
-func (self *cache) set(cacheItem *cacheItems) {
-	self.lock.Lock()
-	element := self.list.PushFront(cacheItem)
-	self.dict[cacheItem.key] = element
-	self.usedSize += int64(len(cacheItem.key) + len(cacheItem.bytes))
-	self.lock.Unlock()
+//   - key string: 缓存键
+//   - kind reflect.Kind: 值类型的种类
+//   - data []byte: 序列化后的值
+//   - expiresTime time.Time: 过期时间，零值表示永不过期
+//
+// 返回:
+//   - error: 无法腾出足够空间时返回错误，此时不写入
+func (self *MemoryCache) store(key string, kind reflect.Kind, data []byte, expiresTime time.Time) error {
+	err := self.reserve(key, int64(len(key)+len(data)))
+	if err != nil {
+		return err
+	}
+	self.stats.Sets++
+	self.emit(CacheEventSet, key)
+	nowTime := GetTime()
+	if cacheItem, ok := self.dict[key]; ok {
+		self.usedSize += int64(len(data) - len(cacheItem.bytes))
+		cacheItem.kind = kind
+		cacheItem.bytes = data
+		self.setExpires(cacheItem, expiresTime)
+		self.touch(cacheItem, nowTime)
+		return nil
+	}
+	cacheItem := &cacheItems{
+		key:     key,
+		kind:    kind,
+		bytes:   data,
+		expires: expiresTime,
+		lru:     nowTime,
+		lfu:     LFUInitValue,
+	}
+	self.dict[key] = cacheItem
+	self.link(cacheItem)
+	self.usedSize += cacheItem.size()
+	return nil
 }
 
-// del 删除缓存项
+// reserve 按淘汰策略为写入腾出空间，调用前需持有写锁
 //
 // 参数:
-//   - element *list.Element: 要删除的缓存项元素
-func (self *cache) del(element *list.Element) {
-	cacheItem := element.Value.(*cacheItems)
-	self.lock.Lock()
-	self.list.Remove(element)
-	delete(self.dict, cacheItem.key)
-	self.usedSize -= int64(len(cacheItem.key) + len(cacheItem.bytes))
-	self.lock.Unlock()
+//   - key string: 将要写入的缓存键
+//   - size int64: 写入后该缓存项占用的容量
+//
+// 返回:
+//   - error: NoEviction 策略下超出最大容量，或没有可淘汰的键时返回错误
+func (self *MemoryCache) reserve(key string, size int64) error {
+	if self.MaxSize == 0 {
+		return nil
+	}
+	for {
+		delta := size
+		if cacheItem, ok := self.dict[key]; ok {
+			delta -= cacheItem.size()
+		}
+		if self.usedSize+delta <= self.MaxSize {
+			return nil
+		}
+		if self.EvictPolicy == NoEviction {
+			noEvictionMsg := i18n.T("server.cache.noeviction", map[string]any{
+				"max_size": FormatBytes(self.MaxSize),
+			})
+			return errors.New(noEvictionMsg)
+		}
+		victim := self.victim()
+		if victim == nil {
+			noEvictableMsg := i18n.T("server.cache.no_evictable", map[string]any{
+				"max_size": FormatBytes(self.MaxSize),
+			})
+			return errors.New(noEvictableMsg)
+		}
+		self.remove(victim)
+		self.stats.Evictions++
+		self.emit(CacheEventEvicted, victim.key)
+	}
+}
+
+// victim 按淘汰策略选出要淘汰的缓存项，调用前需持有写锁
+//
+// 返回:
+//   - *cacheItems: 要淘汰的缓存项，没有可淘汰的键时为 nil
+func (self *MemoryCache) victim() *cacheItems {
+	switch self.EvictPolicy {
+	case AllKeysRandom: // 所有的键 随机移除 key
+		for _, cacheItem := range self.dict {
+			return cacheItem
+		}
+	case AllKeysLRU: // 所有的键 移除最近最少使用的 key
+		return self.allKeys.leastRecent()
+	case AllKeysLFU: // 所有的键 移除最近最不频繁使用的 key
+		return self.allKeys.leastFrequent()
+	case VolatileRandom: // 所有设置了过期时间的键 随机移除 key
+		if len(self.ttl) > 0 {
+			return self.ttl[rand.Intn(len(self.ttl))]
+		}
+	case VolatileLRU: // 所有设置了过期时间的键 移除最近最少使用的 key
+		return self.volatile.leastRecent()
+	case VolatileLFU: // 所有设置了过期时间的键 移除最近最不频繁使用的 key
+		return self.volatile.leastFrequent()
+	case VolatileTTL: // 所有设置了过期时间的键 移除快过期的 key
+		if len(self.ttl) > 0 {
+			return self.ttl[0]
+		}
+	}
+	return nil
+}
+
+// encodeValue 序列化缓存值
+//
+// 参数:
+//   - value any: 缓存值
+//
+// 返回:
+//   - []byte: gob 编码结果
+//   - error: 编码过程中的错误信息
+func encodeValue(value any) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := gob.NewEncoder(&buffer)
+	err := encoder.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// expiresAt 计算过期时间
+//
+// 参数:
+//   - key string: 缓存键，定时删除策略下到期后删除
+//   - expires int: 过期时间(秒)，0表示永不过期
+//
+// 返回:
+//   - time.Time: 过期时间，永不过期为零值
+func (self *MemoryCache) expiresAt(key string, expires int) time.Time {
+	if expires <= 0 {
+		return time.Time{}
+	}
+	if self.ExpirationPolicy == Scheduled { // 定时删除
+		time.AfterFunc(time.Second*time.Duration(expires), func() { self.DelExp(key) })
+	}
+	return GetTime().Add(time.Second * time.Duration(expires))
 }
 
 // Has 检查键是否存在
@@ -179,12 +436,47 @@ func (self *cache) del(element *list.Element) {
 //   - key string: 要检查的键
 //
 // 返回:
-//   - bool: 是否存在该键
-func (self *cache) Has(key string) bool {
+//   - bool: 是否存在该键，已过期视为不存在
+func (self *MemoryCache) Has(key string) bool {
 	self.lock.RLock()
-	_, ok := self.dict[key]
-	self.lock.RUnlock()
-	return ok
+	defer self.lock.RUnlock()
+	cacheItem, ok := self.dict[key]
+	if !ok {
+		return false
+	}
+	return cacheItem.expires.IsZero() || cacheItem.expires.After(GetTime())
+}
+
+// Load 获取缓存值并返回是否命中
+//
+// 参数:
+//   - key string: 缓存键
+//   - value any: 用于存储解码后的值的指针
+//
+// 返回:
+//   - bool: 是否命中，键不存在或已过期返回 false
+//   - error: 解码过程中的错误信息
+func (self *MemoryCache) Load(key string, value any) (bool, error) {
+	nowTime := GetTime()
+	self.lock.Lock()
+	cacheItem, ok := self.lookup(key, nowTime)
+	if !ok {
+		self.stats.Misses++
+		self.lock.Unlock()
+		return false, nil
+	}
+	self.stats.Hits++
+	self.touch(cacheItem, nowTime)
+	data := cacheItem.bytes // 写入时整体替换，不会原地修改
+	self.lock.Unlock()
+
+	// 将字节解码回原始值类型
+	decoder := gob.NewDecoder(bytes.NewReader(data))
+	err := decoder.Decode(value)
+	if err != nil {
+		return true, err
+	}
+	return true, nil
 }
 
 // Get 获取缓存值
@@ -195,31 +487,9 @@ func (self *cache) Has(key string) bool {
 //
 // 返回:
 //   - error: 获取过程中的错误信息
-func (self *cache) Get(key string, value any) error {
-	if element, ok := self.get(key); ok {
-		cacheItem := element.Value.(*cacheItems)
-
-		nowTime := GetTime()                                                // 获取当前时间
-		if cacheItem.expires.IsZero() || cacheItem.expires.After(nowTime) { // 判断是否过期
-			cacheItem.mutex.Lock()
-			cacheItem.lru = nowTime
-			cacheItem.lfu = LFULogIncr(cacheItem.lfu)
-			cacheItem.mutex.Unlock()
-
-			// 将字节解码回原始值类型
-			reader := bytes.NewReader(cacheItem.bytes)
-			decoder := gob.NewDecoder(reader)
-			err := decoder.Decode(value)
-			if err != nil {
-				return err
-			}
-			return nil
-		}
-		// 缓存过期删除
-		self.DelExp(key)
-		return nil
-	}
-	return nil
+func (self *MemoryCache) Get(key string, value any) error {
+	_, err := self.Load(key, value)
+	return err
 }
 
 // Set 设置缓存键值对
@@ -231,393 +501,288 @@ func (self *cache) Get(key string, value any) error {
 //
 // 返回:
 //   - error: 设置过程中的错误信息
-func (self *cache) Set(key string, value any, expires int) error {
-	var buffer bytes.Buffer
-	encoder := gob.NewEncoder(&buffer)
-	err := encoder.Encode(value)
+func (self *MemoryCache) Set(key string, value any, expires int) error {
+	data, err := encodeValue(value)
 	if err != nil {
 		return err
 	}
-	var expiresTime time.Time
-	if expires > 0 {
-		expiresTime = GetTime().Add(time.Second * time.Duration(expires)) // 获取过期时间
-		if self.ExpirationPolicy == Scheduled {                           // 定时删除
-			time.AfterFunc(time.Duration(expires), func() { self.DelExp(key) })
-		}
+	expiresTime := self.expiresAt(key, expires)
+
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return self.store(key, reflect.ValueOf(value).Kind(), data, expiresTime)
+}
+
+// SetNX 键不存在时设置缓存键值对
+//
+// 参数:
+//   - key string: 缓存键
+//   - value any: 要缓存的值
+//   - expires int: 过期时间(秒)，0表示永不过期
+//
+// 返回:
+//   - bool: 是否设置成功，键已存在返回 false
+//   - error: 设置过程中的错误信息
+func (self *MemoryCache) SetNX(key string, value any, expires int) (bool, error) {
+	data, err := encodeValue(value)
+	if err != nil {
+		return false, err
 	}
-	if element, ok := self.get(key); ok {
-		cacheItem := element.Value.(*cacheItems)
-		cacheItem.mutex.Lock()
-		self.usedSize += int64(buffer.Len() - len(cacheItem.bytes))
-		cacheItem.valueType = reflect.TypeOf(value)
-		cacheItem.bytes = buffer.Bytes()
-		cacheItem.expires = expiresTime
-		cacheItem.mutex.Unlock()
-	} else {
-		cacheItem := &cacheItems{
-			key:       key,
-			valueType: reflect.TypeOf(value),
-			bytes:     buffer.Bytes(),
-			expires:   expiresTime,
-			mutex:     sync.Mutex{},
-			lru:       GetTime(),
-			lfu:       5,
-		}
-		self.set(cacheItem)
+
+	self.lock.Lock()
+	if _, ok := self.lookup(key, GetTime()); ok {
+		self.lock.Unlock()
+		return false, nil
 	}
+	err = self.store(key, reflect.ValueOf(value).Kind(), data, self.expiresAt(key, expires))
+	self.lock.Unlock()
+	return err == nil, err
+}
 
-	for self.MaxSize != 0 && self.MaxSize < self.usedSize {
-		self.cacheEvict()
+// CompareAndSwap 当前值等于 old 时替换为 new
+//
+// 参数:
+//   - key string: 缓存键
+//   - old any: 期望的当前值，按 reflect.DeepEqual 比较
+//   - new any: 新值
+//   - expires int: 新值的过期时间(秒)，0表示永不过期
+//
+// 返回:
+//   - bool: 是否替换成功，键不存在或值不相等返回 false
+//   - error: 编解码过程中的错误信息
+func (self *MemoryCache) CompareAndSwap(key string, old any, new any, expires int) (bool, error) {
+	data, err := encodeValue(new)
+	if err != nil {
+		return false, err
 	}
-	return nil
+
+	self.lock.Lock()
+	cacheItem, ok := self.lookup(key, GetTime())
+	if !ok {
+		self.lock.Unlock()
+		return false, nil
+	}
+	equal, err := equalValue(cacheItem.bytes, old)
+	if err != nil || !equal {
+		self.lock.Unlock()
+		return false, err
+	}
+	err = self.store(key, reflect.ValueOf(new).Kind(), data, self.expiresAt(key, expires))
+	self.lock.Unlock()
+	return err == nil, err
+}
+
+// equalValue 比较序列化的值与期望值
+//
+// 参数:
+//   - data []byte: 序列化后的值
+//   - expected any: 期望值
+//
+// 返回:
+//   - bool: 是否相等
+//   - error: 解码过程中的错误信息
+func equalValue(data []byte, expected any) (bool, error) {
+	if expected == nil {
+		return false, nil
+	}
+	current := reflect.New(reflect.TypeOf(expected))
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(current.Interface())
+	if err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(current.Elem().Interface(), expected), nil
 }
 
 // Del 删除缓存键
 //
 // 参数:
 //   - key string: 要删除的缓存键
-func (self *cache) Del(key string) {
-	if element, ok := self.get(key); ok {
-		self.del(element)
+//
+// 返回:
+//   - error: 删除过程中的错误信息，内存缓存始终为 nil
+func (self *MemoryCache) Del(key string) error {
+	self.lock.Lock()
+	if cacheItem, ok := self.dict[key]; ok {
+		self.remove(cacheItem)
 	}
+	self.lock.Unlock()
+	return nil
 }
 
-// DelExp 删除过期的缓存键
+// Expire 设置缓存键的过期时间
 //
 // 参数:
-//   - key string: 要检查的缓存键
+//   - key string: 缓存键
+//   - expires int: 过期时间(秒)，小于等于 0 时立即删除
 //
 // 返回:
-//   - bool: 如果键已过期并被删除返回true，否则返回false
-func (self *cache) DelExp(key string) bool {
-	if element, ok := self.get(key); ok {
-		cacheItem := element.Value.(*cacheItems)
-
-		nowTime := GetTime() // 获取当前时间
-		if !cacheItem.expires.IsZero() && cacheItem.expires.Before(nowTime) {
-			self.del(element)
-			return true
-		}
+//   - bool: 键是否存在
+//   - error: 设置过程中的错误信息
+func (self *MemoryCache) Expire(key string, expires int) (bool, error) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	cacheItem, ok := self.lookup(key, GetTime())
+	if !ok {
+		return false, nil
 	}
-	return false
+	if expires <= 0 {
+		self.remove(cacheItem)
+		return true, nil
+	}
+	self.setExpires(cacheItem, self.expiresAt(key, expires))
+	return true, nil
 }
 
-// cacheEvict 执行缓存淘汰
-func (self *cache) cacheEvict() {
-	if len(self.dict) == 0 {
-		return
+// Persist 移除缓存键的过期时间
+//
+// 参数:
+//   - key string: 缓存键
+//
+// 返回:
+//   - bool: 键存在且原本设置了过期时间时返回 true
+//   - error: 设置过程中的错误信息
+func (self *MemoryCache) Persist(key string) (bool, error) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	cacheItem, ok := self.lookup(key, GetTime())
+	if !ok || cacheItem.expires.IsZero() {
+		return false, nil
 	}
+	self.setExpires(cacheItem, time.Time{})
+	return true, nil
+}
 
-	switch self.EvictPolicy {
-	case NoEviction: // 直接返回错误，不淘汰任何已经存在的键
-		NoEvictionMsg := i18n.T("server.cache.noeviction", map[string]any{
-			"max_size": FormatBytes(self.MaxSize),
-		})
-		Log.Error(NoEvictionMsg)
-		panic(NoEvictionMsg)
-	case AllKeysRandom: // 所有的键 随机移除 key
-		var elementRemove []*list.Element
-		self.lock.RLock()
-		for _, element := range self.dict {
-			if self.MaxSize > self.usedSize {
-				break
-			}
-			elementRemove = append(elementRemove, element)
-		}
-		self.lock.RUnlock()
-		for _, element := range elementRemove {
-			self.del(element)
-		}
-	case AllKeysLRU: // 所有的键 移除最近最少使用的 key
-		var averageUnix int64
-		var num int64 = 100
-		for self.MaxSize < self.usedSize {
-			var totalUnix int64
-			var i int64 = 0
-
-			self.lock.RLock()
-			for _, element := range self.dict {
-				cacheItem := element.Value.(*cacheItems)
-				totalUnix += cacheItem.lru.Unix()
-				i++
-				if i >= num {
-					break
-				}
-			}
-			if averageUnix != 0 { // 合并两个平均值
-				averageUnix = (averageUnix + (totalUnix / i)) / 2
-			} else {
-				averageUnix = totalUnix / i
-			}
-
-			i = 0
-			var elementRemove []*list.Element
-			for _, element := range self.dict {
-				if self.MaxSize > self.usedSize {
-					break
-				}
-				cacheItem := element.Value.(*cacheItems)
-
-				if averageUnix > cacheItem.lru.Unix() {
-					elementRemove = append(elementRemove, element)
-				}
-				i++
-				if i >= num {
-					break
-				}
-			}
-			self.lock.RUnlock()
-			for _, element := range elementRemove {
-				self.del(element)
-			}
-		}
+// TTL 获取缓存键的剩余过期时间
+//
+// 参数:
+//   - key string: 缓存键
+//
+// 返回:
+//   - time.Duration: 剩余过期时间，永不过期返回 TTLPersistent，不存在返回 TTLNotExists
+//   - error: 获取过程中的错误信息
+func (self *MemoryCache) TTL(key string) (time.Duration, error) {
+	nowTime := GetTime()
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	cacheItem, ok := self.lookup(key, nowTime)
+	if !ok {
+		return TTLNotExists, nil
+	}
+	if cacheItem.expires.IsZero() {
+		return TTLPersistent, nil
+	}
+	return cacheItem.expires.Sub(nowTime), nil
+}
 
-	case AllKeysLFU: // 所有的键 移除最近最不频繁使用的 key
-		var averageCounter uint8
-		var num int64 = 100
-		for self.MaxSize < self.usedSize {
-			var totalCounter int64
-			var i int64 = 0
-
-			self.lock.RLock()
-			for _, element := range self.dict {
-				cacheItem := element.Value.(*cacheItems)
-				totalCounter += int64(cacheItem.lfu)
-				i++
-				if i >= num {
-					break
-				}
-			}
-			if averageCounter != 0 { // 合并两个平均值
-				averageCounter = (averageCounter + uint8(totalCounter/i)) / 2
-			} else {
-				averageCounter = uint8(totalCounter / i)
-			}
-
-			i = 0
-			var elementRemove []*list.Element
-			for _, element := range self.dict {
-				if self.MaxSize > self.usedSize {
-					break
-				}
-				cacheItem := element.Value.(*cacheItems)
-
-				if averageCounter > cacheItem.lfu {
-					elementRemove = append(elementRemove, element)
-				}
-				i++
-				if i >= num {
-					break
-				}
-			}
-			self.lock.RUnlock()
-			for _, element := range elementRemove {
-				self.del(element)
-			}
-		}
-	case VolatileRandom: // 所有设置了过期时间的键 随机移除 key
-		var elementRemove []*list.Element
-		self.lock.RLock()
-		for _, element := range self.dict {
-			if self.MaxSize > self.usedSize {
-				break
-			}
-			cacheItem := element.Value.(*cacheItems)
-			if cacheItem.expires.IsZero() {
-				continue
-			}
-			elementRemove = append(elementRemove, element)
-		}
-		self.lock.RUnlock()
-		for _, element := range elementRemove {
-			self.del(element)
-		}
-	case VolatileLRU: // 所有设置了过期时间的键 移除最近最少使用的 key
-		var averageUnix int64
-		var num int64 = 100
-		for self.MaxSize < self.usedSize {
-			var totalUnix int64
-			var i int64 = 0
-
-			self.lock.RLock()
-			for _, element := range self.dict {
-				cacheItem := element.Value.(*cacheItems)
-				if cacheItem.expires.IsZero() {
-					continue
-				}
-				totalUnix += cacheItem.lru.Unix()
-				i++
-				if i >= num {
-					break
-				}
-			}
-			if averageUnix != 0 { // 合并两个平均值
-				averageUnix = (averageUnix + (totalUnix / i)) / 2
-			} else {
-				averageUnix = totalUnix / i
-			}
-
-			i = 0
-			var elementRemove []*list.Element
-			for _, element := range self.dict {
-				if self.MaxSize > self.usedSize {
-					break
-				}
-				cacheItem := element.Value.(*cacheItems)
-				if cacheItem.expires.IsZero() {
-					continue
-				}
-
-				if averageUnix > cacheItem.lru.Unix() {
-					elementRemove = append(elementRemove, element)
-				}
-				i++
-				if i >= num {
-					break
-				}
-			}
-			self.lock.RUnlock()
-			for _, element := range elementRemove {
-				self.del(element)
-			}
+// Keys 获取匹配模式的缓存键
+//
+// 参数:
+//   - pattern string: glob 模式，支持 "*"、"?"、"[abc]"、"[^a]"、"[a-z]" 与 "\" 转义，例如 "user:*"
+//
+// 返回:
+//   - []string: 按字典序排列的未过期缓存键
+//   - error: 获取过程中的错误信息
+func (self *MemoryCache) Keys(pattern string) ([]string, error) {
+	nowTime := GetTime()
+	self.lock.RLock()
+	keys := make([]string, 0)
+	for key, cacheItem := range self.dict {
+		if !cacheItem.expires.IsZero() && !cacheItem.expires.After(nowTime) {
+			continue
 		}
-	case VolatileLFU: // 所有设置了过期时间的键 移除最近最不频繁使用的 key
-		var averageCounter uint8
-		var num int64 = 100
-		for self.MaxSize < self.usedSize {
-			var totalCounter int64
-			var i int64 = 0
-
-			self.lock.RLock()
-			for _, element := range self.dict {
-				cacheItem := element.Value.(*cacheItems)
-				if cacheItem.expires.IsZero() {
-					continue
-				}
-				totalCounter += int64(cacheItem.lfu)
-				i++
-				if i >= num {
-					break
-				}
-			}
-			if averageCounter != 0 { // 合并两个平均值
-				averageCounter = (averageCounter + uint8(totalCounter/i)) / 2
-			} else {
-				averageCounter = uint8(totalCounter / i)
-			}
-
-			i = 0
-			var elementRemove []*list.Element
-			for _, element := range self.dict {
-				if self.MaxSize > self.usedSize {
-					break
-				}
-				cacheItem := element.Value.(*cacheItems)
-				if cacheItem.expires.IsZero() {
-					continue
-				}
-
-				if averageCounter > cacheItem.lfu {
-					elementRemove = append(elementRemove, element)
-				}
-				i++
-				if i >= num {
-					break
-				}
-			}
-			self.lock.RUnlock()
-			for _, element := range elementRemove {
-				self.del(element)
-			}
+		if MatchKey(pattern, key) {
+			keys = append(keys, key)
 		}
-	case VolatileTTL: // 所有设置了过期时间的键 移除快过期的 key
-		var averageUnix int64
-		var num int64 = 200
-		for self.MaxSize < self.usedSize {
-			var totalUnix int64
-			var i int64 = 0
-
-			self.lock.RLock()
-			for _, element := range self.dict {
-				cacheItem := element.Value.(*cacheItems)
-				if cacheItem.expires.IsZero() {
-					continue
-				}
-				totalUnix += cacheItem.lru.Unix()
-				i++
-				if i >= num {
-					break
-				}
-			}
-			if averageUnix != 0 { // 合并两个平均值
-				averageUnix = (averageUnix + (totalUnix / i)) / 2
-			} else {
-				averageUnix = totalUnix / i
-			}
-
-			i = 0
-			var elementRemove []*list.Element
-			for _, element := range self.dict {
-				if self.MaxSize > self.usedSize {
-					break
-				}
-				cacheItem := element.Value.(*cacheItems)
-				if cacheItem.expires.IsZero() {
-					continue
-				}
-
-				if averageUnix > cacheItem.lru.Unix() {
-					elementRemove = append(elementRemove, element)
-				}
-				i++
-				if i >= num {
-					break
-				}
-			}
-			self.lock.RUnlock()
-			for _, element := range elementRemove {
-				self.del(element)
-			}
+	}
+	self.lock.RUnlock()
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Incr 整数自增
+//
+// 参数:
+//   - key string: 缓存键，不存在或已过期时从 0 开始并且永不过期
+//   - delta int64: 增量，可为负数
+//
+// 返回:
+//   - int64: 自增后的值
+//   - error: 原值不是整数时返回错误
+func (self *MemoryCache) Incr(key string, delta int64) (int64, error) {
+	self.lock.Lock()
+	var current int64
+	var expiresTime time.Time
+	if cacheItem, ok := self.lookup(key, GetTime()); ok {
+		if !isIntegerKind(cacheItem.kind) || gob.NewDecoder(bytes.NewReader(cacheItem.bytes)).Decode(&current) != nil {
+			self.lock.Unlock()
+			notIntegerMsg := i18n.T("server.cache.not_integer", map[string]any{
+				"key": key,
+			})
+			return 0, errors.New(notIntegerMsg)
 		}
+		expiresTime = cacheItem.expires
+	}
+	current += delta
+	data, _ := encodeValue(current)
+	err := self.store(key, reflect.Int64, data, expiresTime)
+	self.lock.Unlock()
+	if err != nil {
+		return 0, err
 	}
+	return current, nil
 }
 
-// cachePeriodicDeleteExpires 定期删除过期缓存项
-func (self *cache) cachePeriodicDeleteExpires() {
-	if self.ExpirationPolicy != Periodic {
-		return
+// isIntegerKind 检查值类型是否为整数
+//
+// 参数:
+//   - kind reflect.Kind: 值类型的种类
+//
+// 返回:
+//   - bool: 是否为整数类型
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
 	}
-	self.lock.RLock()
-	cacheCount := len(self.dict)
-	if cacheCount <= 0 {
-		self.lock.RUnlock()
-		return
+	return false
+}
+
+// DelExp 删除过期的缓存键
+//
+// 参数:
+//   - key string: 要检查的缓存键
+//
+// 返回:
+//   - bool: 如果键已过期并被删除返回true，否则返回false
+func (self *MemoryCache) DelExp(key string) bool {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if _, ok := self.dict[key]; !ok {
+		return false
 	}
-	count := rand.Intn(cacheCount)
-	var elementRemove []*list.Element
-	for _, element := range self.dict {
-		if count <= 0 {
-			break
-		}
-		cacheItem := element.Value.(*cacheItems)
+	_, ok := self.lookup(key, GetTime())
+	return !ok
+}
 
-		nowTime := GetTime() // 获取当前时间
-		if !cacheItem.expires.IsZero() && cacheItem.expires.Before(nowTime) {
-			elementRemove = append(elementRemove, element)
-		}
-		count--
+// cachePeriodicDeleteExpires 定期删除过期缓存项
+func (self *MemoryCache) cachePeriodicDeleteExpires() {
+	if self.ExpirationPolicy != Periodic {
+		return
 	}
-	self.lock.RUnlock()
-	for _, element := range elementRemove {
-		self.del(element)
+	nowTime := GetTime() // 获取当前时间
+	self.lock.Lock()
+	for len(self.ttl) > 0 && !self.ttl[0].expires.After(nowTime) {
+		self.expire(self.ttl[0])
 	}
+	self.lock.Unlock()
 }
 
 // StartupName 启动任务名称
 //
 // 返回:
 //   - string: 启动任务名称
-func (self *cache) StartupName() string {
+func (self *MemoryCache) StartupName() string {
 	return i18n.T("server.cache.periodic_delete_expires")
 }
 
@@ -626,7 +791,7 @@ func (self *cache) StartupName() string {
 // 参数:
 //   - ctx context.Context: 上下文对象，用于控制协程退出
 //   - wg *sync.WaitGroup: 等待组，用于同步协程
-func (self *cache) OnStartup(ctx context.Context, wg *sync.WaitGroup) {
+func (self *MemoryCache) OnStartup(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done() // 确保 goroutine 完成时减少 waitGroup 计数
 
 	ticker := time.NewTicker(3 * time.Second)
@@ -662,7 +827,8 @@ func LFULogIncr(counter uint8) uint8 {
 	}
 
 	r := rand.Float64() // 生成[0.0, 1.0)范围内的随机浮点数
-	baseVal := float64(counter - LFUInitValue)
+	// 先转换再相减，避免 uint8 下溢
+	baseVal := float64(counter) - LFUInitValue
 	if baseVal < 0 {
 		baseVal = 0
 	}
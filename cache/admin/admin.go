@@ -0,0 +1,189 @@
+// Package admin 提供可挂载的缓存管理接口，用于查看统计、按前缀列出与删除缓存键
+//
+// 使用方式:
+//
+//	admin.Register(Server.Router, "admin/cache").Use(authMiddleware)
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NeverStopDreamingWang/goi/v2"
+)
+
+// DefaultLimit 列出缓存键的默认数量上限
+const DefaultLimit = 100
+
+// Stats 统计响应
+//
+// 字段:
+//   - goi.CacheStats: 缓存统计
+//   - HitRatio float64: 读取命中率
+type Stats struct {
+	goi.CacheStats
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// KeyInfo 缓存键信息
+//
+// 字段:
+//   - Key string: 缓存键
+//   - TTL int64: 剩余过期时间(秒)，永不过期为 -1
+type KeyInfo struct {
+	Key string `json:"key"`
+	TTL int64  `json:"ttl"`
+}
+
+// KeyList 缓存键列表响应
+//
+// 字段:
+//   - Total int: 匹配的键数量
+//   - Keys []KeyInfo: 缓存键，最多 limit 个
+type KeyList struct {
+	Total int       `json:"total"`
+	Keys  []KeyInfo `json:"keys"`
+}
+
+// Register 注册缓存管理路由
+//
+// 参数:
+//   - router *goi.Router: 父路由
+//   - path string: 管理接口路径，例如 "admin/cache"
+//
+// 返回:
+//   - *goi.Router: 管理接口路由组，可继续注册鉴权中间件
+//
+// 说明:
+//   - GET <path>/stats: 缓存统计
+//   - GET <path>/keys?prefix=user:&limit=100: 按前缀列出缓存键与剩余过期时间
+//   - DELETE <path>/keys?prefix=user:: 按前缀删除缓存键，prefix 不能为空
+//   - GET <path>/keys/<key>: 查看缓存键
+//   - DELETE <path>/keys/<key>: 删除缓存键
+//   - 不包含鉴权，挂载到生产环境前请注册鉴权中间件
+func Register(router *goi.Router, path string) *goi.Router {
+	adminRouter := router.Include(strings.Trim(path, "/"), "Cache admin")
+	adminRouter.Path("stats", "Cache stats", StatsViewSet())
+	adminRouter.Path("keys", "Cache keys", KeysViewSet())
+	adminRouter.Path("keys/<path:key>", "Cache key", KeyViewSet())
+	return adminRouter
+}
+
+// StatsViewSet 缓存统计视图
+//
+// 返回:
+//   - goi.ViewSet: GET 返回 Stats
+func StatsViewSet() goi.ViewSet {
+	return goi.ViewSet{
+		GET: func(request *goi.Request) any {
+			stats := goi.Cache.Stats()
+			return goi.Response{Status: http.StatusOK, Data: Stats{CacheStats: stats, HitRatio: stats.HitRatio()}}
+		},
+	}
+}
+
+// KeysViewSet 缓存键列表视图
+//
+// 返回:
+//   - goi.ViewSet: GET 按前缀列出缓存键，DELETE 按前缀删除缓存键
+func KeysViewSet() goi.ViewSet {
+	return goi.ViewSet{
+		GET: func(request *goi.Request) any {
+			query := request.Object.URL.Query()
+			limit := DefaultLimit
+			if value := query.Get("limit"); value != "" {
+				n, err := strconv.Atoi(value)
+				if err != nil || n <= 0 {
+					return goi.Response{Status: http.StatusBadRequest, Data: "invalid limit"}
+				}
+				limit = n
+			}
+			keys, err := goi.Cache.Keys(goi.EscapeKeyPattern(query.Get("prefix")) + "*")
+			if err != nil {
+				return goi.Response{Status: http.StatusInternalServerError, Data: err.Error()}
+			}
+			list := KeyList{Total: len(keys), Keys: make([]KeyInfo, 0, min(len(keys), limit))}
+			for _, key := range keys[:min(len(keys), limit)] {
+				ttl, err := goi.Cache.TTL(key)
+				if err != nil {
+					return goi.Response{Status: http.StatusInternalServerError, Data: err.Error()}
+				}
+				if ttl == goi.TTLNotExists {
+					continue
+				}
+				list.Keys = append(list.Keys, KeyInfo{Key: key, TTL: ttlSeconds(ttl)})
+			}
+			return goi.Response{Status: http.StatusOK, Data: list}
+		},
+		DELETE: func(request *goi.Request) any {
+			prefix := request.Object.URL.Query().Get("prefix")
+			if prefix == "" {
+				return goi.Response{Status: http.StatusBadRequest, Data: "prefix is required"}
+			}
+			keys, err := goi.Cache.Keys(goi.EscapeKeyPattern(prefix) + "*")
+			if err != nil {
+				return goi.Response{Status: http.StatusInternalServerError, Data: err.Error()}
+			}
+			for _, key := range keys {
+				err = goi.Cache.Del(key)
+				if err != nil {
+					return goi.Response{Status: http.StatusInternalServerError, Data: err.Error()}
+				}
+			}
+			return goi.Response{Status: http.StatusOK, Data: map[string]int{"deleted": len(keys)}}
+		},
+	}
+}
+
+// KeyViewSet 单个缓存键视图，路由需包含 key 参数
+//
+// 返回:
+//   - goi.ViewSet: GET 返回 KeyInfo，DELETE 删除缓存键
+func KeyViewSet() goi.ViewSet {
+	return goi.ViewSet{
+		GET: func(request *goi.Request) any {
+			var key string
+			if validationErr := request.PathParams.Get("key", &key); validationErr != nil {
+				return validationErr.Response()
+			}
+			ttl, err := goi.Cache.TTL(key)
+			if err != nil {
+				return goi.Response{Status: http.StatusInternalServerError, Data: err.Error()}
+			}
+			if ttl == goi.TTLNotExists {
+				return goi.Response{Status: http.StatusNotFound, Data: ""}
+			}
+			return goi.Response{Status: http.StatusOK, Data: KeyInfo{Key: key, TTL: ttlSeconds(ttl)}}
+		},
+		DELETE: func(request *goi.Request) any {
+			var key string
+			if validationErr := request.PathParams.Get("key", &key); validationErr != nil {
+				return validationErr.Response()
+			}
+			if !goi.Cache.Has(key) {
+				return goi.Response{Status: http.StatusNotFound, Data: ""}
+			}
+			err := goi.Cache.Del(key)
+			if err != nil {
+				return goi.Response{Status: http.StatusInternalServerError, Data: err.Error()}
+			}
+			return goi.Response{Status: http.StatusNoContent, Data: ""}
+		},
+	}
+}
+
+// ttlSeconds 将剩余过期时间转换为秒，不足 1 秒向上取整
+//
+// 参数:
+//   - ttl time.Duration: 剩余过期时间
+//
+// 返回:
+//   - int64: 秒，永不过期为 -1
+func ttlSeconds(ttl time.Duration) int64 {
+	if ttl == goi.TTLPersistent {
+		return -1
+	}
+	return int64((ttl + time.Second - 1) / time.Second)
+}
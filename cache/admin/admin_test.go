@@ -0,0 +1,66 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/NeverStopDreamingWang/goi/v2"
+	"github.com/NeverStopDreamingWang/goi/v2/cache/admin"
+)
+
+func serve(engine *goi.Engine, method string, target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+	return recorder
+}
+
+// TestRegister 验证统计、按前缀列出与删除缓存键
+func TestRegister(t *testing.T) {
+	engine := goi.NewHTTPServer()
+	engine.Log = goi.NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer engine.Log.File.Close()
+	admin.Register(engine.Router, "admin/cache")
+
+	_ = goi.Cache.Set("user:1", "a", 60)
+	_ = goi.Cache.Set("user:2", "b", 0)
+	_ = goi.Cache.Set("order:1", "c", 0)
+	defer goi.Cache.Del("order:1")
+
+	var list admin.KeyList
+	recorder := serve(engine, http.MethodGet, "/admin/cache/keys?prefix=user:&limit=1")
+	if err := json.Unmarshal(recorder.Body.Bytes(), &list); err != nil {
+		t.Fatalf("keys response %q: %v", recorder.Body.String(), err)
+	}
+	if list.Total != 2 || len(list.Keys) != 1 || list.Keys[0].Key != "user:1" || list.Keys[0].TTL != 60 {
+		t.Errorf("keys = %+v", list)
+	}
+
+	var info admin.KeyInfo
+	recorder = serve(engine, http.MethodGet, "/admin/cache/keys/user:2")
+	if err := json.Unmarshal(recorder.Body.Bytes(), &info); err != nil || info.TTL != -1 {
+		t.Errorf("key = %+v, %v", info, err)
+	}
+	if recorder = serve(engine, http.MethodDelete, "/admin/cache/keys/user:2"); recorder.Code != http.StatusNoContent {
+		t.Errorf("delete key status = %d", recorder.Code)
+	}
+	if recorder = serve(engine, http.MethodGet, "/admin/cache/keys/user:2"); recorder.Code != http.StatusNotFound {
+		t.Errorf("deleted key status = %d", recorder.Code)
+	}
+
+	if recorder = serve(engine, http.MethodDelete, "/admin/cache/keys"); recorder.Code != http.StatusBadRequest {
+		t.Errorf("delete without prefix status = %d", recorder.Code)
+	}
+	serve(engine, http.MethodDelete, "/admin/cache/keys?prefix=user:")
+	if goi.Cache.Has("user:1") || !goi.Cache.Has("order:1") {
+		t.Error("delete by prefix removed wrong keys")
+	}
+
+	var stats admin.Stats
+	recorder = serve(engine, http.MethodGet, "/admin/cache/stats")
+	if err := json.Unmarshal(recorder.Body.Bytes(), &stats); err != nil || stats.Items != 1 || stats.Sets < 3 {
+		t.Errorf("stats = %+v, %v", stats, err)
+	}
+}
@@ -0,0 +1,645 @@
+// Package redis 提供基于 RESP 协议的 goi 缓存后端，兼容 Redis / Valkey
+//
+// 使用方式:
+//
+//	goi.Cache.Backend = redis.New(redis.Options{Addr: "127.0.0.1:6379"})
+package redis
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/gob"
+	"errors"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NeverStopDreamingWang/goi/v2"
+	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
+)
+
+// Options 连接配置
+type Options struct {
+	Network      string        // 网络协议，默认 "tcp"
+	Addr         string        // 服务地址，默认 "127.0.0.1:6379"
+	Username     string        // ACL 用户名，为空时仅使用密码认证
+	Password     string        // 密码
+	DB           int           // 数据库编号
+	Prefix       string        // 键前缀，用于多个服务共享同一实例
+	PoolSize     int           // 最大空闲连接数，默认 10
+	DialTimeout  time.Duration // 连接超时，默认 5 秒
+	ReadTimeout  time.Duration // 读取超时，默认 3 秒
+	WriteTimeout time.Duration // 写入超时，默认 3 秒
+	TLSConfig    *tls.Config   // TLS 配置，为空时不使用 TLS
+}
+
+// Backend RESP 缓存后端
+//
+// 说明:
+//   - 整数以十进制字符串存储，可与 INCRBY 等命令互通，其它值使用 gob 编码
+type Backend struct {
+	options Options
+	idle    chan *conn
+	lock    sync.Mutex
+	closed  bool
+	hits    atomic.Int64 // 读取命中次数
+	misses  atomic.Int64 // 读取未命中次数
+	sets    atomic.Int64 // 写入次数
+}
+
+// New 创建 RESP 缓存后端
+//
+// 参数:
+//   - options Options: 连接配置
+//
+// 返回:
+//   - *Backend: 缓存后端，连接在首次使用时建立
+func New(options Options) *Backend {
+	if options.Network == "" {
+		options.Network = "tcp"
+	}
+	if options.Addr == "" {
+		options.Addr = "127.0.0.1:6379"
+	}
+	if options.PoolSize <= 0 {
+		options.PoolSize = 10
+	}
+	if options.DialTimeout <= 0 {
+		options.DialTimeout = 5 * time.Second
+	}
+	if options.ReadTimeout <= 0 {
+		options.ReadTimeout = 3 * time.Second
+	}
+	if options.WriteTimeout <= 0 {
+		options.WriteTimeout = 3 * time.Second
+	}
+	return &Backend{
+		options: options,
+		idle:    make(chan *conn, options.PoolSize),
+	}
+}
+
+// Do 执行任意命令
+//
+// 参数:
+//   - args ...any: 命令及参数，例如 Do("EXPIRE", "key", 10)
+//
+// 返回:
+//   - any: 回复，简单字符串为 string，整数为 int64，批量字符串为 []byte，数组为 []any，空回复为 nil
+//   - error: 服务端错误回复为 Error，其它为连接错误
+//
+// 说明:
+//   - 参数中的键不会自动添加前缀
+func (backend *Backend) Do(args ...any) (any, error) {
+	c, err := backend.getConn()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := c.do(backend.options.ReadTimeout, backend.options.WriteTimeout, args)
+	var replyErr Error
+	backend.putConn(c, err != nil && !errors.As(err, &replyErr))
+	return reply, err
+}
+
+// getConn 获取空闲连接，没有时新建连接
+//
+// 返回:
+//   - *conn: 连接
+//   - error: 连接过程中的错误信息
+func (backend *Backend) getConn() (*conn, error) {
+	backend.lock.Lock()
+	closed := backend.closed
+	backend.lock.Unlock()
+	if closed {
+		return nil, errors.New(i18n.T("redis.closed"))
+	}
+
+	select {
+	case c := <-backend.idle:
+		return c, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: backend.options.DialTimeout}
+	var netConn net.Conn
+	var err error
+	if backend.options.TLSConfig != nil {
+		netConn, err = tls.DialWithDialer(dialer, backend.options.Network, backend.options.Addr, backend.options.TLSConfig)
+	} else {
+		netConn, err = dialer.Dial(backend.options.Network, backend.options.Addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := newConn(netConn)
+
+	// 认证与选择数据库
+	if backend.options.Password != "" {
+		args := []any{"AUTH", backend.options.Password}
+		if backend.options.Username != "" {
+			args = []any{"AUTH", backend.options.Username, backend.options.Password}
+		}
+		_, err = c.do(backend.options.ReadTimeout, backend.options.WriteTimeout, args)
+	}
+	if err == nil && backend.options.DB != 0 {
+		_, err = c.do(backend.options.ReadTimeout, backend.options.WriteTimeout, []any{"SELECT", backend.options.DB})
+	}
+	if err != nil {
+		_ = netConn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// putConn 归还连接
+//
+// 参数:
+//   - c *conn: 连接
+//   - broken bool: 连接是否已损坏，损坏或连接池已满时关闭连接
+func (backend *Backend) putConn(c *conn, broken bool) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	if broken || backend.closed {
+		_ = c.netConn.Close()
+		return
+	}
+	select {
+	case backend.idle <- c:
+	default:
+		_ = c.netConn.Close()
+	}
+}
+
+// Close 关闭所有连接，关闭后不可再使用
+//
+// 返回:
+//   - error: 关闭过程中的错误信息
+func (backend *Backend) Close() error {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	if backend.closed {
+		return nil
+	}
+	backend.closed = true
+	for {
+		select {
+		case c := <-backend.idle:
+			_ = c.netConn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// ShutdownName 关闭服务名称
+//
+// 返回:
+//   - string: 关闭服务名称
+func (backend *Backend) ShutdownName() string {
+	return i18n.T("redis.shutdown_name", map[string]any{
+		"addr": backend.options.Addr,
+	})
+}
+
+// OnShutdown 服务关闭时关闭所有连接
+//
+// 返回:
+//   - error: 关闭过程中的错误信息
+func (backend *Backend) OnShutdown() error {
+	return backend.Close()
+}
+
+// Has 检查键是否存在
+//
+// 参数:
+//   - key string: 要检查的键
+//
+// 返回:
+//   - bool: 是否存在该键，连接错误时返回 false
+func (backend *Backend) Has(key string) bool {
+	reply, err := backend.Do("EXISTS", backend.options.Prefix+key)
+	return err == nil && reply == int64(1)
+}
+
+// Get 获取缓存值
+//
+// 参数:
+//   - key string: 缓存键
+//   - value any: 用于存储解码后的值的指针，键不存在时保持不变
+//
+// 返回:
+//   - error: 获取过程中的错误信息
+func (backend *Backend) Get(key string, value any) error {
+	_, err := backend.Load(key, value)
+	return err
+}
+
+// Load 获取缓存值并返回是否命中
+//
+// 参数:
+//   - key string: 缓存键
+//   - value any: 用于存储解码后的值的指针
+//
+// 返回:
+//   - bool: 是否命中
+//   - error: 获取过程中的错误信息
+func (backend *Backend) Load(key string, value any) (bool, error) {
+	reply, err := backend.Do("GET", backend.options.Prefix+key)
+	if err != nil {
+		return false, err
+	}
+	if reply == nil {
+		backend.misses.Add(1)
+		return false, nil
+	}
+	data, ok := reply.([]byte)
+	if !ok {
+		return false, unexpectedReply(reply)
+	}
+	backend.hits.Add(1)
+	return true, decode(data, value)
+}
+
+// Set 设置缓存键值对
+//
+// 参数:
+//   - key string: 缓存键
+//   - value any: 要缓存的值
+//   - expires int: 过期时间(秒)，0表示永不过期
+//
+// 返回:
+//   - error: 设置过程中的错误信息
+func (backend *Backend) Set(key string, value any, expires int) error {
+	data, err := encode(value)
+	if err != nil {
+		return err
+	}
+	args := []any{"SET", backend.options.Prefix + key, data}
+	if expires > 0 {
+		args = append(args, "EX", expires)
+	}
+	_, err = backend.Do(args...)
+	if err == nil {
+		backend.sets.Add(1)
+	}
+	return err
+}
+
+// SetNX 键不存在时设置缓存键值对
+//
+// 参数:
+//   - key string: 缓存键
+//   - value any: 要缓存的值
+//   - expires int: 过期时间(秒)，0表示永不过期
+//
+// 返回:
+//   - bool: 是否设置成功，键已存在返回 false
+//   - error: 设置过程中的错误信息
+func (backend *Backend) SetNX(key string, value any, expires int) (bool, error) {
+	data, err := encode(value)
+	if err != nil {
+		return false, err
+	}
+	args := []any{"SET", backend.options.Prefix + key, data, "NX"}
+	if expires > 0 {
+		args = append(args, "EX", expires)
+	}
+	reply, err := backend.Do(args...)
+	if err != nil || reply == nil {
+		return false, err
+	}
+	backend.sets.Add(1)
+	return true, nil
+}
+
+// CompareAndSwap 当前值等于 old 时原子替换为 new
+//
+// 参数:
+//   - key string: 缓存键
+//   - old any: 期望的当前值，按 reflect.DeepEqual 比较
+//   - new any: 新值
+//   - expires int: 新值的过期时间(秒)，0表示永不过期
+//
+// 返回:
+//   - bool: 是否替换成功，键不存在、值不相等或比较期间被其它客户端修改返回 false
+//   - error: 替换过程中的错误信息
+//
+// 说明:
+//   - 使用 WATCH/MULTI/EXEC 乐观锁实现
+func (backend *Backend) CompareAndSwap(key string, old any, new any, expires int) (bool, error) {
+	data, err := encode(new)
+	if err != nil {
+		return false, err
+	}
+	key = backend.options.Prefix + key
+
+	c, err := backend.getConn()
+	if err != nil {
+		return false, err
+	}
+	swapped, err := backend.compareAndSwap(c, key, old, data, expires)
+	// 事务中途出错时连接状态未知，直接关闭
+	backend.putConn(c, err != nil)
+	return swapped, err
+}
+
+// compareAndSwap 在同一连接上执行比较与替换
+//
+// 参数:
+//   - c *conn: 连接
+//   - key string: 带前缀的缓存键
+//   - old any: 期望的当前值
+//   - data []byte: 编码后的新值
+//   - expires int: 新值的过期时间(秒)
+//
+// 返回:
+//   - bool: 是否替换成功
+//   - error: 替换过程中的错误信息
+func (backend *Backend) compareAndSwap(c *conn, key string, old any, data []byte, expires int) (bool, error) {
+	do := func(args ...any) (any, error) {
+		return c.do(backend.options.ReadTimeout, backend.options.WriteTimeout, args)
+	}
+	_, err := do("WATCH", key)
+	if err != nil {
+		return false, err
+	}
+	reply, err := do("GET", key)
+	if err != nil {
+		return false, err
+	}
+	current, ok := reply.([]byte)
+	equal := false
+	if ok && old != nil {
+		value := reflect.New(reflect.TypeOf(old))
+		if decode(current, value.Interface()) == nil {
+			equal = reflect.DeepEqual(value.Elem().Interface(), old)
+		}
+	}
+	if !equal {
+		_, err = do("UNWATCH")
+		return false, err
+	}
+
+	_, err = do("MULTI")
+	if err != nil {
+		return false, err
+	}
+	args := []any{"SET", key, data}
+	if expires > 0 {
+		args = append(args, "EX", expires)
+	}
+	_, err = do(args...)
+	if err != nil {
+		return false, err
+	}
+	reply, err = do("EXEC")
+	if err != nil {
+		return false, err
+	}
+	// 监视的键被修改时 EXEC 返回空回复
+	return reply != nil, nil
+}
+
+// Del 删除缓存键
+//
+// 参数:
+//   - key string: 要删除的缓存键
+//
+// 返回:
+//   - error: 删除过程中的错误信息
+func (backend *Backend) Del(key string) error {
+	_, err := backend.Do("DEL", backend.options.Prefix+key)
+	return err
+}
+
+// Expire 设置缓存键的过期时间
+//
+// 参数:
+//   - key string: 缓存键
+//   - expires int: 过期时间(秒)，小于等于 0 时立即删除
+//
+// 返回:
+//   - bool: 键是否存在
+//   - error: 设置过程中的错误信息
+func (backend *Backend) Expire(key string, expires int) (bool, error) {
+	return backend.doBool("EXPIRE", backend.options.Prefix+key, expires)
+}
+
+// Persist 移除缓存键的过期时间
+//
+// 参数:
+//   - key string: 缓存键
+//
+// 返回:
+//   - bool: 键存在且原本设置了过期时间时返回 true
+//   - error: 设置过程中的错误信息
+func (backend *Backend) Persist(key string) (bool, error) {
+	return backend.doBool("PERSIST", backend.options.Prefix+key)
+}
+
+// doBool 执行返回 0/1 的命令
+//
+// 参数:
+//   - args ...any: 命令及参数
+//
+// 返回:
+//   - bool: 回复是否为 1
+//   - error: 执行过程中的错误信息
+func (backend *Backend) doBool(args ...any) (bool, error) {
+	reply, err := backend.Do(args...)
+	if err != nil {
+		return false, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return false, unexpectedReply(reply)
+	}
+	return n == 1, nil
+}
+
+// Keys 获取匹配模式的缓存键
+//
+// 参数:
+//   - pattern string: glob 模式，例如 "user:*"
+//
+// 返回:
+//   - []string: 去除前缀后按字典序排列的缓存键
+//   - error: 获取过程中的错误信息
+//
+// 说明:
+//   - 使用 SCAN 增量遍历，不会像 KEYS 一样阻塞服务端
+func (backend *Backend) Keys(pattern string) ([]string, error) {
+	seen := make(map[string]bool)
+	keys := make([]string, 0)
+	cursor := "0"
+	for {
+		reply, err := backend.Do("SCAN", cursor, "MATCH", backend.options.Prefix+pattern, "COUNT", 100)
+		if err != nil {
+			return nil, err
+		}
+		items, ok := reply.([]any)
+		if !ok || len(items) != 2 {
+			return nil, unexpectedReply(reply)
+		}
+		next, ok := items[0].([]byte)
+		if !ok {
+			return nil, unexpectedReply(reply)
+		}
+		batch, ok := items[1].([]any)
+		if !ok {
+			return nil, unexpectedReply(reply)
+		}
+		for _, item := range batch {
+			data, ok := item.([]byte)
+			if !ok {
+				return nil, unexpectedReply(reply)
+			}
+			// SCAN 可能重复返回同一个键
+			key := strings.TrimPrefix(string(data), backend.options.Prefix)
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+		cursor = string(next)
+		if cursor == "0" {
+			break
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// TTL 获取缓存键的剩余过期时间
+//
+// 参数:
+//   - key string: 缓存键
+//
+// 返回:
+//   - time.Duration: 剩余过期时间，永不过期返回 goi.TTLPersistent，不存在返回 goi.TTLNotExists
+//   - error: 获取过程中的错误信息
+func (backend *Backend) TTL(key string) (time.Duration, error) {
+	reply, err := backend.Do("PTTL", backend.options.Prefix+key)
+	if err != nil {
+		return 0, err
+	}
+	ms, ok := reply.(int64)
+	if !ok {
+		return 0, unexpectedReply(reply)
+	}
+	switch ms {
+	case -2:
+		return goi.TTLNotExists, nil
+	case -1:
+		return goi.TTLPersistent, nil
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// Incr 整数自增
+//
+// 参数:
+//   - key string: 缓存键，不存在时从 0 开始
+//   - delta int64: 增量，可为负数
+//
+// 返回:
+//   - int64: 自增后的值
+//   - error: 原值不是整数时返回服务端错误
+func (backend *Backend) Incr(key string, delta int64) (int64, error) {
+	reply, err := backend.Do("INCRBY", backend.options.Prefix+key, delta)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, unexpectedReply(reply)
+	}
+	return n, nil
+}
+
+// Stats 获取客户端侧统计
+//
+// 返回:
+//   - goi.CacheStats: 仅包含本实例的 Hits、Misses 与 Sets，淘汰与过期由服务端处理
+func (backend *Backend) Stats() goi.CacheStats {
+	return goi.CacheStats{
+		Hits:   backend.hits.Load(),
+		Misses: backend.misses.Load(),
+		Sets:   backend.sets.Load(),
+	}
+}
+
+// encode 编码缓存值
+//
+// 参数:
+//   - value any: 缓存值
+//
+// 返回:
+//   - []byte: 整数为十进制字符串，其它为 gob 编码
+//   - error: 编码过程中的错误信息
+func encode(value any) ([]byte, error) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(nil, rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.AppendUint(nil, rv.Uint(), 10), nil
+	}
+	var buffer bytes.Buffer
+	err := gob.NewEncoder(&buffer).Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// decode 解码缓存值
+//
+// 参数:
+//   - data []byte: 缓存数据
+//   - value any: 用于存储解码后的值的指针
+//
+// 返回:
+//   - error: 解码过程中的错误信息
+func decode(data []byte, value any) error {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		elem := rv.Elem()
+		switch elem.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(string(data), 10, elem.Type().Bits())
+			if err != nil {
+				return err
+			}
+			elem.SetInt(n)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n, err := strconv.ParseUint(string(data), 10, elem.Type().Bits())
+			if err != nil {
+				return err
+			}
+			elem.SetUint(n)
+			return nil
+		}
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+// unexpectedReply 创建回复类型错误
+//
+// 参数:
+//   - reply any: 回复
+//
+// 返回:
+//   - error: 回复类型错误
+func unexpectedReply(reply any) error {
+	unexpectedReplyMsg := i18n.T("redis.unexpected_reply", map[string]any{
+		"reply": reply,
+	})
+	return errors.New(unexpectedReplyMsg)
+}
@@ -0,0 +1,478 @@
+package redis_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NeverStopDreamingWang/goi/v2"
+	"github.com/NeverStopDreamingWang/goi/v2/cache/redis"
+)
+
+// standIn 进程内 RESP 服务端替身，实现测试所需的命令子集
+type standIn struct {
+	listener net.Listener
+	lock     sync.Mutex
+	data     map[string][]byte
+	expires  map[string]time.Time
+	versions map[string]int64 // 键的修改版本，用于 WATCH
+	password string
+}
+
+// newStandIn 启动 RESP 服务端替身
+func newStandIn(t *testing.T, password string) *standIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &standIn{
+		listener: listener,
+		data:     make(map[string][]byte),
+		expires:  make(map[string]time.Time),
+		versions: make(map[string]int64),
+		password: password,
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			netConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(netConn)
+		}
+	}()
+	return server
+}
+
+// stored 获取服务端存储的原始值
+func (server *standIn) stored(key string) ([]byte, bool) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	data, ok := server.data[key]
+	return data, ok
+}
+
+func (server *standIn) addr() string {
+	return server.listener.Addr().String()
+}
+
+func (server *standIn) serve(netConn net.Conn) {
+	defer netConn.Close()
+	reader := bufio.NewReader(netConn)
+	authed := server.password == ""
+	var watched map[string]int64 // WATCH 时记录的版本
+	var queued [][]string        // MULTI 后排队的命令
+	inMulti := false
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(args[0])
+		if !authed && command != "AUTH" {
+			io.WriteString(netConn, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+		switch {
+		case command == "AUTH":
+			if args[len(args)-1] != server.password {
+				io.WriteString(netConn, "-WRONGPASS invalid password\r\n")
+				continue
+			}
+			authed = true
+			io.WriteString(netConn, "+OK\r\n")
+		case command == "WATCH":
+			server.lock.Lock()
+			if watched == nil {
+				watched = make(map[string]int64)
+			}
+			for _, key := range args[1:] {
+				server.lookup(key)
+				watched[key] = server.versions[key]
+			}
+			server.lock.Unlock()
+			io.WriteString(netConn, "+OK\r\n")
+		case command == "UNWATCH":
+			watched = nil
+			io.WriteString(netConn, "+OK\r\n")
+		case command == "MULTI":
+			inMulti = true
+			io.WriteString(netConn, "+OK\r\n")
+		case command == "EXEC":
+			server.lock.Lock()
+			aborted := false
+			for key, version := range watched {
+				server.lookup(key)
+				if server.versions[key] != version {
+					aborted = true
+				}
+			}
+			if aborted {
+				io.WriteString(netConn, "*-1\r\n")
+			} else {
+				reply := fmt.Sprintf("*%d\r\n", len(queued))
+				for _, queuedArgs := range queued {
+					reply += server.execLocked(strings.ToUpper(queuedArgs[0]), queuedArgs[1:])
+				}
+				io.WriteString(netConn, reply)
+			}
+			server.lock.Unlock()
+			watched, queued, inMulti = nil, nil, false
+		case inMulti:
+			queued = append(queued, args)
+			io.WriteString(netConn, "+QUEUED\r\n")
+		default:
+			server.lock.Lock()
+			io.WriteString(netConn, server.execLocked(command, args[1:]))
+			server.lock.Unlock()
+		}
+	}
+}
+
+// readCommand 读取 RESP 数组命令
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err = reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		data := make([]byte, size+2)
+		if _, err = io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func bulk(data []byte) string {
+	if data == nil {
+		return "$-1\r\n"
+	}
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(data), data)
+}
+
+func integer(ok bool) string {
+	if ok {
+		return ":1\r\n"
+	}
+	return ":0\r\n"
+}
+
+// lookup 获取未过期的值，调用前需加锁
+func (server *standIn) lookup(key string) ([]byte, bool) {
+	if expires, ok := server.expires[key]; ok && time.Now().After(expires) {
+		server.remove(key)
+	}
+	data, ok := server.data[key]
+	return data, ok
+}
+
+// remove 删除键，调用前需加锁
+func (server *standIn) remove(key string) {
+	delete(server.data, key)
+	delete(server.expires, key)
+	server.versions[key]++
+}
+
+// execLocked 执行单条命令，调用前需加锁
+func (server *standIn) execLocked(command string, args []string) string {
+	switch command {
+	case "PING":
+		return "+PONG\r\n"
+	case "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		data, _ := server.lookup(args[0])
+		return bulk(data)
+	case "SET":
+		key := args[0]
+		var expires time.Duration
+		nx := false
+		for i := 2; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "NX":
+				nx = true
+			case "EX":
+				seconds, _ := strconv.Atoi(args[i+1])
+				expires = time.Duration(seconds) * time.Second
+				i++
+			}
+		}
+		if _, ok := server.lookup(key); ok && nx {
+			return "$-1\r\n"
+		}
+		server.data[key] = []byte(args[1])
+		delete(server.expires, key)
+		if expires > 0 {
+			server.expires[key] = time.Now().Add(expires)
+		}
+		server.versions[key]++
+		return "+OK\r\n"
+	case "DEL":
+		_, ok := server.lookup(args[0])
+		server.remove(args[0])
+		return integer(ok)
+	case "EXISTS":
+		_, ok := server.lookup(args[0])
+		return integer(ok)
+	case "EXPIRE":
+		if _, ok := server.lookup(args[0]); !ok {
+			return integer(false)
+		}
+		seconds, _ := strconv.Atoi(args[1])
+		if seconds <= 0 {
+			server.remove(args[0])
+		} else {
+			server.expires[args[0]] = time.Now().Add(time.Duration(seconds) * time.Second)
+			server.versions[args[0]]++
+		}
+		return integer(true)
+	case "PERSIST":
+		_, ok := server.lookup(args[0])
+		_, hasExpires := server.expires[args[0]]
+		delete(server.expires, args[0])
+		return integer(ok && hasExpires)
+	case "PTTL":
+		if _, ok := server.lookup(args[0]); !ok {
+			return ":-2\r\n"
+		}
+		expires, ok := server.expires[args[0]]
+		if !ok {
+			return ":-1\r\n"
+		}
+		return fmt.Sprintf(":%d\r\n", time.Until(expires).Milliseconds())
+	case "INCRBY":
+		data, _ := server.lookup(args[0])
+		current := int64(0)
+		if data != nil {
+			var err error
+			current, err = strconv.ParseInt(string(data), 10, 64)
+			if err != nil {
+				return "-ERR value is not an integer or out of range\r\n"
+			}
+		}
+		delta, _ := strconv.ParseInt(args[1], 10, 64)
+		current += delta
+		server.data[args[0]] = []byte(strconv.FormatInt(current, 10))
+		server.versions[args[0]]++
+		return fmt.Sprintf(":%d\r\n", current)
+	case "SCAN":
+		// 单次返回全部匹配的键
+		pattern := "*"
+		for i := 1; i+1 < len(args); i += 2 {
+			if strings.ToUpper(args[i]) == "MATCH" {
+				pattern = args[i+1]
+			}
+		}
+		var keys []string
+		for key := range server.data {
+			if _, ok := server.lookup(key); ok && goi.MatchKey(pattern, key) {
+				keys = append(keys, key)
+			}
+		}
+		reply := fmt.Sprintf("*2\r\n$1\r\n0\r\n*%d\r\n", len(keys))
+		for _, key := range keys {
+			reply += bulk([]byte(key))
+		}
+		return reply
+	}
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", command)
+}
+
+type user struct {
+	Name string
+	Age  int
+}
+
+// TestBackend 验证 RESP 后端的基本命令
+func TestBackend(t *testing.T) {
+	server := newStandIn(t, "secret")
+	backend := redis.New(redis.Options{Addr: server.addr(), Password: "secret", Prefix: "app:"})
+	defer backend.Close()
+
+	if err := backend.Set("user", user{Name: "goi", Age: 3}, 0); err != nil {
+		t.Fatal(err)
+	}
+	var got user
+	if err := backend.Get("user", &got); err != nil || got.Name != "goi" || got.Age != 3 {
+		t.Fatalf("Get = %+v, %v", got, err)
+	}
+	if !backend.Has("user") || backend.Has("missing") {
+		t.Error("unexpected Has result")
+	}
+	if _, ok := server.stored("app:user"); !ok {
+		t.Error("key prefix not applied")
+	}
+
+	// 整数与 INCRBY 互通
+	if err := backend.Set("counter", 40, 60); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := backend.Incr("counter", 2); err != nil || n != 42 {
+		t.Fatalf("Incr = %d, %v", n, err)
+	}
+	var counter int
+	if err := backend.Get("counter", &counter); err != nil || counter != 42 {
+		t.Fatalf("Get counter = %d, %v", counter, err)
+	}
+	if _, err := backend.Incr("user", 1); err == nil {
+		t.Error("Incr on non-integer value succeeded")
+	}
+
+	if ttl, err := backend.TTL("counter"); err != nil || ttl <= 0 || ttl > time.Minute {
+		t.Errorf("TTL counter = %v, %v", ttl, err)
+	}
+	if ttl, _ := backend.TTL("user"); ttl != goi.TTLPersistent {
+		t.Errorf("TTL user = %v", ttl)
+	}
+	if err := backend.Del("user"); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, _ := backend.TTL("user"); ttl != goi.TTLNotExists {
+		t.Errorf("TTL deleted = %v", ttl)
+	}
+
+	// 键不存在时保持原值
+	got = user{Name: "keep"}
+	if err := backend.Get("user", &got); err != nil || got.Name != "keep" {
+		t.Errorf("Get missing = %+v, %v", got, err)
+	}
+
+	// 服务端错误不影响连接复用
+	if _, err := backend.Do("UNKNOWN"); err == nil {
+		t.Error("unknown command succeeded")
+	}
+	if reply, err := backend.Do("PING"); err != nil || reply != "PONG" {
+		t.Errorf("PING = %v, %v", reply, err)
+	}
+}
+
+// TestBackendAuthError 验证认证失败时返回错误
+func TestBackendAuthError(t *testing.T) {
+	server := newStandIn(t, "secret")
+	backend := redis.New(redis.Options{Addr: server.addr(), Password: "wrong"})
+	defer backend.Close()
+	if err := backend.Set("key", "value", 0); err == nil {
+		t.Error("Set with wrong password succeeded")
+	}
+}
+
+// TestCacheBackend 验证 goi.Cache 切换为 RESP 后端后行为一致
+func TestCacheBackend(t *testing.T) {
+	server := newStandIn(t, "")
+	backend := redis.New(redis.Options{Addr: server.addr()})
+	defer backend.Close()
+
+	prev := goi.Cache.Backend
+	goi.Cache.Backend = backend
+	defer func() { goi.Cache.Backend = prev }()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := goi.Cache.Incr("hits", 1); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var hits int64
+	if err := goi.Cache.Get("hits", &hits); err != nil || hits != 20 {
+		t.Fatalf("hits = %d, %v", hits, err)
+	}
+	if data, _ := server.stored("hits"); string(data) != "20" {
+		t.Errorf("stored hits = %q", data)
+	}
+}
+
+// TestBackendAtomic 验证 SetNX、CompareAndSwap、Expire、Persist 与 Keys
+func TestBackendAtomic(t *testing.T) {
+	server := newStandIn(t, "")
+	backend := redis.New(redis.Options{Addr: server.addr(), Prefix: "app:"})
+	defer backend.Close()
+
+	if ok, err := backend.SetNX("lock", "a", 10); err != nil || !ok {
+		t.Fatalf("SetNX new = %v, %v", ok, err)
+	}
+	if ok, _ := backend.SetNX("lock", "b", 10); ok {
+		t.Error("SetNX existing succeeded")
+	}
+	var found bool
+	var owner string
+	if found, _ = backend.Load("lock", &owner); !found || owner != "a" {
+		t.Errorf("Load lock = %q, %v", owner, found)
+	}
+	if found, _ = backend.Load("missing", &owner); found {
+		t.Error("Load missing found")
+	}
+
+	if ok, _ := backend.CompareAndSwap("lock", "b", "c", 0); ok {
+		t.Error("CompareAndSwap with wrong old value succeeded")
+	}
+	if ok, err := backend.CompareAndSwap("lock", "a", "c", 0); err != nil || !ok {
+		t.Fatalf("CompareAndSwap = %v, %v", ok, err)
+	}
+
+	if ok, _ := backend.Expire("lock", 30); !ok {
+		t.Error("Expire existing failed")
+	}
+	if ok, _ := backend.Persist("lock"); !ok {
+		t.Error("Persist failed")
+	}
+	if ttl, _ := backend.TTL("lock"); ttl != goi.TTLPersistent {
+		t.Errorf("TTL after Persist = %v", ttl)
+	}
+
+	_ = backend.Set("user:1", 1, 0)
+	_ = backend.Set("user:2", 2, 0)
+	keys, err := backend.Keys("user:*")
+	if err != nil || strings.Join(keys, ",") != "user:1,user:2" {
+		t.Errorf("Keys = %v, %v", keys, err)
+	}
+
+	// 并发 CompareAndSwap 自增不丢失更新
+	_ = backend.Set("counter", 0, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var current int
+				if _, err := backend.Load("counter", &current); err != nil {
+					t.Error(err)
+					return
+				}
+				if ok, err := backend.CompareAndSwap("counter", current, current+1, 0); err != nil || ok {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	var counter int
+	if _ = backend.Get("counter", &counter); counter != 10 {
+		t.Errorf("counter = %d, want 10", counter)
+	}
+}
@@ -0,0 +1,203 @@
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
+)
+
+// Error 服务端返回的错误回复，例如 "ERR unknown command"
+type Error string
+
+// Error 实现 error 接口
+func (err Error) Error() string {
+	return string(err)
+}
+
+// conn RESP 连接
+type conn struct {
+	netConn net.Conn
+	reader  *bufio.Reader
+	writer  *bufio.Writer
+}
+
+// newConn 包装网络连接
+//
+// 参数:
+//   - netConn net.Conn: 网络连接
+//
+// 返回:
+//   - *conn: RESP 连接
+func newConn(netConn net.Conn) *conn {
+	return &conn{
+		netConn: netConn,
+		reader:  bufio.NewReader(netConn),
+		writer:  bufio.NewWriter(netConn),
+	}
+}
+
+// do 发送命令并读取回复
+//
+// 参数:
+//   - readTimeout time.Duration: 读取超时，0 表示不限制
+//   - writeTimeout time.Duration: 写入超时，0 表示不限制
+//   - args []any: 命令及参数
+//
+// 返回:
+//   - any: 回复，类型为 string、int64、[]byte、[]any 或 nil
+//   - error: 服务端错误回复为 Error，其它为连接错误
+func (c *conn) do(readTimeout time.Duration, writeTimeout time.Duration, args []any) (any, error) {
+	if writeTimeout > 0 {
+		_ = c.netConn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	}
+	err := writeCommand(c.writer, args)
+	if err != nil {
+		return nil, err
+	}
+	if readTimeout > 0 {
+		_ = c.netConn.SetReadDeadline(time.Now().Add(readTimeout))
+	}
+	return readReply(c.reader)
+}
+
+// writeCommand 以 RESP 数组格式写入命令
+//
+// 参数:
+//   - writer *bufio.Writer: 输出缓冲
+//   - args []any: 命令及参数，支持 string、[]byte 与整数
+//
+// 返回:
+//   - error: 写入过程中的错误信息
+func writeCommand(writer *bufio.Writer, args []any) error {
+	writer.WriteString("*")
+	writer.WriteString(strconv.Itoa(len(args)))
+	writer.WriteString("\r\n")
+	for _, arg := range args {
+		var data []byte
+		switch value := arg.(type) {
+		case string:
+			data = []byte(value)
+		case []byte:
+			data = value
+		case int:
+			data = strconv.AppendInt(nil, int64(value), 10)
+		case int64:
+			data = strconv.AppendInt(nil, value, 10)
+		default:
+			data = []byte(fmt.Sprint(value))
+		}
+		writer.WriteString("$")
+		writer.WriteString(strconv.Itoa(len(data)))
+		writer.WriteString("\r\n")
+		writer.Write(data)
+		writer.WriteString("\r\n")
+	}
+	return writer.Flush()
+}
+
+// readReply 读取一个 RESP 回复
+//
+// 参数:
+//   - reader *bufio.Reader: 输入缓冲
+//
+// 返回:
+//   - any: 回复，简单字符串为 string，整数为 int64，批量字符串为 []byte，数组为 []any，空回复为 nil
+//   - error: 服务端错误回复为 Error，其它为协议或连接错误
+func readReply(reader *bufio.Reader) (any, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, protocolError(line)
+	}
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		n, err := strconv.ParseInt(string(line[1:]), 10, 64)
+		if err != nil {
+			return nil, protocolError(line)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil || n < -1 {
+			return nil, protocolError(line)
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		_, err = io.ReadFull(reader, data)
+		if err != nil {
+			return nil, err
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil || n < -1 {
+			return nil, protocolError(line)
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		items := make([]any, n)
+		for i := range items {
+			item, err := readReply(reader)
+			var replyErr Error
+			if errors.As(err, &replyErr) {
+				// 数组中的错误回复作为元素返回
+				items[i] = replyErr
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return nil, protocolError(line)
+}
+
+// readLine 读取以 "\r\n" 结尾的一行
+//
+// 参数:
+//   - reader *bufio.Reader: 输入缓冲
+//
+// 返回:
+//   - []byte: 不含 "\r\n" 的内容
+//   - error: 读取过程中的错误信息
+func readLine(reader *bufio.Reader) ([]byte, error) {
+	line, err := reader.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, protocolError(line)
+	}
+	return line[:len(line)-2], nil
+}
+
+// protocolError 创建协议错误
+//
+// 参数:
+//   - line []byte: 无法解析的内容
+//
+// 返回:
+//   - error: 协议错误
+func protocolError(line []byte) error {
+	protocolErrorMsg := i18n.T("redis.protocol_error", map[string]any{
+		"line": strconv.Quote(string(line)),
+	})
+	return errors.New(protocolErrorMsg)
+}
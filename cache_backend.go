@@ -0,0 +1,387 @@
+package goi
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
+)
+
+// TTL 特殊返回值
+const (
+	TTLPersistent time.Duration = -1 // 永不过期
+	TTLNotExists  time.Duration = -2 // 键不存在
+)
+
+// CacheBackend 缓存后端
+//
+// 说明:
+//   - 默认使用内存缓存 MemoryCache，跨实例共享缓存时可使用 RESP 协议后端（cache/redis）
+//   - 后端实现 Startup 或 ShutdownCallback 时，在服务启动时自动注册
+type CacheBackend interface {
+	// Has 检查键是否存在
+	Has(key string) bool
+	// Get 获取缓存值，解码到 value 指针，键不存在时 value 保持不变
+	Get(key string, value any) error
+	// Load 获取缓存值，解码到 value 指针，返回是否命中
+	Load(key string, value any) (bool, error)
+	// Set 设置缓存键值对，expires 为过期时间(秒)，0 表示永不过期
+	Set(key string, value any, expires int) error
+	// SetNX 键不存在时设置缓存键值对，返回是否设置成功
+	SetNX(key string, value any, expires int) (bool, error)
+	// CompareAndSwap 当前值等于 old 时原子替换为 new，返回是否替换成功
+	CompareAndSwap(key string, old any, new any, expires int) (bool, error)
+	// Del 删除缓存键
+	Del(key string) error
+	// Expire 设置过期时间(秒)，小于等于 0 时立即删除，返回键是否存在
+	Expire(key string, expires int) (bool, error)
+	// Persist 移除过期时间，返回键存在且原本设置了过期时间
+	Persist(key string) (bool, error)
+	// TTL 获取剩余过期时间，永不过期返回 TTLPersistent，不存在返回 TTLNotExists
+	TTL(key string) (time.Duration, error)
+	// Keys 获取匹配 glob 模式的缓存键，规则见 MatchKey
+	Keys(pattern string) ([]string, error)
+	// Incr 整数原子自增，键不存在时从 0 开始
+	Incr(key string, delta int64) (int64, error)
+}
+
+// CacheSubscriber 支持事件订阅的缓存后端
+type CacheSubscriber interface {
+	// Subscribe 订阅缓存事件，返回事件通道与取消订阅函数
+	Subscribe(buffer int, types ...CacheEventType) (<-chan CacheEvent, func())
+}
+
+// CacheSnapshotter 支持快照的缓存后端
+type CacheSnapshotter interface {
+	// SaveSnapshot 保存快照到 path
+	SaveSnapshot(path string) error
+	// LoadSnapshot 从 path 加载快照，返回加载的键数量
+	LoadSnapshot(path string) (int, error)
+}
+
+// cache 缓存管理器
+//
+// 字段:
+//   - *MemoryCacheSettings: 默认内存缓存配置，仅作用于内存缓存
+//   - Backend CacheBackend: 缓存后端，为空时使用内存缓存
+//   - memory *MemoryCache: 默认内存缓存
+type cache struct {
+	*MemoryCacheSettings
+	Backend CacheBackend
+
+	memory *MemoryCache
+
+	initOnce sync.Once // 多个 Engine 共用缓存时只初始化一次
+}
+
+// newCache 创建新的缓存管理器
+//
+// 返回:
+//   - *cache: 新创建的缓存管理器实例
+func newCache() *cache {
+	memory := NewMemoryCache()
+	return &cache{
+		MemoryCacheSettings: memory.MemoryCacheSettings,
+		Backend:             nil,
+		memory:              memory,
+	}
+}
+
+// backend 获取当前使用的缓存后端
+//
+// 返回:
+//   - CacheBackend: 缓存后端
+func (self *cache) backend() CacheBackend {
+	if self.Backend != nil {
+		return self.Backend
+	}
+	return self.memory
+}
+
+// initCache 初始化缓存配置
+//
+// 参数:
+//   - engine *Engine: 启动的 Engine，缓存被多个 Engine 共享时只在首个 Engine 中初始化
+func (self *cache) initCache(engine *Engine) {
+	self.initOnce.Do(func() {
+		self.init(engine)
+	})
+}
+
+// init 初始化缓存配置，向 engine 注册缓存后台任务与关闭回调
+func (self *cache) init(engine *Engine) {
+	if self.Backend == nil {
+		self.memory.initCache(engine)
+		return
+	}
+	backendMsg := i18n.T("server.cache.backend", map[string]any{
+		"backend": fmt.Sprintf("%T", self.Backend),
+	})
+	Log.Log(meta, backendMsg)
+	if task, ok := self.Backend.(Startup); ok {
+		engine.registerOnStartup(task)
+	}
+	if callback, ok := self.Backend.(ShutdownCallback); ok {
+		engine.registerOnShutdown(callback)
+	}
+}
+
+// Has 检查键是否存在
+//
+// 参数:
+//   - key string: 要检查的键
+//
+// 返回:
+//   - bool: 是否存在该键
+func (self *cache) Has(key string) bool {
+	return self.backend().Has(key)
+}
+
+// Get 获取缓存值
+//
+// 参数:
+//   - key string: 缓存键
+//   - value any: 用于存储解码后的值的指针
+//
+// 返回:
+//   - error: 获取过程中的错误信息
+func (self *cache) Get(key string, value any) error {
+	return self.backend().Get(key, value)
+}
+
+// Load 获取缓存值并返回是否命中
+//
+// 参数:
+//   - key string: 缓存键
+//   - value any: 用于存储解码后的值的指针
+//
+// 返回:
+//   - bool: 是否命中
+//   - error: 获取过程中的错误信息
+func (self *cache) Load(key string, value any) (bool, error) {
+	return self.backend().Load(key, value)
+}
+
+// Set 设置缓存键值对
+//
+// 参数:
+//   - key string: 缓存键
+//   - value any: 要缓存的值
+//   - expires int: 过期时间(秒)，0表示永不过期
+//
+// 返回:
+//   - error: 设置过程中的错误信息
+func (self *cache) Set(key string, value any, expires int) error {
+	return self.backend().Set(key, value, expires)
+}
+
+// SetNX 键不存在时设置缓存键值对
+//
+// 参数:
+//   - key string: 缓存键
+//   - value any: 要缓存的值
+//   - expires int: 过期时间(秒)，0表示永不过期
+//
+// 返回:
+//   - bool: 是否设置成功，键已存在返回 false
+//   - error: 设置过程中的错误信息
+func (self *cache) SetNX(key string, value any, expires int) (bool, error) {
+	return self.backend().SetNX(key, value, expires)
+}
+
+// CompareAndSwap 当前值等于 old 时原子替换为 new
+//
+// 参数:
+//   - key string: 缓存键
+//   - old any: 期望的当前值
+//   - new any: 新值
+//   - expires int: 新值的过期时间(秒)，0表示永不过期
+//
+// 返回:
+//   - bool: 是否替换成功
+//   - error: 替换过程中的错误信息
+func (self *cache) CompareAndSwap(key string, old any, new any, expires int) (bool, error) {
+	return self.backend().CompareAndSwap(key, old, new, expires)
+}
+
+// Del 删除缓存键
+//
+// 参数:
+//   - key string: 要删除的缓存键
+//
+// 返回:
+//   - error: 删除过程中的错误信息
+func (self *cache) Del(key string) error {
+	return self.backend().Del(key)
+}
+
+// Expire 设置缓存键的过期时间
+//
+// 参数:
+//   - key string: 缓存键
+//   - expires int: 过期时间(秒)，小于等于 0 时立即删除
+//
+// 返回:
+//   - bool: 键是否存在
+//   - error: 设置过程中的错误信息
+func (self *cache) Expire(key string, expires int) (bool, error) {
+	return self.backend().Expire(key, expires)
+}
+
+// Persist 移除缓存键的过期时间
+//
+// 参数:
+//   - key string: 缓存键
+//
+// 返回:
+//   - bool: 键存在且原本设置了过期时间时返回 true
+//   - error: 设置过程中的错误信息
+func (self *cache) Persist(key string) (bool, error) {
+	return self.backend().Persist(key)
+}
+
+// TTL 获取缓存键的剩余过期时间
+//
+// 参数:
+//   - key string: 缓存键
+//
+// 返回:
+//   - time.Duration: 剩余过期时间，永不过期返回 TTLPersistent，不存在返回 TTLNotExists
+//   - error: 获取过程中的错误信息
+func (self *cache) TTL(key string) (time.Duration, error) {
+	return self.backend().TTL(key)
+}
+
+// Incr 整数自增
+//
+// 参数:
+//   - key string: 缓存键
+//   - delta int64: 增量，可为负数
+//
+// 返回:
+//   - int64: 自增后的值
+//   - error: 自增过程中的错误信息
+func (self *cache) Incr(key string, delta int64) (int64, error) {
+	return self.backend().Incr(key, delta)
+}
+
+// Decr 整数自减
+//
+// 参数:
+//   - key string: 缓存键
+//   - delta int64: 减量
+//
+// 返回:
+//   - int64: 自减后的值
+//   - error: 自减过程中的错误信息
+func (self *cache) Decr(key string, delta int64) (int64, error) {
+	return self.backend().Incr(key, -delta)
+}
+
+// Keys 获取匹配模式的缓存键
+//
+// 参数:
+//   - pattern string: glob 模式，规则见 MatchKey
+//
+// 返回:
+//   - []string: 缓存键
+//   - error: 获取过程中的错误信息
+func (self *cache) Keys(pattern string) ([]string, error) {
+	return self.backend().Keys(pattern)
+}
+
+// Stats 获取缓存统计
+//
+// 返回:
+//   - CacheStats: 统计快照，后端未实现 CacheStatsProvider 时为空
+func (self *cache) Stats() CacheStats {
+	if provider, ok := self.backend().(CacheStatsProvider); ok {
+		return provider.Stats()
+	}
+	return CacheStats{}
+}
+
+// DelExp 删除已过期的缓存键
+//
+// 参数:
+//   - key string: 缓存键
+//
+// 返回:
+//   - bool: 是否删除，后端不支持时返回 false
+func (self *cache) DelExp(key string) bool {
+	if backend, ok := self.backend().(interface{ DelExp(key string) bool }); ok {
+		return backend.DelExp(key)
+	}
+	return false
+}
+
+// Subscribe 订阅缓存事件
+//
+// 参数:
+//   - buffer int: 事件通道缓冲大小
+//   - types ...CacheEventType: 订阅的事件类型，为空时订阅全部事件
+//
+// 返回:
+//   - <-chan CacheEvent: 事件通道，后端未实现 CacheSubscriber 时为已关闭的通道
+//   - func(): 取消订阅函数
+func (self *cache) Subscribe(buffer int, types ...CacheEventType) (<-chan CacheEvent, func()) {
+	if subscriber, ok := self.backend().(CacheSubscriber); ok {
+		return subscriber.Subscribe(buffer, types...)
+	}
+	events := make(chan CacheEvent)
+	close(events)
+	return events, func() {}
+}
+
+// SaveSnapshot 保存缓存快照
+//
+// 参数:
+//   - path string: 快照文件路径
+//
+// 返回:
+//   - error: 保存过程中的错误信息，后端未实现 CacheSnapshotter 时返回错误
+func (self *cache) SaveSnapshot(path string) error {
+	snapshotter, err := self.snapshotter("SaveSnapshot")
+	if err != nil {
+		return err
+	}
+	return snapshotter.SaveSnapshot(path)
+}
+
+// LoadSnapshot 加载缓存快照
+//
+// 参数:
+//   - path string: 快照文件路径
+//
+// 返回:
+//   - int: 加载的键数量
+//   - error: 加载过程中的错误信息，后端未实现 CacheSnapshotter 时返回错误
+func (self *cache) LoadSnapshot(path string) (int, error) {
+	snapshotter, err := self.snapshotter("LoadSnapshot")
+	if err != nil {
+		return 0, err
+	}
+	return snapshotter.LoadSnapshot(path)
+}
+
+// snapshotter 获取支持快照的缓存后端
+//
+// 参数:
+//   - name string: 调用的方法名称，用于错误信息
+//
+// 返回:
+//   - CacheSnapshotter: 支持快照的缓存后端
+//   - error: 后端不支持快照时的错误信息
+func (self *cache) snapshotter(name string) (CacheSnapshotter, error) {
+	backend := self.backend()
+	if snapshotter, ok := backend.(CacheSnapshotter); ok {
+		return snapshotter, nil
+	}
+	unsupportedMsg := i18n.T("server.cache.unsupported", map[string]any{
+		"backend": fmt.Sprintf("%T", backend),
+		"name":    name,
+	})
+	return nil, errors.New(unsupportedMsg)
+}
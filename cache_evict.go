@@ -0,0 +1,124 @@
+package goi
+
+import (
+	"container/list"
+)
+
+// evictionIndex 缓存淘汰索引
+//
+// 字段:
+//   - lru list.List: 按最近访问时间排序的链表，最近访问的在前
+//   - lfu [MaxCounterValue + 1]list.List: 按 LFU 计数分桶的链表，桶内最近访问的在前
+//
+// 说明:
+//   - 访问、写入与删除均为 O(1)，查找最不频繁使用的键最多遍历 MaxCounterValue+1 个桶
+type evictionIndex struct {
+	lru list.List
+	lfu [MaxCounterValue + 1]list.List
+}
+
+// indexElements 缓存项在淘汰索引中的位置
+//
+// 字段:
+//   - lru *list.Element: LRU 链表中的元素
+//   - lfu *list.Element: LFU 计数桶中的元素
+type indexElements struct {
+	lru *list.Element
+	lfu *list.Element
+}
+
+// push 加入缓存项
+//
+// 参数:
+//   - cacheItem *cacheItems: 缓存项
+//   - elements *indexElements: 用于记录缓存项在索引中的位置
+func (self *evictionIndex) push(cacheItem *cacheItems, elements *indexElements) {
+	elements.lru = self.lru.PushFront(cacheItem)
+	elements.lfu = self.lfu[cacheItem.lfu].PushFront(cacheItem)
+}
+
+// remove 移除缓存项
+//
+// 参数:
+//   - cacheItem *cacheItems: 缓存项
+//   - elements *indexElements: 缓存项在索引中的位置
+func (self *evictionIndex) remove(cacheItem *cacheItems, elements *indexElements) {
+	self.lru.Remove(elements.lru)
+	self.lfu[cacheItem.lfu].Remove(elements.lfu)
+	elements.lru = nil
+	elements.lfu = nil
+}
+
+// touch 记录一次访问
+//
+// 参数:
+//   - cacheItem *cacheItems: 已更新 LFU 计数的缓存项
+//   - elements *indexElements: 缓存项在索引中的位置
+//   - counter uint8: 访问前的 LFU 计数
+func (self *evictionIndex) touch(cacheItem *cacheItems, elements *indexElements, counter uint8) {
+	self.lru.MoveToFront(elements.lru)
+	if counter == cacheItem.lfu {
+		self.lfu[counter].MoveToFront(elements.lfu)
+		return
+	}
+	self.lfu[counter].Remove(elements.lfu)
+	elements.lfu = self.lfu[cacheItem.lfu].PushFront(cacheItem)
+}
+
+// leastRecent 获取最近最少使用的缓存项
+//
+// 返回:
+//   - *cacheItems: 缓存项，索引为空时为 nil
+func (self *evictionIndex) leastRecent() *cacheItems {
+	element := self.lru.Back()
+	if element == nil {
+		return nil
+	}
+	return element.Value.(*cacheItems)
+}
+
+// leastFrequent 获取最不频繁使用的缓存项，计数相同时取最近最少使用的
+//
+// 返回:
+//   - *cacheItems: 缓存项，索引为空时为 nil
+func (self *evictionIndex) leastFrequent() *cacheItems {
+	for i := range self.lfu {
+		if element := self.lfu[i].Back(); element != nil {
+			return element.Value.(*cacheItems)
+		}
+	}
+	return nil
+}
+
+// ttlHeap 按过期时间排序的最小堆，实现 heap.Interface
+type ttlHeap []*cacheItems
+
+func (self ttlHeap) Len() int {
+	return len(self)
+}
+
+func (self ttlHeap) Less(i, j int) bool {
+	return self[i].expires.Before(self[j].expires)
+}
+
+func (self ttlHeap) Swap(i, j int) {
+	self[i], self[j] = self[j], self[i]
+	self[i].heapIndex = i
+	self[j].heapIndex = j
+}
+
+func (self *ttlHeap) Push(value any) {
+	cacheItem := value.(*cacheItems)
+	cacheItem.heapIndex = len(*self)
+	*self = append(*self, cacheItem)
+}
+
+func (self *ttlHeap) Pop() any {
+	old := *self
+	n := len(old)
+	cacheItem := old[n-1]
+	old[n-1] = nil
+	cacheItem.heapIndex = -1
+	*self = old[:n-1]
+	return cacheItem
+}
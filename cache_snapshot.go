@@ -0,0 +1,260 @@
+package goi
+
+import (
+	"bufio"
+	"context"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
+)
+
+// 缓存快照文件头: 魔数 + 格式版本
+const (
+	cacheSnapshotMagic   = "GOICACHE"
+	cacheSnapshotVersion = 1
+)
+
+// snapshotItem 快照中的缓存项
+//
+// 字段:
+//   - Key string: 缓存键
+//   - Kind reflect.Kind: 值类型的种类
+//   - Bytes []byte: gob 编码后的值
+//   - Expires time.Time: 过期时间，零值表示永不过期
+type snapshotItem struct {
+	Key     string
+	Kind    reflect.Kind
+	Bytes   []byte
+	Expires time.Time
+}
+
+// snapshotPath 获取快照文件路径
+//
+// 返回:
+//   - string: 相对路径基于 Settings.BaseDir 解析后的路径
+func (self *MemoryCache) snapshotPath() string {
+	if filepath.IsAbs(self.SnapshotPath) || Settings.BaseDir == "" {
+		return self.SnapshotPath
+	}
+	return filepath.Join(Settings.BaseDir, self.SnapshotPath)
+}
+
+// initSnapshot 恢复快照并向 engine 注册定期快照与关闭时快照
+//
+// 参数:
+//   - engine *Engine: 注册后台任务与关闭回调的 Engine
+func (self *MemoryCache) initSnapshot(engine *Engine) {
+	path := self.snapshotPath()
+	snapshotPathMsg := i18n.T("server.cache.snapshot_path", map[string]any{
+		"path": path,
+	})
+	Log.Log(meta, snapshotPathMsg)
+
+	count, err := self.LoadSnapshot(path)
+	if err != nil {
+		snapshotErrorMsg := i18n.T("server.cache.snapshot_error", map[string]any{
+			"err": err,
+		})
+		Log.Error(snapshotErrorMsg)
+	} else {
+		snapshotRestoreMsg := i18n.T("server.cache.snapshot_restore", map[string]any{
+			"count": count,
+		})
+		Log.Log(meta, snapshotRestoreMsg)
+	}
+
+	snapshot := &cacheSnapshot{cache: self, path: path}
+	if self.SnapshotInterval > 0 {
+		engine.registerOnStartup(snapshot)
+	}
+	engine.registerOnShutdown(snapshot)
+}
+
+// SaveSnapshot 将未过期的缓存项保存到快照文件
+//
+// 参数:
+//   - path string: 快照文件路径
+//
+// 返回:
+//   - error: 保存过程中的错误信息
+//
+// 说明:
+//   - 先写入同目录下的临时文件再重命名，写入中断不会损坏已有快照
+func (self *MemoryCache) SaveSnapshot(path string) error {
+	nowTime := GetTime()
+	self.lock.RLock()
+	items := make([]snapshotItem, 0, len(self.dict))
+	for _, cacheItem := range self.dict {
+		if !cacheItem.expires.IsZero() && !cacheItem.expires.After(nowTime) {
+			continue
+		}
+		items = append(items, snapshotItem{
+			Key:     cacheItem.key,
+			Kind:    cacheItem.kind,
+			Bytes:   cacheItem.bytes, // 写入时整体替换，不会原地修改
+			Expires: cacheItem.expires,
+		})
+	}
+	self.lock.RUnlock()
+
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name()) // 重命名成功后为空操作
+
+	writer := bufio.NewWriter(file)
+	writer.WriteString(cacheSnapshotMagic)
+	writer.WriteByte(cacheSnapshotVersion)
+	err = gob.NewEncoder(writer).Encode(items)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// LoadSnapshot 从快照文件恢复缓存项
+//
+// 参数:
+//   - path string: 快照文件路径
+//
+// 返回:
+//   - int: 恢复的键数量，已过期的键会被跳过
+//   - error: 文件格式或版本不正确、超出最大容量时返回错误，文件不存在时不返回错误
+func (self *MemoryCache) LoadSnapshot(path string) (int, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	header := make([]byte, len(cacheSnapshotMagic)+1)
+	_, err = io.ReadFull(reader, header)
+	if err != nil || string(header[:len(cacheSnapshotMagic)]) != cacheSnapshotMagic {
+		snapshotInvalidMsg := i18n.T("server.cache.snapshot_invalid", map[string]any{
+			"path": path,
+		})
+		return 0, errors.New(snapshotInvalidMsg)
+	}
+	version := header[len(cacheSnapshotMagic)]
+	if version != cacheSnapshotVersion {
+		snapshotVersionMsg := i18n.T("server.cache.snapshot_version", map[string]any{
+			"version": version,
+		})
+		return 0, errors.New(snapshotVersionMsg)
+	}
+	var items []snapshotItem
+	err = gob.NewDecoder(reader).Decode(&items)
+	if err != nil {
+		return 0, err
+	}
+
+	nowTime := GetTime()
+	count := 0
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	for _, item := range items {
+		if !item.Expires.IsZero() {
+			if !item.Expires.After(nowTime) {
+				continue
+			}
+			if self.ExpirationPolicy == Scheduled { // 定时删除
+				key := item.Key
+				time.AfterFunc(item.Expires.Sub(nowTime), func() { self.DelExp(key) })
+			}
+		}
+		err = self.store(item.Key, item.Kind, item.Bytes, item.Expires)
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// cacheSnapshot 缓存快照任务，定期保存并在关闭服务时保存
+//
+// 字段:
+//   - cache *MemoryCache: 内存缓存
+//   - path string: 快照文件路径
+type cacheSnapshot struct {
+	cache *MemoryCache
+	path  string
+}
+
+// StartupName 启动任务名称
+//
+// 返回:
+//   - string: 启动任务名称
+func (self *cacheSnapshot) StartupName() string {
+	return i18n.T("server.cache.snapshot")
+}
+
+// OnStartup 定期保存快照
+//
+// 参数:
+//   - ctx context.Context: 上下文对象，用于控制协程退出
+//   - wg *sync.WaitGroup: 等待组，用于同步协程
+func (self *cacheSnapshot) OnStartup(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(self.cache.SnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := self.cache.SaveSnapshot(self.path)
+			if err != nil {
+				snapshotErrorMsg := i18n.T("server.cache.snapshot_error", map[string]any{
+					"err": err,
+				})
+				Log.Error(snapshotErrorMsg)
+			}
+		}
+	}
+}
+
+// ShutdownName 关闭服务名称
+//
+// 返回:
+//   - string: 关闭服务名称
+func (self *cacheSnapshot) ShutdownName() string {
+	return i18n.T("server.cache.snapshot")
+}
+
+// OnShutdown 关闭服务时保存快照
+//
+// 返回:
+//   - error: 保存过程中的错误信息
+func (self *cacheSnapshot) OnShutdown() error {
+	return self.cache.SaveSnapshot(self.path)
+}
@@ -0,0 +1,171 @@
+package goi
+
+import (
+	"time"
+)
+
+// CacheStats 缓存统计
+//
+// 字段:
+//   - Items int: 缓存键数量，包含尚未清理的过期键
+//   - UsedSize int64: 当前已使用容量(字节)
+//   - MaxSize int64: 缓存最大容量(字节)，0 表示不限制
+//   - Hits int64: 读取命中次数
+//   - Misses int64: 读取未命中次数
+//   - Sets int64: 写入次数
+//   - Evictions int64: 因超出最大容量被淘汰的键数量
+//   - Expirations int64: 因过期被删除的键数量
+//   - DroppedEvents int64: 订阅者缓冲区已满而丢弃的事件数量
+type CacheStats struct {
+	Items         int   `json:"items"`
+	UsedSize      int64 `json:"used_size"`
+	MaxSize       int64 `json:"max_size"`
+	Hits          int64 `json:"hits"`
+	Misses        int64 `json:"misses"`
+	Sets          int64 `json:"sets"`
+	Evictions     int64 `json:"evictions"`
+	Expirations   int64 `json:"expirations"`
+	DroppedEvents int64 `json:"dropped_events"`
+}
+
+// HitRatio 读取命中率
+//
+// 返回:
+//   - float64: 命中次数 / 读取次数，没有读取时为 0
+func (stats CacheStats) HitRatio() float64 {
+	total := stats.Hits + stats.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(stats.Hits) / float64(total)
+}
+
+// CacheStatsProvider 提供统计信息的缓存后端
+type CacheStatsProvider interface {
+	// Stats 获取缓存统计
+	Stats() CacheStats
+}
+
+// CacheEventType 缓存事件类型
+type CacheEventType string
+
+// 缓存事件类型
+const (
+	CacheEventSet     CacheEventType = "set"     // 写入键，包括 Set、SetNX、CompareAndSwap、Incr
+	CacheEventExpired CacheEventType = "expired" // 键过期被删除
+	CacheEventEvicted CacheEventType = "evicted" // 超出最大容量键被淘汰
+)
+
+// CacheEvent 缓存事件
+//
+// 字段:
+//   - Type CacheEventType: 事件类型
+//   - Key string: 缓存键
+//   - Time time.Time: 事件时间
+type CacheEvent struct {
+	Type CacheEventType
+	Key  string
+	Time time.Time
+}
+
+// cacheSubscriber 缓存事件订阅者
+//
+// 字段:
+//   - events chan CacheEvent: 事件通道
+//   - types []CacheEventType: 订阅的事件类型，为空表示全部
+type cacheSubscriber struct {
+	events chan CacheEvent
+	types  []CacheEventType
+}
+
+// accepts 检查是否订阅了该事件类型
+//
+// 参数:
+//   - eventType CacheEventType: 事件类型
+//
+// 返回:
+//   - bool: 是否订阅
+func (self *cacheSubscriber) accepts(eventType CacheEventType) bool {
+	if len(self.types) == 0 {
+		return true
+	}
+	for _, t := range self.types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Stats 获取内存缓存统计
+//
+// 返回:
+//   - CacheStats: 统计快照
+func (self *MemoryCache) Stats() CacheStats {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	stats := self.stats
+	stats.Items = len(self.dict)
+	stats.UsedSize = self.usedSize
+	stats.MaxSize = self.MaxSize
+	return stats
+}
+
+// Subscribe 订阅内存缓存事件
+//
+// 参数:
+//   - buffer int: 事件通道缓冲区大小
+//   - types ...CacheEventType: 订阅的事件类型，为空表示全部
+//
+// 返回:
+//   - <-chan CacheEvent: 事件通道
+//   - func(): 取消订阅，取消后事件通道关闭
+//
+// 说明:
+//   - 事件在缓存操作中同步投递，缓冲区已满时丢弃事件并计入 CacheStats.DroppedEvents，不会阻塞缓存操作
+func (self *MemoryCache) Subscribe(buffer int, types ...CacheEventType) (<-chan CacheEvent, func()) {
+	subscriber := &cacheSubscriber{
+		events: make(chan CacheEvent, buffer),
+		types:  types,
+	}
+	self.eventLock.Lock()
+	self.subscribers = append(self.subscribers, subscriber)
+	self.eventLock.Unlock()
+
+	unsubscribe := func() {
+		self.eventLock.Lock()
+		defer self.eventLock.Unlock()
+		for i, s := range self.subscribers {
+			if s == subscriber {
+				self.subscribers = append(self.subscribers[:i:i], self.subscribers[i+1:]...)
+				close(subscriber.events)
+				return
+			}
+		}
+	}
+	return subscriber.events, unsubscribe
+}
+
+// emit 投递缓存事件，调用前需持有写锁
+//
+// 参数:
+//   - eventType CacheEventType: 事件类型
+//   - key string: 缓存键
+func (self *MemoryCache) emit(eventType CacheEventType, key string) {
+	self.eventLock.RLock()
+	defer self.eventLock.RUnlock()
+	if len(self.subscribers) == 0 {
+		return
+	}
+	event := CacheEvent{Type: eventType, Key: key, Time: GetTime()}
+	for _, subscriber := range self.subscribers {
+		if !subscriber.accepts(eventType) {
+			continue
+		}
+		select {
+		case subscriber.events <- event:
+		default:
+			self.stats.DroppedEvents++
+		}
+	}
+}
@@ -0,0 +1,406 @@
+package goi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestMemoryCacheIncrTTL 验证内存缓存的整数自增与剩余过期时间
+func TestMemoryCacheIncrTTL(t *testing.T) {
+	cache := NewMemoryCache()
+
+	if n, err := cache.Incr("hits", 2); err != nil || n != 2 {
+		t.Fatalf("Incr new = %d, %v", n, err)
+	}
+	if n, err := cache.Incr("hits", -5); err != nil || n != -3 {
+		t.Fatalf("Incr existing = %d, %v", n, err)
+	}
+	var hits int64
+	if err := cache.Get("hits", &hits); err != nil || hits != -3 {
+		t.Fatalf("Get hits = %d, %v", hits, err)
+	}
+
+	_ = cache.Set("name", "goi", 60)
+	if _, err := cache.Incr("name", 1); err == nil {
+		t.Error("Incr on string value succeeded")
+	}
+	if ttl, _ := cache.TTL("name"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("TTL name = %v", ttl)
+	}
+	if ttl, _ := cache.TTL("hits"); ttl != TTLPersistent {
+		t.Errorf("TTL hits = %v", ttl)
+	}
+	_ = cache.Del("name")
+	if ttl, _ := cache.TTL("name"); ttl != TTLNotExists {
+		t.Errorf("TTL deleted = %v", ttl)
+	}
+}
+
+// TestMemoryCacheAtomic 验证 SetNX、CompareAndSwap 的并发语义与 Expire、Persist、Keys
+func TestMemoryCacheAtomic(t *testing.T) {
+	cache := NewMemoryCache()
+
+	var wg sync.WaitGroup
+	var winners sync.Map
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if ok, err := cache.SetNX("lock", i, 0); err == nil && ok {
+				winners.Store(i, true)
+			}
+		}(i)
+	}
+	wg.Wait()
+	count := 0
+	winners.Range(func(_, _ any) bool { count++; return true })
+	if count != 1 {
+		t.Fatalf("SetNX winners = %d, want 1", count)
+	}
+
+	_ = cache.Set("counter", 0, 0)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var current int
+				if _, err := cache.Load("counter", &current); err != nil {
+					t.Error(err)
+					return
+				}
+				if ok, err := cache.CompareAndSwap("counter", current, current+1, 0); err != nil || ok {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	var counter int
+	if _ = cache.Get("counter", &counter); counter != 20 {
+		t.Errorf("counter = %d, want 20", counter)
+	}
+
+	if ok, _ := cache.Expire("counter", 60); !ok {
+		t.Error("Expire existing failed")
+	}
+	if ok, _ := cache.Persist("counter"); !ok {
+		t.Error("Persist failed")
+	}
+	if ttl, _ := cache.TTL("counter"); ttl != TTLPersistent {
+		t.Errorf("TTL after Persist = %v", ttl)
+	}
+	if ok, _ := cache.Expire("missing", 60); ok {
+		t.Error("Expire missing succeeded")
+	}
+	if ok, _ := cache.Expire("counter", 0); !ok || cache.Has("counter") {
+		t.Error("Expire 0 did not delete")
+	}
+
+	_ = cache.Set("user:1", 1, 0)
+	_ = cache.Set("user:2", 2, 0)
+	_ = cache.Set("order:1", 1, 0)
+	keys, _ := cache.Keys("user:*")
+	if len(keys) != 2 || keys[0] != "user:1" || keys[1] != "user:2" {
+		t.Errorf("Keys = %v", keys)
+	}
+}
+
+// TestCacheTyped 验证泛型读取区分未命中与零值
+func TestCacheTyped(t *testing.T) {
+	saved := Cache
+	Cache = newCache()
+	defer func() { Cache = saved }()
+
+	if _, ok, err := CacheGet[int]("n"); ok || err != nil {
+		t.Fatalf("CacheGet miss = %v, %v", ok, err)
+	}
+	_ = Cache.Set("n", 0, 0)
+	if n, ok, err := CacheGet[int]("n"); !ok || err != nil || n != 0 {
+		t.Fatalf("CacheGet zero = %d, %v, %v", n, ok, err)
+	}
+
+	calls := 0
+	loader := func() (string, error) {
+		calls++
+		return "loaded", nil
+	}
+	for i := 0; i < 2; i++ {
+		if value, err := CacheGetOrSet("s", 0, loader); err != nil || value != "loaded" {
+			t.Fatalf("CacheGetOrSet = %q, %v", value, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("loader calls = %d, want 1", calls)
+	}
+
+	loadErr := errors.New("load failed")
+	if _, err := CacheGetOrSet("e", 0, func() (int, error) { return 0, loadErr }); err != loadErr {
+		t.Errorf("CacheGetOrSet error = %v", err)
+	}
+	if Cache.Has("e") {
+		t.Error("failed load was cached")
+	}
+}
+
+// TestMatchKey 验证 glob 模式匹配
+func TestMatchKey(t *testing.T) {
+	prefix := EscapeKeyPattern("a*[b]?")
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"*", "a/b", true},
+		{"user:*", "user:1", true},
+		{"user:*", "order:1", false},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{"h\\*llo", "h*llo", true},
+		{"h\\*llo", "hello", false},
+		{prefix + "*", "a*[b]?c", true},
+		{prefix + "*", "ax[b]?c", false},
+	}
+	for _, test := range tests {
+		if got := MatchKey(test.pattern, test.key); got != test.want {
+			t.Errorf("MatchKey(%q, %q) = %v, want %v", test.pattern, test.key, got, test.want)
+		}
+	}
+}
+
+// newFullCache 创建已写入 a、b、c 三个键且恰好写满的内存缓存
+func newFullCache(t *testing.T, policy EvictPolicy, expires int) *MemoryCache {
+	cache := NewMemoryCache()
+	cache.EvictPolicy = policy
+	for _, key := range []string{"a", "b", "c"} {
+		if err := cache.Set(key, 1, expires); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache.MaxSize = cache.usedSize
+	return cache
+}
+
+// TestMemoryCacheEvict 验证各淘汰策略选出的键
+func TestMemoryCacheEvict(t *testing.T) {
+	var value int
+
+	cache := newFullCache(t, AllKeysLRU, 0)
+	_ = cache.Get("a", &value)
+	_ = cache.Set("d", 1, 0)
+	if cache.Has("b") || !cache.Has("a") || !cache.Has("c") || !cache.Has("d") {
+		t.Errorf("AllKeysLRU keys = %v", cache.dict)
+	}
+
+	cache = newFullCache(t, AllKeysLFU, 0)
+	_ = cache.Get("a", &value)
+	_ = cache.Get("b", &value)
+	_ = cache.Set("d", 1, 0)
+	if cache.Has("c") || !cache.Has("a") || !cache.Has("b") {
+		t.Errorf("AllKeysLFU keys = %v", cache.dict)
+	}
+
+	cache = newFullCache(t, VolatileTTL, 0)
+	_, _ = cache.Expire("a", 60)
+	_, _ = cache.Expire("b", 30)
+	_ = cache.Set("d", 1, 0)
+	if cache.Has("b") || !cache.Has("a") || !cache.Has("c") {
+		t.Errorf("VolatileTTL keys = %v", cache.dict)
+	}
+
+	cache = newFullCache(t, VolatileLRU, 0)
+	if err := cache.Set("d", 1, 0); err == nil || cache.Has("d") {
+		t.Error("VolatileLRU without volatile keys accepted write")
+	}
+
+	cache = newFullCache(t, NoEviction, 0)
+	if err := cache.Set("d", 1, 0); err == nil || cache.Has("d") {
+		t.Error("NoEviction accepted write")
+	}
+	if err := cache.Set("a", 2, 0); err != nil {
+		t.Errorf("NoEviction overwrite of same size = %v", err)
+	}
+}
+
+// TestMemoryCacheStress 并发读写、淘汰与过期，需配合 -race 运行
+func TestMemoryCacheStress(t *testing.T) {
+	policies := []EvictPolicy{AllKeysRandom, AllKeysLRU, AllKeysLFU, VolatileRandom, VolatileLRU, VolatileLFU, VolatileTTL}
+	for _, policy := range policies {
+		cache := NewMemoryCache()
+		cache.EvictPolicy = policy
+		cache.MaxSize = 4 << 10
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				var value int
+				for j := 0; j < 500; j++ {
+					key := fmt.Sprintf("key:%d", (i*31+j)%200)
+					switch j % 5 {
+					case 0:
+						_ = cache.Set(key, j, 1+j%3)
+					case 1:
+						_, _ = cache.Load(key, &value)
+					case 2:
+						_, _ = cache.Incr(key+":n", 1)
+					case 3:
+						_, _ = cache.Expire(key, j%2)
+					case 4:
+						cache.cachePeriodicDeleteExpires()
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		if cache.usedSize > cache.MaxSize {
+			t.Errorf("%s usedSize = %d, MaxSize = %d", evictPolicyNames[policy], cache.usedSize, cache.MaxSize)
+		}
+		var size int64
+		for _, cacheItem := range cache.dict {
+			size += cacheItem.size()
+		}
+		if size != cache.usedSize || cache.allKeys.lru.Len() != len(cache.dict) || len(cache.ttl) != cache.volatile.lru.Len() {
+			t.Errorf("%s index out of sync", evictPolicyNames[policy])
+		}
+	}
+}
+
+// TestMemoryCacheSnapshot 验证快照保存与恢复
+func TestMemoryCacheSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "snapshot.bin")
+
+	cache := NewMemoryCache()
+	_ = cache.Set("name", "goi", 0)
+	_ = cache.Set("session", "s1", 60)
+	_, _ = cache.Incr("hits", 3)
+	_ = cache.Set("expired", "x", 60)
+	cache.dict["expired"].expires = GetTime().Add(-time.Second)
+	if err := cache.SaveSnapshot(path); err != nil {
+		t.Fatal(err)
+	}
+	if matches, _ := filepath.Glob(path + ".*"); len(matches) != 0 {
+		t.Errorf("temporary files left: %v", matches)
+	}
+
+	restored := NewMemoryCache()
+	count, err := restored.LoadSnapshot(path)
+	if err != nil || count != 3 {
+		t.Fatalf("LoadSnapshot = %d, %v", count, err)
+	}
+	var name string
+	if _ = restored.Get("name", &name); name != "goi" {
+		t.Errorf("name = %q", name)
+	}
+	if ttl, _ := restored.TTL("session"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("TTL session = %v", ttl)
+	}
+	if n, err := restored.Incr("hits", 1); err != nil || n != 4 {
+		t.Errorf("Incr restored = %d, %v", n, err)
+	}
+	if restored.Has("expired") {
+		t.Error("expired key restored")
+	}
+
+	if count, err = NewMemoryCache().LoadSnapshot(path + ".missing"); err != nil || count != 0 {
+		t.Errorf("LoadSnapshot missing = %d, %v", count, err)
+	}
+	data, _ := os.ReadFile(path)
+	data[len(cacheSnapshotMagic)] = cacheSnapshotVersion + 1
+	_ = os.WriteFile(path, data, 0600)
+	if _, err = NewMemoryCache().LoadSnapshot(path); err == nil {
+		t.Error("LoadSnapshot accepted unknown version")
+	}
+}
+
+// TestMemoryCacheStatsEvents 验证统计计数与事件订阅
+func TestMemoryCacheStatsEvents(t *testing.T) {
+	cache := NewMemoryCache()
+	events, unsubscribe := cache.Subscribe(10, CacheEventExpired, CacheEventEvicted)
+	all, unsubscribeAll := cache.Subscribe(1)
+	defer unsubscribeAll()
+
+	_ = cache.Set("a", 1, 0)
+	_ = cache.Set("b", 1, 60)
+	cache.dict["b"].expires = GetTime().Add(-time.Second)
+	var value int
+	_ = cache.Get("a", &value)
+	_ = cache.Get("b", &value)
+	cache.MaxSize = cache.usedSize
+	cache.EvictPolicy = AllKeysLRU
+	_ = cache.Set("c", 1, 0)
+
+	stats := cache.Stats()
+	want := CacheStats{Items: 1, UsedSize: stats.UsedSize, MaxSize: cache.MaxSize, Hits: 1, Misses: 1, Sets: 3, Evictions: 1, Expirations: 1, DroppedEvents: 4}
+	if stats != want {
+		t.Errorf("Stats = %+v, want %+v", stats, want)
+	}
+	if ratio := stats.HitRatio(); ratio != 0.5 {
+		t.Errorf("HitRatio = %v", ratio)
+	}
+
+	if event := <-events; event.Type != CacheEventExpired || event.Key != "b" {
+		t.Errorf("first event = %+v", event)
+	}
+	if event := <-events; event.Type != CacheEventEvicted || event.Key != "a" {
+		t.Errorf("second event = %+v", event)
+	}
+	if event := <-all; event.Type != CacheEventSet || event.Key != "a" {
+		t.Errorf("buffered event = %+v", event)
+	}
+	unsubscribe()
+	if _, ok := <-events; ok {
+		t.Error("events channel not closed after unsubscribe")
+	}
+}
+
+// plainBackend 仅实现 CacheBackend 的缓存后端
+type plainBackend struct {
+	CacheBackend
+}
+
+// TestCacheBackendCapabilities 验证缓存管理器按后端能力转发订阅与快照
+func TestCacheBackendCapabilities(t *testing.T) {
+	manager := newCache()
+	manager.MaxSize = 1
+	if manager.memory.MaxSize != 1 {
+		t.Fatalf("memory MaxSize = %d", manager.memory.MaxSize)
+	}
+	manager.MaxSize = 0
+	events, unsubscribe := manager.Subscribe(1, CacheEventSet)
+	_ = manager.Set("a", 1, 0)
+	if event := <-events; event.Type != CacheEventSet || event.Key != "a" {
+		t.Errorf("memory event = %+v", event)
+	}
+	unsubscribe()
+
+	manager.Backend = plainBackend{CacheBackend: NewMemoryCache()}
+	events, unsubscribe = manager.Subscribe(1)
+	defer unsubscribe()
+	if _, ok := <-events; ok {
+		t.Error("unsupported Subscribe channel not closed")
+	}
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	if err := manager.SaveSnapshot(path); err == nil {
+		t.Error("unsupported SaveSnapshot returned nil error")
+	}
+	if _, err := manager.LoadSnapshot(path); err == nil {
+		t.Error("unsupported LoadSnapshot returned nil error")
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("snapshot written by unsupported backend: %v", err)
+	}
+	if manager.DelExp("a") {
+		t.Error("unsupported DelExp deleted key")
+	}
+}
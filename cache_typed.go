@@ -0,0 +1,198 @@
+package goi
+
+import (
+	"strings"
+)
+
+// CacheGet 获取指定类型的缓存值
+//
+// 参数:
+//   - key string: 缓存键
+//
+// 返回:
+//   - T: 缓存值，未命中时为零值
+//   - bool: 是否命中，用于区分未命中与零值
+//   - error: 获取过程中的错误信息
+//
+// 示例:
+//
+//	user, ok, err := goi.CacheGet[User]("user:1")
+func CacheGet[T any](key string) (T, bool, error) {
+	var value T
+	ok, err := Cache.Load(key, &value)
+	if err != nil || !ok {
+		var zero T
+		return zero, ok, err
+	}
+	return value, true, nil
+}
+
+// CacheGetOrSet 获取指定类型的缓存值，未命中时调用 loader 加载并写入缓存
+//
+// 参数:
+//   - key string: 缓存键
+//   - expires int: 过期时间(秒)，0表示永不过期
+//   - loader func() (T, error): 加载函数，返回错误时不写入缓存
+//
+// 返回:
+//   - T: 缓存值
+//   - error: 获取或加载过程中的错误信息
+//
+// 说明:
+//   - 使用 SetNX 写入，并发加载时以先写入的值为准
+func CacheGetOrSet[T any](key string, expires int, loader func() (T, error)) (T, error) {
+	value, ok, err := CacheGet[T](key)
+	if err != nil || ok {
+		return value, err
+	}
+
+	value, err = loader()
+	if err != nil {
+		return value, err
+	}
+	ok, err = Cache.SetNX(key, value, expires)
+	if err != nil || ok {
+		return value, err
+	}
+	// 其它请求已写入，返回已存在的值
+	current, ok, err := CacheGet[T](key)
+	if err != nil || !ok {
+		return value, err
+	}
+	return current, nil
+}
+
+// MatchKey 检查缓存键是否匹配 glob 模式
+//
+// 参数:
+//   - pattern string: glob 模式，支持 "*"、"?"、"[abc]"、"[^a]"、"[a-z]" 与 "\\" 转义
+//   - key string: 缓存键
+//
+// 返回:
+//   - bool: 是否匹配
+//
+// 说明:
+//   - 与 Redis KEYS/SCAN 的匹配规则一致，"*" 可匹配包括 "/" 在内的任意字符
+func MatchKey(pattern string, key string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(key); i++ {
+				if MatchKey(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(key) == 0 {
+				return false
+			}
+			key = key[1:]
+			pattern = pattern[1:]
+		case '[':
+			if len(key) == 0 {
+				return false
+			}
+			rest, matched, ok := matchClass(pattern[1:], key[0])
+			if !ok {
+				// 未闭合的 "[" 按普通字符处理
+				if key[0] != '[' {
+					return false
+				}
+				key = key[1:]
+				pattern = pattern[1:]
+				continue
+			}
+			if !matched {
+				return false
+			}
+			key = key[1:]
+			pattern = rest
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(key) == 0 || key[0] != pattern[0] {
+				return false
+			}
+			key = key[1:]
+			pattern = pattern[1:]
+		}
+	}
+	return len(key) == 0
+}
+
+// EscapeKeyPattern 转义 glob 特殊字符，使字符串在 MatchKey、Keys 中按字面匹配
+//
+// 参数:
+//   - s string: 字符串，例如缓存键前缀
+//
+// 返回:
+//   - string: 转义后的模式
+//
+// 示例:
+//
+//	keys, err := goi.Cache.Keys(goi.EscapeKeyPattern(prefix) + "*")
+func EscapeKeyPattern(s string) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '*', '?', '[', ']', '\\':
+			builder.WriteByte('\\')
+		}
+		builder.WriteByte(s[i])
+	}
+	return builder.String()
+}
+
+// matchClass 匹配字符集合
+//
+// 参数:
+//   - pattern string: "[" 之后的模式
+//   - c byte: 待匹配字符
+//
+// 返回:
+//   - string: "]" 之后的模式
+//   - bool: 是否匹配
+//   - bool: 字符集合是否闭合
+func matchClass(pattern string, c byte) (string, bool, bool) {
+	negate := false
+	if len(pattern) > 0 && pattern[0] == '^' {
+		negate = true
+		pattern = pattern[1:]
+	}
+	matched := false
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == ']':
+			return pattern[i+1:], matched != negate, true
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+			if pattern[i] == c {
+				matched = true
+			}
+		case i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']':
+			low, high := pattern[i], pattern[i+2]
+			if low > high {
+				low, high = high, low
+			}
+			if low <= c && c <= high {
+				matched = true
+			}
+			i += 2
+		default:
+			if pattern[i] == c {
+				matched = true
+			}
+		}
+	}
+	return "", false, false
+}
@@ -183,7 +183,7 @@ func init() {
 	// Debug
 	Server.Settings.Debug = true
 	// 网络协议
-	Server.Settings.Network = "tcp" // 默认 "tcp" 常用网络协议 "tcp"、"tcp4"、"tcp6"、"udp"、"udp4"、"udp6
+	Server.Settings.Network = "tcp" // 默认 "tcp" 支持网络协议 "tcp"、"tcp4"、"tcp6"、"unix"
 	// 监听地址
 	Server.Settings.BindAddress = "0.0.0.0" // 默认 0.0.0.0
 	// 端口
@@ -262,6 +262,7 @@ func init() {
 	Server.Cache.EvictPolicy = goi.AllKeysLRU   // 缓存淘汰策略
 	Server.Cache.ExpirationPolicy = goi.Periodic // 过期策略
 	Server.Cache.MaxSize = 0                     // 单位为字节，0 为不限制使用
+	Server.Cache.SnapshotPath = ""               // 缓存快照文件，相对路径基于 BaseDir，为空时不启用
 
 	// 创建日志，或者修改全局日志配置
 	Server.Log = newDefaultLog() // 默认日志
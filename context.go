@@ -2,33 +2,102 @@ package goi
 
 import (
 	"context"
+	"crypto/x509"
 	"embed"
-	"encoding/json"
 	"errors"
 	"io"
 	"io/fs"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
 	"github.com/NeverStopDreamingWang/goi/v2/parser"
+	"github.com/NeverStopDreamingWang/goi/v2/render"
 )
 
 const ContentType = "Content-Type"
 
+// FormatQueryParam 指定响应格式的查询参数，取值为编码器名称，例如 ?format=xml
+const FormatQueryParam = "format"
+
+// ErrNotAcceptable 没有可接受的响应格式，Render 返回该错误时框架响应 406 Not Acceptable
+var ErrNotAcceptable = errors.New("goi: not acceptable")
+
 // Request 封装HTTP请求相关信息
 //
 // 参数:
 //   - Object *http.Request: 原始HTTP请求对象，包含完整的HTTP请求信息
 //   - PathParams Params: 路由参数，存储URL路径中的动态参数值
 //   - Params Params: 自定义参数，可在整个请求处理过程中传递和共享数据
+//   - etag string: CheckPreconditions 声明的 ETag
+//   - lastModified time.Time: CheckPreconditions 声明的最后修改时间
+//   - renderers []string: 路由可协商的响应 MIME 类型，为空表示全部已注册类型
 //
 // 用于在处理请求时提供统一的访问接口
 type Request struct {
-	Object     *http.Request
-	PathParams Params
-	Params     Params
+	Object       *http.Request
+	PathParams   Params
+	Params       Params
+	etag         string
+	lastModified time.Time
+	renderers    []string
+	engine       *Engine // 处理请求的 Engine
+}
+
+// Context 获取请求上下文
+//
+// 返回:
+//   - context.Context: 请求上下文，客户端断开、路由超时或服务停止时取消
+func (request *Request) Context() context.Context {
+	return request.Object.Context()
+}
+
+// ClientCertificate 获取已验证的客户端证书
+//
+// 返回:
+//   - *x509.Certificate: 双向认证（mTLS）中通过 SSL.ClientCAPath 验证的客户端证书，未验证时为 nil
+//
+// 说明:
+//   - 可根据证书的 Subject、DNSNames 等字段进行授权
+func (request *Request) ClientCertificate() *x509.Certificate {
+	state := request.Object.TLS
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
+// ID 获取请求 ID
+//
+// 返回:
+//   - string: 请求 ID
+func (request *Request) ID() string {
+	requestID, _ := RequestIDKey.Value(request.Context())
+	return requestID
+}
+
+// Log 获取绑定请求 ID 的日志器
+//
+// 返回:
+//   - *Logger: 框架为本次请求创建的日志器，输出的每行日志都包含请求 ID
+//
+// 说明:
+//   - 日志器在请求开始时由处理请求的 Engine 的日志器派生，并通过 LoggerKey 存入请求上下文，
+//     中间件、视图与框架在请求期间输出的访问日志、异常日志均使用该日志器
+//   - 仅持有 context.Context 的代码可通过 LoggerKey.Value(ctx) 获取同一日志器
+//   - 未经 Engine 处理的请求由全局 Log 派生
+func (request *Request) Log() *Logger {
+	if logger, ok := LoggerKey.Get(request); ok && logger != nil {
+		return logger
+	}
+	logger := Log
+	if request.engine != nil && request.engine.Log != nil {
+		logger = request.engine.Log
+	}
+	return logger.WithRequestID(request.ID())
 }
 
 // WithContext 更新请求对象中的上下文信息
@@ -40,6 +109,81 @@ func (request *Request) WithContext(ctx context.Context) {
 	request.Object = request.Object.WithContext(ctx)
 }
 
+// ContextKey 类型化的上下文键
+//
+// 以指针作为键，不同包之间不会冲突，读取时直接返回 T 类型的值
+type ContextKey[T any] struct {
+	name string
+}
+
+// NewContextKey 创建类型化的上下文键
+//
+// 参数:
+//   - name string: 键名称，仅用于调试输出
+//
+// 返回:
+//   - *ContextKey[T]: 上下文键
+func NewContextKey[T any](name string) *ContextKey[T] {
+	return &ContextKey[T]{name: name}
+}
+
+// String 返回键名称
+func (key *ContextKey[T]) String() string {
+	return key.name
+}
+
+// WithValue 返回携带值的新上下文
+//
+// 参数:
+//   - ctx context.Context: 父上下文
+//   - value T: 值
+//
+// 返回:
+//   - context.Context: 新的上下文
+func (key *ContextKey[T]) WithValue(ctx context.Context, value T) context.Context {
+	return context.WithValue(ctx, key, value)
+}
+
+// Value 读取上下文中的值
+//
+// 参数:
+//   - ctx context.Context: 上下文
+//
+// 返回:
+//   - T: 值
+//   - bool: 是否存在
+func (key *ContextKey[T]) Value(ctx context.Context) (T, bool) {
+	value, ok := ctx.Value(key).(T)
+	return value, ok
+}
+
+// Set 在请求上下文中设置值
+//
+// 参数:
+//   - request *Request: 请求对象
+//   - value T: 值
+func (key *ContextKey[T]) Set(request *Request, value T) {
+	request.WithContext(key.WithValue(request.Context(), value))
+}
+
+// Get 读取请求上下文中的值
+//
+// 参数:
+//   - request *Request: 请求对象
+//
+// 返回:
+//   - T: 值
+//   - bool: 是否存在
+func (key *ContextKey[T]) Get(request *Request) (T, bool) {
+	return key.Value(request.Context())
+}
+
+// RequestIDKey 请求 ID 的上下文键
+var RequestIDKey = NewContextKey[string]("request_id")
+
+// LoggerKey 请求日志器的上下文键，值为绑定请求 ID 的 *Logger
+var LoggerKey = NewContextKey[*Logger]("logger")
+
 // QueryParams 解析并返回URL查询字符串参数
 // 匹配模式: URL中的查询参数，格式为 ?key=value&key2=value2
 // 示例: /api/users?page=1&size=10
@@ -147,6 +291,11 @@ func (responseWriter *ResponseWriter) Write(b []byte) (int, error) {
 	return bytesWritten, err
 }
 
+// Unwrap 返回底层 http.ResponseWriter，供 http.ResponseController 使用
+func (responseWriter *ResponseWriter) Unwrap() http.ResponseWriter {
+	return responseWriter.ResponseWriter
+}
+
 // Flush 若底层 http.ResponseWriter 支持 http.Flusher 则触发分块刷新，否则静默 no-op
 //
 // 用于流式响应、SSE、长连接等场景，由 RawHandler 视图主动调用
@@ -156,7 +305,7 @@ func (responseWriter *ResponseWriter) Flush() {
 	}
 }
 
-// RawHandler 视图函数返回 RawHandler 时，框架不会再对返回值做 json.Marshal 与
+// RawHandler 视图函数返回 RawHandler 时，框架不会再对返回值做编码与
 // Content-Length 设置，转而由 fn 自行写入 status / header / body 并按需 flush
 //
 // 适用于：流式响应、SSE、反向代理透传、自定义二进制协议等需要直接操作 ResponseWriter 的场景
@@ -189,7 +338,76 @@ func (response *Response) Header() http.Header {
 	return response.headers
 }
 
-func (response *Response) write(request *Request, responseWriter *ResponseWriter) (int, error) {
+// Streaming 检查响应数据是否由框架直接写出而不经过 Render
+//
+// 返回:
+//   - bool: RawHandler、文件、文件系统与 io.Reader 返回 true
+func (response *Response) Streaming() bool {
+	switch response.Data.(type) {
+	case RawHandler, *os.File, http.File, FS, fs.FS, embed.FS, io.Reader:
+		return true
+	}
+	return false
+}
+
+// Render 将响应数据编码为字节
+//
+// 参数:
+//   - request *Request: HTTP请求对象，用于内容协商，为 nil 时编码为 JSON
+//
+// 返回:
+//   - []byte: 编码后的数据，string 与 []byte 原样返回，其它类型按 Accept 请求头或 ?format= 参数协商编码
+//   - string: 默认 Content-Type，响应头未设置 Content-Type 时使用
+//   - error: 编码过程中的错误信息，没有可接受的格式时为 ErrNotAcceptable
+//
+// 说明:
+//   - 流式响应数据（见 Streaming）不应调用 Render
+//   - 协商编码时在响应头中追加 Vary: Accept
+func (response *Response) Render(request *Request) ([]byte, string, error) {
+	switch value := response.Data.(type) {
+	case string:
+		return []byte(value), "text/plain", nil
+	case []byte:
+		return value, "application/octet-stream", nil
+	}
+	if request == nil {
+		dataByte, err := render.JSON.Render(response.Data)
+		return dataByte, parser.MIMEJSON, err
+	}
+	addVary(response.Header(), "Accept")
+	mediaType, renderer, ok := render.Negotiate(
+		request.Object.Header.Get("Accept"),
+		request.Object.URL.Query().Get(FormatQueryParam),
+		request.renderers,
+	)
+	if !ok {
+		return nil, "", ErrNotAcceptable
+	}
+	dataByte, err := renderer.Render(response.Data)
+	if err != nil {
+		return nil, "", err
+	}
+	return dataByte, mediaType, nil
+}
+
+// addVary 追加 Vary 响应头，已包含时不重复添加
+//
+// 参数:
+//   - header http.Header: 响应头
+//   - name string: 请求头名称
+func addVary(header http.Header, name string) {
+	for _, value := range header.Values("Vary") {
+		for _, existing := range strings.Split(value, ",") {
+			existing = strings.TrimSpace(existing)
+			if existing == "*" || strings.EqualFold(existing, name) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", name)
+}
+
+func (response *Response) write(request *Request, responseWriter *ResponseWriter, etagMode ETagMode) (int, error) {
 	var err error
 	var dataByte []byte
 	var contentType string
@@ -202,18 +420,18 @@ func (response *Response) write(request *Request, responseWriter *ResponseWriter
 			}
 		}
 	}
+	request.setValidators(responseWriter.Header())
+	if response.Status == http.StatusNotModified {
+		// 304 不包含响应体
+		responseWriter.WriteHeader(response.Status)
+		return 0, nil
+	}
 	switch value := response.Data.(type) {
 	case nil:
 		return 0, nil
 	case RawHandler:
 		err = value(responseWriter, request)
 		return int(responseWriter.bytes), err
-	case string:
-		dataByte = []byte(value)
-		contentType = "text/plain"
-	case []byte:
-		dataByte = value
-		contentType = "application/octet-stream"
 	case *os.File:
 		defer value.Close()
 		fileInfo, err := value.Stat()
@@ -254,8 +472,20 @@ func (response *Response) write(request *Request, responseWriter *ResponseWriter
 		written, copyErr := io.Copy(responseWriter, value)
 		return int(written), copyErr
 	default:
-		dataByte, err = json.Marshal(value)
-		contentType = "application/json"
+		dataByte, contentType, err = response.Render(request)
+		if errors.Is(err, ErrNotAcceptable) {
+			notAcceptableMsg := i18n.T("server.not_acceptable", map[string]any{
+				"accept": request.Object.Header.Get("Accept"),
+			})
+			response.Status = http.StatusNotAcceptable
+			dataByte, contentType, err = []byte(notAcceptableMsg), "text/plain", nil
+		}
+		// 同步 Render 协商编码时追加的 Vary
+		for _, value := range response.Header().Values("Vary") {
+			for _, name := range strings.Split(value, ",") {
+				addVary(responseWriter.Header(), strings.TrimSpace(name))
+			}
+		}
 	}
 	if err != nil {
 		responseJsonErrorMsg := i18n.T("server.response_error", map[string]any{
@@ -263,6 +493,19 @@ func (response *Response) write(request *Request, responseWriter *ResponseWriter
 		})
 		return 0, errors.New(responseJsonErrorMsg)
 	}
+	// 自动计算 ETag 并处理 If-None-Match/If-Modified-Since
+	if response.Status == http.StatusOK {
+		if responseWriter.Header().Get("ETag") == "" {
+			if etag := computeETag(dataByte, etagMode); etag != "" {
+				responseWriter.Header().Set("ETag", etag)
+			}
+		}
+		if notModified(request.Object, responseWriter.Header()) {
+			responseWriter.Header().Del("Content-Length")
+			responseWriter.WriteHeader(http.StatusNotModified)
+			return 0, nil
+		}
+	}
 	// 若未显式设置 Content-Length，则为非流式响应补上
 	if responseWriter.Header().Get("Content-Length") == "" {
 		responseWriter.Header().Set("Content-Length", strconv.Itoa(len(dataByte)))
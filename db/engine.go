@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
@@ -20,6 +21,24 @@ type Engine interface {
 	Query(query string, args ...any) (*sql.Rows, error)
 }
 
+// ContextEngine 支持上下文的数据库引擎，内置引擎均已实现
+//
+// 说明:
+//   - 上下文取消或超时后查询立即返回，通常传入 request.Context()
+//   - 自定义引擎可选实现，使用前通过类型断言判断
+//
+// 示例:
+//
+//	if ctxEngine, ok := engine.(db.ContextEngine); ok {
+//		rows, err := ctxEngine.QueryContext(request.Context(), query, args...)
+//	}
+type ContextEngine interface {
+	Engine
+	ExecuteContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
 type ConnectFunc func(UseDatabases string, database *goi.Database) Engine
 
 // engineMu 保护 engines
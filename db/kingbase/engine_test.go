@@ -1,7 +1,9 @@
 package kingbase_test
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -115,3 +117,52 @@ func ExampleEngine_Insert() {
 	fmt.Printf("插入成功")
 	// Output:
 }
+
+func ExampleEngine_QueryContext() {
+	kbDB := db.Connect[*kingbase.Engine]("default")
+
+	// 自定义引擎不一定实现 db.ContextEngine，使用前通过类型断言判断
+	var engine db.Engine = kbDB
+	ctxEngine, ok := engine.(db.ContextEngine)
+	if !ok {
+		fmt.Println("不支持上下文查询")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Format(time.DateTime)
+	_, err := ctxEngine.ExecuteContext(ctx, `UPDATE "user_tb" SET "update_time" = ? WHERE "username" = ?`, now, "test_user")
+	if err != nil {
+		fmt.Println("更新错误:", err)
+		return
+	}
+
+	var username string
+	err = ctxEngine.QueryRowContext(ctx, `SELECT "username" FROM "user_tb" WHERE "username" = ?`, "test_user").Scan(&username)
+	if err != nil {
+		fmt.Println("查询错误:", err)
+		return
+	}
+	fmt.Println("用户名:", username)
+
+	rows, err := ctxEngine.QueryContext(ctx, `SELECT "username" FROM "user_tb" WHERE "username" = ?`, "test_user")
+	if err != nil {
+		fmt.Println("查询错误:", err)
+		return
+	}
+	fmt.Println("查询结果:", rows.Next())
+	rows.Close()
+
+	// 上下文取消后查询立即返回
+	canceledCtx, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	_, err = ctxEngine.QueryContext(canceledCtx, `SELECT "username" FROM "user_tb"`)
+	fmt.Println("取消后查询:", errors.Is(err, context.Canceled))
+
+	// Output:
+	// 用户名: test_user
+	// 查询结果: true
+	// 取消后查询: true
+}
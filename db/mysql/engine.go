@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -175,12 +176,30 @@ func (engine Engine) WithTransaction(transactionFunc TransactionFunc, args ...an
 //
 // 说明:
 //   - 支持在事务中使用
+//   - 等同于使用 context.Background() 调用 ExecuteContext
 func (engine *Engine) Execute(query string, args ...any) (sql.Result, error) {
+	return engine.ExecuteContext(context.Background(), query, args...)
+}
+
+// ExecuteContext 使用上下文执行 SQL 语句
+//
+// 参数:
+//   - ctx: context.Context 上下文，取消或超时时中断执行
+//   - query: string SQL语句，使用 ? 作为占位符，例如: "id = ? AND status IN (?)"
+//   - args: ...any SQL参数值列表
+//
+// 返回:
+//   - sql.Result: 执行操作的结果
+//   - error: 执行过程中的错误
+//
+// 说明:
+//   - 支持在事务中使用
+func (engine *Engine) ExecuteContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	engine.sql = query
 	if engine.transaction != nil {
-		return engine.transaction.Exec(query, args...)
+		return engine.transaction.ExecContext(ctx, query, args...)
 	}
-	return engine.DB.Exec(query, args...)
+	return engine.DB.ExecContext(ctx, query, args...)
 }
 
 // QueryRow 执行查询 SQL 语句
@@ -195,12 +214,30 @@ func (engine *Engine) Execute(query string, args ...any) (sql.Result, error) {
 // 说明:
 //   - 查询失败时返回nil
 //   - 支持在事务中使用
+//   - 等同于使用 context.Background() 调用 QueryRowContext
 func (engine *Engine) QueryRow(query string, args ...any) *sql.Row {
+	return engine.QueryRowContext(context.Background(), query, args...)
+}
+
+// QueryRowContext 使用上下文执行查询 SQL 语句
+//
+// 参数:
+//   - ctx: context.Context 上下文，取消或超时时中断执行
+//   - query: string SQL查询语句，使用 ? 作为占位符，例如: "id = ? AND status IN (?)"
+//   - args: ...any SQL参数值列表
+//
+// 返回:
+//   - *sql.Row: 查询结果行
+//
+// 说明:
+//   - 查询失败时返回nil
+//   - 支持在事务中使用
+func (engine *Engine) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
 	engine.sql = query
 	if engine.transaction != nil {
-		return engine.transaction.QueryRow(query, args...)
+		return engine.transaction.QueryRowContext(ctx, query, args...)
 	}
-	return engine.DB.QueryRow(query, args...)
+	return engine.DB.QueryRowContext(ctx, query, args...)
 }
 
 // Query 执行查询 SQL 语句
@@ -217,12 +254,32 @@ func (engine *Engine) QueryRow(query string, args ...any) *sql.Row {
 //   - 返回的结果集需要调用方手动关闭
 //   - 查询失败时返回nil和错误信息
 //   - 支持在事务中使用
+//   - 等同于使用 context.Background() 调用 QueryContext
 func (engine *Engine) Query(query string, args ...any) (*sql.Rows, error) {
+	return engine.QueryContext(context.Background(), query, args...)
+}
+
+// QueryContext 使用上下文执行查询 SQL 语句
+//
+// 参数:
+//   - ctx: context.Context 上下文，取消或超时时中断执行
+//   - query: string SQL查询语句，使用 ? 作为占位符，例如: "id = ? AND status IN (?)"
+//   - args: ...any SQL参数值列表
+//
+// 返回:
+//   - *sql.Rows: 查询结果集
+//   - error: 查询过程中的错误
+//
+// 说明:
+//   - 返回的结果集需要调用方手动关闭
+//   - 查询失败时返回nil和错误信息
+//   - 支持在事务中使用
+func (engine *Engine) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
 	engine.sql = query
 	if engine.transaction != nil {
-		return engine.transaction.Query(query, args...)
+		return engine.transaction.QueryContext(ctx, query, args...)
 	}
-	return engine.DB.Query(query, args...)
+	return engine.DB.QueryContext(ctx, query, args...)
 }
 
 // Migrate 根据模型创建数据库表
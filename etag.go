@@ -0,0 +1,159 @@
+package goi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETagMode 自动 ETag 模式
+type ETagMode uint8
+
+// 自动 ETag 模式
+const (
+	ETagWeak   ETagMode = iota // 弱 ETag W/"..."，响应内容在压缩等转换后仍视为相同
+	ETagStrong                 // 强 ETag "..."，可用于 If-Match 与 Range 请求
+	ETagOff                    // 不自动计算 ETag
+)
+
+// computeETag 根据响应内容计算 ETag
+//
+// 参数:
+//   - data []byte: 响应内容
+//   - mode ETagMode: ETag 模式
+//
+// 返回:
+//   - string: ETag，ETagOff 时为空
+func computeETag(data []byte, mode ETagMode) string {
+	if mode == ETagOff {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if mode == ETagWeak {
+		return "W/" + etag
+	}
+	return etag
+}
+
+// etagMatch 检查 ETag 是否匹配 If-Match/If-None-Match 列表
+//
+// 参数:
+//   - list string: 逗号分隔的 ETag 列表，"*" 匹配任意 ETag
+//   - etag string: 当前资源的 ETag
+//   - weak bool: 是否使用弱比较，强比较时弱 ETag 不匹配
+//
+// 返回:
+//   - bool: 是否匹配
+//
+// 说明:
+//   - "*" 在未声明 ETag 时同样匹配，表示资源存在即可
+func etagMatch(list string, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if etag == "" {
+			continue
+		}
+		if weak {
+			if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		} else if !strings.HasPrefix(candidate, "W/") && !strings.HasPrefix(etag, "W/") && candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified 检查 GET/HEAD 请求的 If-None-Match 与 If-Modified-Since
+//
+// 参数:
+//   - r *http.Request: HTTP请求对象
+//   - header http.Header: 包含 ETag、Last-Modified 的响应头
+//
+// 返回:
+//   - bool: 是否应返回 304 Not Modified
+func notModified(r *http.Request, header http.Header) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etagMatch(ifNoneMatch, header.Get("ETag"), true)
+	}
+	lastModified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	ifModifiedSince, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(ifModifiedSince)
+}
+
+// CheckPreconditions 声明资源的 ETag 与最后修改时间，并检查条件请求头
+//
+// 参数:
+//   - etag string: 资源 ETag，需包含引号，例如 `"v3"`、`W/"v3"`，为空表示不声明
+//   - lastModified time.Time: 资源最后修改时间，零值表示不声明
+//
+// 返回:
+//   - Response: 前置条件不满足时的响应，412 Precondition Failed 或 304 Not Modified
+//   - bool: 是否继续执行处理函数
+//
+// 说明:
+//   - If-Match、If-Unmodified-Since 不满足时返回 412，If-Match 使用强比较
+//   - GET/HEAD 的 If-None-Match、If-Modified-Since 满足时返回 304，其它方法 If-None-Match 匹配时返回 412
+//   - 声明的 ETag 与最后修改时间会自动写入响应头
+//
+// 示例:
+//
+//	if response, ok := request.CheckPreconditions(article.ETag(), article.UpdatedAt); !ok {
+//		return response
+//	}
+func (request *Request) CheckPreconditions(etag string, lastModified time.Time) (Response, bool) {
+	request.etag = etag
+	request.lastModified = lastModified
+
+	response := Response{Status: http.StatusPreconditionFailed, Data: ""}
+	request.setValidators(response.Header())
+	header := request.Object.Header
+
+	if ifMatch := header.Get("If-Match"); ifMatch != "" {
+		if !etagMatch(ifMatch, etag, false) {
+			return response, false
+		}
+	} else if ifUnmodifiedSince, err := http.ParseTime(header.Get("If-Unmodified-Since")); err == nil && !lastModified.IsZero() {
+		if lastModified.Truncate(time.Second).After(ifUnmodifiedSince) {
+			return response, false
+		}
+	}
+
+	if notModified(request.Object, response.Header()) {
+		response.Status = http.StatusNotModified
+		return response, false
+	}
+	method := request.Object.Method
+	if method != http.MethodGet && method != http.MethodHead && etagMatch(header.Get("If-None-Match"), etag, true) {
+		return response, false
+	}
+	return Response{}, true
+}
+
+// setValidators 写入 CheckPreconditions 声明的 ETag 与 Last-Modified，已存在时不覆盖
+//
+// 参数:
+//   - header http.Header: 响应头
+func (request *Request) setValidators(header http.Header) {
+	if request.etag != "" && header.Get("ETag") == "" {
+		header.Set("ETag", request.etag)
+	}
+	if !request.lastModified.IsZero() && header.Get("Last-Modified") == "" {
+		header.Set("Last-Modified", request.lastModified.UTC().Format(http.TimeFormat))
+	}
+}
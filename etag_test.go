@@ -0,0 +1,86 @@
+package goi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestETag 验证自动 ETag、304 与 CheckPreconditions 的 412
+func TestETag(t *testing.T) {
+	engine := NewHTTPServer()
+	engine.Log = NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer engine.Log.File.Close()
+	engine.Settings = &settings{ETag: ETagWeak}
+
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	calls := 0
+	engine.Router.Path("article", "文章", ViewSet{
+		GET: func(request *Request) any {
+			return map[string]string{"title": "goi"}
+		},
+		PUT: func(request *Request) any {
+			if response, ok := request.CheckPreconditions(`"v2"`, updatedAt); !ok {
+				return response
+			}
+			calls++
+			return "updated"
+		},
+		// 仅声明最后修改时间
+		PATCH: func(request *Request) any {
+			if response, ok := request.CheckPreconditions("", updatedAt); !ok {
+				return response
+			}
+			return "patched"
+		},
+	})
+
+	serve := func(method string, header http.Header) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, "/article", nil)
+		for name, values := range header {
+			request.Header[name] = values
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	first := serve(http.MethodGet, nil)
+	etag := first.Header().Get("ETag")
+	if len(etag) < 4 || etag[:3] != `W/"` {
+		t.Fatalf("ETag = %q", etag)
+	}
+	second := serve(http.MethodGet, http.Header{"If-None-Match": {etag}})
+	if second.Code != http.StatusNotModified || second.Body.Len() != 0 || second.Header().Get("ETag") != etag {
+		t.Errorf("If-None-Match = %d %q %q", second.Code, second.Body.String(), second.Header().Get("ETag"))
+	}
+	if recorder := serve(http.MethodGet, http.Header{"If-None-Match": {`"other"`}}); recorder.Code != http.StatusOK {
+		t.Errorf("If-None-Match mismatch = %d", recorder.Code)
+	}
+
+	if recorder := serve(http.MethodPut, http.Header{"If-Match": {`"v1"`}}); recorder.Code != http.StatusPreconditionFailed || calls != 0 {
+		t.Errorf("If-Match mismatch = %d, calls = %d", recorder.Code, calls)
+	}
+	stale := updatedAt.Add(-time.Hour).Format(http.TimeFormat)
+	if recorder := serve(http.MethodPut, http.Header{"If-Unmodified-Since": {stale}}); recorder.Code != http.StatusPreconditionFailed || calls != 0 {
+		t.Errorf("If-Unmodified-Since stale = %d, calls = %d", recorder.Code, calls)
+	}
+	recorder := serve(http.MethodPut, http.Header{"If-Match": {`"v2"`}})
+	if recorder.Code != http.StatusOK || calls != 1 || recorder.Header().Get("ETag") != `"v2"` || recorder.Header().Get("Last-Modified") == "" {
+		t.Errorf("If-Match match = %d, calls = %d, header = %v", recorder.Code, calls, recorder.Header())
+	}
+
+	if recorder := serve(http.MethodPatch, http.Header{"If-Match": {"*"}}); recorder.Code != http.StatusOK {
+		t.Errorf("If-Match * without ETag = %d", recorder.Code)
+	}
+	if recorder := serve(http.MethodPatch, http.Header{"If-Match": {`"v2"`}}); recorder.Code != http.StatusPreconditionFailed {
+		t.Errorf("If-Match without ETag = %d", recorder.Code)
+	}
+
+	engine.Settings.ETag = ETagOff
+	if recorder := serve(http.MethodGet, nil); recorder.Header().Get("ETag") != "" {
+		t.Errorf("ETagOff set ETag %q", recorder.Header().Get("ETag"))
+	}
+}
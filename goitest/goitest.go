@@ -0,0 +1,364 @@
+// Package goitest 提供在进程内调用 goi 路由的测试工具
+//
+// 请求直接交给 Engine.ServeHTTP 处理，不监听端口，不启动后台任务，断言失败时通过 testing.TB 报告错误
+package goitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/NeverStopDreamingWang/goi/v2"
+)
+
+// NewEngine 创建测试用的 Engine
+//
+// 参数:
+//   - t testing.TB: 测试对象
+//
+// 返回:
+//   - *goi.Engine: 日志写入 t.TempDir() 的 Engine，测试结束时关闭日志文件
+//
+// 注意:
+//   - Settings、Cache 与 Validation 与 goi.NewHTTPServer 相同，为包级共享对象
+func NewEngine(t testing.TB) *goi.Engine {
+	t.Helper()
+	engine := goi.NewHTTPServer()
+	engine.Log = goi.NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	t.Cleanup(func() {
+		engine.Log.File.Close()
+	})
+	return engine
+}
+
+// Client 在进程内调用 Engine 的测试客户端
+type Client struct {
+	t      testing.TB
+	engine *goi.Engine
+	header http.Header
+}
+
+// NewClient 创建测试客户端
+//
+// 参数:
+//   - t testing.TB: 测试对象
+//   - engine *goi.Engine: 处理请求的 Engine
+//
+// 返回:
+//   - *Client: 测试客户端
+//
+// 示例:
+//
+//	client := goitest.NewClient(t, engine)
+//	client.Get("/user/1").Status(http.StatusOK).JSONPath("data.name", "goi")
+func NewClient(t testing.TB, engine *goi.Engine) *Client {
+	return &Client{
+		t:      t,
+		engine: engine,
+		header: make(http.Header),
+	}
+}
+
+// WithHeader 创建携带指定请求头的客户端
+//
+// 参数:
+//   - name string: 请求头名称
+//   - value string: 请求头值
+//
+// 返回:
+//   - *Client: 新的测试客户端，不影响原客户端
+func (client *Client) WithHeader(name string, value string) *Client {
+	header := client.header.Clone()
+	header.Set(name, value)
+	return &Client{
+		t:      client.t,
+		engine: client.engine,
+		header: header,
+	}
+}
+
+// Get 发送 GET 请求
+func (client *Client) Get(path string) *Response {
+	client.t.Helper()
+	return client.Do(http.MethodGet, path, nil)
+}
+
+// Post 发送 POST 请求
+func (client *Client) Post(path string, body any) *Response {
+	client.t.Helper()
+	return client.Do(http.MethodPost, path, body)
+}
+
+// Put 发送 PUT 请求
+func (client *Client) Put(path string, body any) *Response {
+	client.t.Helper()
+	return client.Do(http.MethodPut, path, body)
+}
+
+// Patch 发送 PATCH 请求
+func (client *Client) Patch(path string, body any) *Response {
+	client.t.Helper()
+	return client.Do(http.MethodPatch, path, body)
+}
+
+// Delete 发送 DELETE 请求
+func (client *Client) Delete(path string) *Response {
+	client.t.Helper()
+	return client.Do(http.MethodDelete, path, nil)
+}
+
+// Do 发送请求
+//
+// 参数:
+//   - method string: 请求方法
+//   - path string: 请求路径，可包含查询参数
+//   - body any: 请求体
+//
+// 返回:
+//   - *Response: 响应断言对象
+//
+// 说明:
+//   - body 为 nil 时不发送请求体
+//   - string、[]byte 与 io.Reader 原样发送
+//   - url.Values 编码为表单，Content-Type 为 application/x-www-form-urlencoded
+//   - 其它类型编码为 JSON，Content-Type 为 application/json
+//   - 客户端已设置 Content-Type 时不覆盖
+func (client *Client) Do(method string, path string, body any) *Response {
+	client.t.Helper()
+	var reader io.Reader
+	var contentType string
+	switch value := body.(type) {
+	case nil:
+	case string:
+		reader = strings.NewReader(value)
+	case []byte:
+		reader = bytes.NewReader(value)
+	case io.Reader:
+		reader = value
+	case url.Values:
+		reader = strings.NewReader(value.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			client.t.Fatalf("goitest: encode body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	request := httptest.NewRequest(method, path, reader)
+	for name, values := range client.header {
+		request.Header[name] = values
+	}
+	if contentType != "" && request.Header.Get("Content-Type") == "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	recorder := httptest.NewRecorder()
+	client.engine.ServeHTTP(recorder, request)
+	return &Response{
+		t:        client.t,
+		Recorder: recorder,
+		method:   method,
+		path:     path,
+	}
+}
+
+// Response 响应断言对象，断言方法均返回自身以便链式调用
+//
+// 断言失败时调用 t.Errorf 记录错误并继续执行
+type Response struct {
+	t        testing.TB
+	Recorder *httptest.ResponseRecorder // 响应记录
+
+	method string
+	path   string
+}
+
+// Code 获取响应状态码
+func (response *Response) Code() int {
+	return response.Recorder.Code
+}
+
+// Text 获取响应体文本
+func (response *Response) Text() string {
+	return response.Recorder.Body.String()
+}
+
+// Status 断言响应状态码
+//
+// 参数:
+//   - code int: 期望的状态码
+func (response *Response) Status(code int) *Response {
+	response.t.Helper()
+	if response.Recorder.Code != code {
+		response.errorf("status = %d, want %d; body: %s", response.Recorder.Code, code, response.Text())
+	}
+	return response
+}
+
+// Header 断言响应头
+//
+// 参数:
+//   - name string: 响应头名称
+//   - value string: 期望的响应头值，为空时断言响应头不存在
+func (response *Response) Header(name string, value string) *Response {
+	response.t.Helper()
+	actual := response.Recorder.Header().Get(name)
+	if actual != value {
+		response.errorf("header %s = %q, want %q", name, actual, value)
+	}
+	return response
+}
+
+// Body 断言响应体文本
+//
+// 参数:
+//   - body string: 期望的响应体
+func (response *Response) Body(body string) *Response {
+	response.t.Helper()
+	if response.Text() != body {
+		response.errorf("body = %q, want %q", response.Text(), body)
+	}
+	return response
+}
+
+// BodyContains 断言响应体包含指定文本
+//
+// 参数:
+//   - substr string: 期望包含的文本
+func (response *Response) BodyContains(substr string) *Response {
+	response.t.Helper()
+	if !strings.Contains(response.Text(), substr) {
+		response.errorf("body = %q, want contains %q", response.Text(), substr)
+	}
+	return response
+}
+
+// JSON 断言响应体与期望值编码为 JSON 后相等
+//
+// 参数:
+//   - expected any: 期望值，可以是结构体、map 或切片，JSON 文本使用 json.RawMessage
+//
+// 说明:
+//   - 比较时忽略键顺序与空白，数字统一按 float64 比较
+func (response *Response) JSON(expected any) *Response {
+	response.t.Helper()
+	actual, ok := response.decode()
+	if !ok {
+		return response
+	}
+	want, err := normalizeJSON(expected)
+	if err != nil {
+		response.errorf("encode expected JSON: %v", err)
+		return response
+	}
+	if !reflect.DeepEqual(actual, want) {
+		response.errorf("JSON = %s, want %s", response.Text(), marshalJSON(want))
+	}
+	return response
+}
+
+// JSONPath 断言响应 JSON 中指定路径的值
+//
+// 参数:
+//   - path string: 以 "." 分隔的路径，数组元素使用下标，例如 "data.items.0.name"
+//   - expected any: 期望值，编码为 JSON 后比较
+func (response *Response) JSONPath(path string, expected any) *Response {
+	response.t.Helper()
+	actual, ok := response.decode()
+	if !ok {
+		return response
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch value := actual.(type) {
+		case map[string]any:
+			actual, ok = value[key]
+		case []any:
+			index, err := strconv.Atoi(key)
+			ok = err == nil && index >= 0 && index < len(value)
+			if ok {
+				actual = value[index]
+			}
+		default:
+			ok = false
+		}
+		if !ok {
+			response.errorf("JSON path %q not found in %s", path, response.Text())
+			return response
+		}
+	}
+	want, err := normalizeJSON(expected)
+	if err != nil {
+		response.errorf("encode expected JSON: %v", err)
+		return response
+	}
+	if !reflect.DeepEqual(actual, want) {
+		response.errorf("JSON path %q = %s, want %s", path, marshalJSON(actual), marshalJSON(want))
+	}
+	return response
+}
+
+// Decode 将响应 JSON 解码到 dest
+//
+// 参数:
+//   - dest any: 解码目标，必须为指针
+func (response *Response) Decode(dest any) *Response {
+	response.t.Helper()
+	err := json.Unmarshal(response.Recorder.Body.Bytes(), dest)
+	if err != nil {
+		response.errorf("decode JSON: %v; body: %s", err, response.Text())
+	}
+	return response
+}
+
+// decode 将响应体解码为通用 JSON 值
+func (response *Response) decode() (any, bool) {
+	response.t.Helper()
+	var value any
+	err := json.Unmarshal(response.Recorder.Body.Bytes(), &value)
+	if err != nil {
+		response.errorf("decode JSON: %v; body: %s", err, response.Text())
+		return nil, false
+	}
+	return value, true
+}
+
+// errorf 记录带请求方法与路径的断言错误
+func (response *Response) errorf(format string, args ...any) {
+	response.t.Helper()
+	response.t.Errorf("%s %s: %s", response.method, response.path, fmt.Sprintf(format, args...))
+}
+
+// normalizeJSON 将期望值转换为与 json.Unmarshal 结果可比较的通用 JSON 值
+func normalizeJSON(expected any) (any, error) {
+	var data []byte
+	switch value := expected.(type) {
+	case json.RawMessage:
+		data = value
+	default:
+		var err error
+		data, err = json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var value any
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+// marshalJSON 将通用 JSON 值编码为文本，用于错误信息
+func marshalJSON(value any) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
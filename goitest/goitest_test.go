@@ -0,0 +1,100 @@
+package goitest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/NeverStopDreamingWang/goi/v2"
+	"github.com/NeverStopDreamingWang/goi/v2/goitest"
+)
+
+// recordTB 记录断言错误的 testing.TB
+type recordTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *recordTB) Helper() {}
+
+func (tb *recordTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func newEngine(t *testing.T) *goi.Engine {
+	engine := goitest.NewEngine(t)
+	engine.Router.Path("user/<int:id>", "用户", goi.ViewSet{
+		GET: func(request *goi.Request) any {
+			var id int
+			request.PathParams.Get("id", &id)
+			response := goi.Response{
+				Status: http.StatusOK,
+				Data:   map[string]any{"code": 0, "data": map[string]any{"id": id, "tags": []string{"a", "b"}}},
+			}
+			response.Header().Set("X-User", fmt.Sprint(id))
+			return response
+		},
+	})
+	engine.Router.Path("echo", "回显", goi.ViewSet{
+		POST: func(request *goi.Request) any {
+			return map[string]any{
+				"content_type": request.Object.Header.Get("Content-Type"),
+				"token":        request.Object.Header.Get("Authorization"),
+				"body":         map[string]any(request.BodyParams()),
+			}
+		},
+	})
+	return engine
+}
+
+func TestClient(t *testing.T) {
+	client := goitest.NewClient(t, newEngine(t))
+
+	client.Get("/user/7").
+		Status(http.StatusOK).
+		Header("X-User", "7").
+		JSON(json.RawMessage(`{"data": {"tags": ["a", "b"], "id": 7}, "code": 0}`)).
+		JSONPath("data.id", 7).
+		JSONPath("data.tags.1", "b")
+
+	var result struct {
+		Data struct {
+			ID int `json:"id"`
+		} `json:"data"`
+	}
+	client.Get("/user/8").Decode(&result)
+	if result.Data.ID != 8 {
+		t.Errorf("Decode id = %d", result.Data.ID)
+	}
+
+	authorized := client.WithHeader("Authorization", "Bearer token")
+	authorized.Post("/echo", map[string]any{"name": "goi"}).
+		Status(http.StatusOK).
+		JSONPath("content_type", "application/json").
+		JSONPath("token", "Bearer token").
+		JSONPath("body.name", "goi")
+	authorized.Post("/echo", url.Values{"name": {"form"}}).
+		JSONPath("content_type", "application/x-www-form-urlencoded").
+		JSONPath("body.name", "form")
+	client.Post("/echo", nil).JSONPath("token", "")
+
+	client.Get("/missing").Status(http.StatusNotFound)
+}
+
+func TestResponseFailures(t *testing.T) {
+	tb := &recordTB{TB: t}
+	client := goitest.NewClient(tb, newEngine(t))
+
+	client.Get("/user/7").
+		Status(http.StatusCreated).
+		Header("X-User", "8").
+		BodyContains("missing").
+		JSON(map[string]any{"code": 1}).
+		JSONPath("data.tags.5", "a").
+		JSONPath("data.id", "7")
+	if len(tb.errors) != 6 {
+		t.Errorf("errors = %d, want 6: %q", len(tb.errors), tb.errors)
+	}
+}
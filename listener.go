@@ -0,0 +1,226 @@
+package goi
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
+)
+
+// Listener 监听器配置，作为 Settings.Listeners 的元素使用
+//
+// 说明:
+//   - 所有监听器共享同一个 Engine 处理请求，并随 Engine 一同启动与关闭
+//   - Settings.Listeners 为空时，使用 Settings.Network、BindAddress、Port 与 SSL 创建单个监听器
+//
+// 示例:
+//
+//	Server.Settings.Listeners = []goi.Listener{
+//		{Network: "tcp", Address: "0.0.0.0:443", SSL: goi.SSL{Enabled: true, CertPath: "ssl/server.crt", KeyPath: "ssl/server.key"}},
+//		{Network: "tcp", Address: "0.0.0.0:80", RedirectHTTPS: true},
+//		{Network: "unix", Address: "/run/myproject.sock"},
+//	}
+type Listener struct {
+	Network       string      // 网络协议 "tcp"、"tcp4"、"tcp6"、"unix"，默认 "tcp"
+	Address       string      // 监听地址，tcp 为 "host:port"，unix 为套接字文件路径
+	SSL           SSL         // SSL，Enabled 为 true 时使用 HTTPS
+	TLSConfig     *tls.Config // 自定义 TLS 配置，不为 nil 时使用 HTTPS，启用 SSL 时由 SSL 提供证书
+	RedirectHTTPS bool        // 是否将所有请求重定向到 HTTPS，开启后该监听器不再交由 Engine 处理
+	HTTPSPort     uint16      // 重定向的 HTTPS 端口，0 或 443 时省略端口
+}
+
+// validNetworks 支持的网络协议
+var validNetworks = []string{"tcp", "tcp4", "tcp6", "unix"}
+
+// checkNetwork 检查网络协议是否可用于 net.Listen 提供 HTTP 服务
+//
+// 参数:
+//   - network string: 网络协议
+//
+// 返回:
+//   - error: 不支持时的错误信息
+func checkNetwork(network string) error {
+	for _, valid := range validNetworks {
+		if network == valid {
+			return nil
+		}
+	}
+	invalidNetworkMsg := i18n.T("server.invalid_network", map[string]any{
+		"network": network,
+		"valid":   validNetworks,
+	})
+	return errors.New(invalidNetworkMsg)
+}
+
+// listeners 获取监听器配置
+//
+// 返回:
+//   - []Listener: Settings.Listeners，为空时由 Network、BindAddress、Port 与 SSL 生成
+func (engine *Engine) listeners() []Listener {
+	if len(engine.Settings.Listeners) != 0 {
+		return engine.Settings.Listeners
+	}
+	return []Listener{{
+		Network: engine.Settings.Network,
+		Address: net.JoinHostPort(engine.Settings.BindAddress, strconv.Itoa(int(engine.Settings.Port))),
+		SSL:     engine.Settings.SSL,
+	}}
+}
+
+// isTLS 是否使用 HTTPS
+func (listener Listener) isTLS() bool {
+	return listener.SSL.Enabled || listener.TLSConfig != nil
+}
+
+// scheme 获取监听器的协议
+func (listener Listener) scheme() string {
+	if listener.isTLS() {
+		return "https"
+	}
+	return "http"
+}
+
+// listen 创建网络监听
+//
+// 参数:
+//   - ctx context.Context: 创建监听使用的上下文
+//
+// 返回:
+//   - net.Listener: 网络监听，使用 HTTPS 时已包装 TLS
+//   - *certificateStore: SSL 证书存储，未启用 SSL 时为 nil
+//   - error: 网络协议无效、证书读取失败或监听失败时的错误信息
+//
+// 说明:
+//   - unix 套接字文件已存在且不是正在使用的套接字时先删除
+func (listener Listener) listen(ctx context.Context) (net.Listener, *certificateStore, error) {
+	network := listener.Network
+	if network == "" {
+		network = "tcp"
+	}
+	err := checkNetwork(network)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tlsConfig *tls.Config
+	var store *certificateStore
+	if listener.isTLS() {
+		tlsConfig, store, err = listener.tlsConfig()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if network == "unix" {
+		removeStaleSocket(listener.Address)
+	}
+	var listenConfig net.ListenConfig
+	ln, err := listenConfig.Listen(ctx, network, listener.Address)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+	return ln, store, nil
+}
+
+// tlsConfig 创建监听器的 TLS 配置
+//
+// 返回:
+//   - *tls.Config: TLS 配置，默认支持 HTTP/2
+//   - *certificateStore: SSL 证书存储，未启用 SSL 时为 nil
+//   - error: 证书读取失败时的错误信息
+func (listener Listener) tlsConfig() (*tls.Config, *certificateStore, error) {
+	tlsConfig := &tls.Config{}
+	if listener.TLSConfig != nil {
+		tlsConfig = listener.TLSConfig.Clone()
+	}
+	if len(tlsConfig.NextProtos) == 0 {
+		tlsConfig.NextProtos = []string{"h2", "http/1.1"}
+	}
+	if listener.SSL.Enabled == false {
+		return tlsConfig, nil, nil
+	}
+	store, err := newCertificateStore(listener.SSL)
+	if err != nil {
+		return nil, nil, err
+	}
+	store.configure(tlsConfig)
+	return tlsConfig, store, nil
+}
+
+// removeStaleSocket 删除残留的 unix 套接字文件，仍有进程监听时保留
+//
+// 参数:
+//   - path string: 套接字文件路径
+func removeStaleSocket(path string) {
+	info, err := os.Stat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		conn.Close()
+		return
+	}
+	_ = os.Remove(path)
+}
+
+// handler 获取监听器的请求处理程序
+//
+// 参数:
+//   - engine *Engine: 处理请求的 Engine
+//
+// 返回:
+//   - http.Handler: 开启 RedirectHTTPS 时为重定向处理程序，否则为 engine
+func (listener Listener) handler(engine *Engine) http.Handler {
+	if listener.RedirectHTTPS == false {
+		return engine
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		}
+		host = strings.Trim(host, "[]")
+		if listener.HTTPSPort != 0 && listener.HTTPSPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(int(listener.HTTPSPort)))
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+// logListen 记录监听地址
+//
+// 参数:
+//   - engine *Engine: 记录日志的 Engine
+//   - address net.Addr: 实际监听地址
+func (listener Listener) logListen(engine *Engine, address net.Addr) {
+	network := address.Network()
+	listenAddressMsg := i18n.T("server.listen_address", map[string]any{
+		"bind_address": fmt.Sprintf("%v://%v [%v]", listener.scheme(), address, network),
+	})
+	engine.Log.Log(meta, listenAddressMsg)
+
+	if engine.Settings.BindDomain == "" || network == "unix" {
+		return
+	}
+	_, port, err := net.SplitHostPort(address.String())
+	if err != nil {
+		return
+	}
+	listenDomainMsg := i18n.T("server.listen_address", map[string]any{
+		"bind_address": fmt.Sprintf("%v://%v [%v]", listener.scheme(), net.JoinHostPort(engine.Settings.BindDomain, port), network),
+	})
+	engine.Log.Log(meta, listenDomainMsg)
+}
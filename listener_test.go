@@ -0,0 +1,117 @@
+package goi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// serveListener 使用监听器配置启动服务
+func serveListener(t *testing.T, engine *Engine, listener Listener) net.Listener {
+	t.Helper()
+	ln, _, err := listener.listen(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: listener.handler(engine)}
+	go server.Serve(ln)
+	t.Cleanup(func() { server.Close() })
+	return ln
+}
+
+// TestListener 验证网络协议校验、unix 套接字、HTTPS 与 HTTPS 重定向
+func TestListener(t *testing.T) {
+	engine := NewHTTPServer()
+	engine.Log = NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer engine.Log.File.Close()
+	engine.Router.Path("ping", "ping", ViewSet{GET: func(request *Request) any { return "pong" }})
+
+	if _, _, err := (Listener{Network: "udp", Address: "127.0.0.1:0"}).listen(context.Background()); err == nil {
+		t.Error("udp listener accepted")
+	}
+
+	// unix 套接字，残留的套接字文件会被删除
+	socketPath := filepath.Join(t.TempDir(), "goi.sock")
+	stale, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+	serveListener(t, engine, Listener{Network: "unix", Address: socketPath})
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network string, address string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+		},
+	}}
+	response, err := client.Get("http://unix/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	if string(body) != "pong" {
+		t.Errorf("unix body = %q", body)
+	}
+
+	// HTTPS 与 HTTP/2
+	certDir := t.TempDir()
+	err = GenerateECCCertificate(x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}, certDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln := serveListener(t, engine, Listener{Address: "127.0.0.1:0", SSL: SSL{
+		Enabled:  true,
+		CertPath: filepath.Join(certDir, "localhost.crt"),
+		KeyPath:  filepath.Join(certDir, "localhost.key"),
+	}})
+	client = &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		ForceAttemptHTTP2: true,
+	}}
+	response, err = client.Get("https://" + ln.Addr().String() + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK || response.Proto != "HTTP/2.0" {
+		t.Errorf("https = %d %s", response.StatusCode, response.Proto)
+	}
+
+	// HTTPS 重定向
+	tests := []struct {
+		listener Listener
+		host     string
+		location string
+	}{
+		{Listener{RedirectHTTPS: true, HTTPSPort: 8443}, "example.com:8080", "https://example.com:8443/ping?a=1"},
+		{Listener{RedirectHTTPS: true}, "example.com", "https://example.com/ping?a=1"},
+		{Listener{RedirectHTTPS: true, HTTPSPort: 443}, "[::1]:80", "https://[::1]/ping?a=1"},
+	}
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodPost, "http://"+test.host+"/ping?a=1", nil)
+		recorder := httptest.NewRecorder()
+		test.listener.handler(engine).ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusPermanentRedirect || recorder.Header().Get("Location") != test.location {
+			t.Errorf("redirect %s = %d %q, want %q", test.host, recorder.Code, recorder.Header().Get("Location"), test.location)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package goi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Field 结构化日志字段
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Entry 单条日志记录
+type Entry struct {
+	Time      time.Time // 记录时间
+	Level     Level     // 日志级别
+	Name      string    // 日志器名称
+	RequestID string    // 请求 ID
+	Caller    string    // 调用位置，Logger.Caller 开启时记录，格式 "file:line"
+	Message   string    // 日志内容
+	Fields    []Field   // 附加字段
+	logs      []any     // 原始日志内容，用于 PrintFunc
+}
+
+// Encoder 日志编码器
+type Encoder interface {
+	// Encode 将日志记录编码为一行文本
+	//
+	// 参数:
+	//   - entry *Entry: 日志记录
+	//
+	// 返回:
+	//   - []byte: 编码结果，不含结尾换行
+	Encode(entry *Entry) []byte
+}
+
+// TextEncoder 文本日志编码器（默认）
+//
+// 说明:
+//   - 格式: [time] LEVEL [request_id] caller: message key=value ...
+type TextEncoder struct{}
+
+// Encode 编码为文本格式
+//
+// 参数:
+//   - entry *Entry: 日志记录
+//
+// 返回:
+//   - []byte: 编码结果
+func (TextEncoder) Encode(entry *Entry) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	buf.WriteString(entry.Time.Format(time.DateTime))
+	buf.WriteString("]")
+	if entry.Level != "" {
+		buf.WriteString(" ")
+		buf.WriteString(string(entry.Level))
+	}
+	if entry.RequestID != "" {
+		buf.WriteString(" [")
+		buf.WriteString(entry.RequestID)
+		buf.WriteString("]")
+	}
+	if entry.Caller != "" {
+		buf.WriteString(" ")
+		buf.WriteString(entry.Caller)
+		buf.WriteString(":")
+	}
+	buf.WriteString(" ")
+	buf.WriteString(entry.Message)
+	for _, field := range entry.Fields {
+		buf.WriteString(" ")
+		appendLogfmt(&buf, field.Key, field.Value)
+	}
+	return buf.Bytes()
+}
+
+// JSONEncoder JSON 日志编码器
+//
+// 说明:
+//   - 每行一个 JSON 对象，标准字段依次为 time、level、logger、request_id、caller、msg，随后为附加字段
+type JSONEncoder struct {
+	TimeFormat string // 时间格式，默认 time.RFC3339Nano
+}
+
+// Encode 编码为 JSON 格式
+//
+// 参数:
+//   - entry *Entry: 日志记录
+//
+// 返回:
+//   - []byte: 编码结果
+func (encoder JSONEncoder) Encode(entry *Entry) []byte {
+	timeFormat := encoder.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339Nano
+	}
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	appendJSON(&buf, "time", entry.Time.Format(timeFormat))
+	if entry.Level != "" {
+		buf.WriteString(",")
+		appendJSON(&buf, "level", string(entry.Level))
+	}
+	if entry.Name != "" {
+		buf.WriteString(",")
+		appendJSON(&buf, "logger", entry.Name)
+	}
+	if entry.RequestID != "" {
+		buf.WriteString(",")
+		appendJSON(&buf, "request_id", entry.RequestID)
+	}
+	if entry.Caller != "" {
+		buf.WriteString(",")
+		appendJSON(&buf, "caller", entry.Caller)
+	}
+	buf.WriteString(",")
+	appendJSON(&buf, "msg", entry.Message)
+	for _, field := range entry.Fields {
+		buf.WriteString(",")
+		appendJSON(&buf, field.Key, field.Value)
+	}
+	buf.WriteString("}")
+	return buf.Bytes()
+}
+
+// LogfmtEncoder logfmt 日志编码器
+//
+// 说明:
+//   - 格式: time=... level=... logger=... request_id=... caller=... msg=... key=value ...
+type LogfmtEncoder struct {
+	TimeFormat string // 时间格式，默认 time.RFC3339Nano
+}
+
+// Encode 编码为 logfmt 格式
+//
+// 参数:
+//   - entry *Entry: 日志记录
+//
+// 返回:
+//   - []byte: 编码结果
+func (encoder LogfmtEncoder) Encode(entry *Entry) []byte {
+	timeFormat := encoder.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339Nano
+	}
+	var buf bytes.Buffer
+	appendLogfmt(&buf, "time", entry.Time.Format(timeFormat))
+	if entry.Level != "" {
+		buf.WriteString(" ")
+		appendLogfmt(&buf, "level", string(entry.Level))
+	}
+	if entry.Name != "" {
+		buf.WriteString(" ")
+		appendLogfmt(&buf, "logger", entry.Name)
+	}
+	if entry.RequestID != "" {
+		buf.WriteString(" ")
+		appendLogfmt(&buf, "request_id", entry.RequestID)
+	}
+	if entry.Caller != "" {
+		buf.WriteString(" ")
+		appendLogfmt(&buf, "caller", entry.Caller)
+	}
+	buf.WriteString(" ")
+	appendLogfmt(&buf, "msg", entry.Message)
+	for _, field := range entry.Fields {
+		buf.WriteString(" ")
+		appendLogfmt(&buf, field.Key, field.Value)
+	}
+	return buf.Bytes()
+}
+
+// appendJSON 写入 JSON 键值对
+//
+// 参数:
+//   - buf *bytes.Buffer: 输出缓冲
+//   - key string: 键
+//   - value any: 值，无法序列化时使用 fmt.Sprint 结果
+func appendJSON(buf *bytes.Buffer, key string, value any) {
+	keyBytes, _ := json.Marshal(key)
+	buf.Write(keyBytes)
+	buf.WriteString(":")
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		valueBytes, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buf.Write(valueBytes)
+}
+
+// appendLogfmt 写入 logfmt 键值对
+//
+// 参数:
+//   - buf *bytes.Buffer: 输出缓冲
+//   - key string: 键
+//   - value any: 值，包含空白、引号、等号或不可见字符时加引号转义
+func appendLogfmt(buf *bytes.Buffer, key string, value any) {
+	buf.WriteString(key)
+	buf.WriteString("=")
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case error:
+		str = v.Error()
+	case nil:
+		str = ""
+	default:
+		str = fmt.Sprint(v)
+	}
+	if needsQuote(str) {
+		buf.WriteString(strconv.Quote(str))
+	} else {
+		buf.WriteString(str)
+	}
+}
+
+// needsQuote 检查 logfmt 值是否需要加引号
+//
+// 参数:
+//   - str string: 值
+//
+// 返回:
+//   - bool: 是否需要加引号
+func needsQuote(str string) bool {
+	if str == "" {
+		return true
+	}
+	for _, r := range str {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || r == utf8.RuneError {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldsFromArgs 将键值对参数转换为字段
+//
+// 参数:
+//   - args []any: Field、slog.Attr 或交替出现的键与值
+//
+// 返回:
+//   - []Field: 字段列表，缺少值的键记为 "!BADKEY"
+func fieldsFromArgs(args []any) []Field {
+	fields := make([]Field, 0, len(args)/2+1)
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i].(type) {
+		case Field:
+			fields = append(fields, arg)
+		case string:
+			if i+1 < len(args) {
+				fields = append(fields, Field{Key: arg, Value: args[i+1]})
+				i++
+			} else {
+				fields = append(fields, Field{Key: "!BADKEY", Value: arg})
+			}
+		default:
+			if attr, ok := slogAttr(arg); ok {
+				fields = appendAttr(fields, "", attr)
+				continue
+			}
+			fields = append(fields, Field{Key: "!BADKEY", Value: arg})
+		}
+	}
+	return fields
+}
@@ -0,0 +1,187 @@
+package goi
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
+)
+
+// splitLogPath 拆分日志路径
+//
+// 参数:
+//   - path string: 日志路径，例如 "logs/server.log"
+//
+// 返回:
+//   - string: 目录，例如 "logs"
+//   - string: 文件名（不含扩展名），例如 "server"
+//   - string: 扩展名，例如 ".log"
+func splitLogPath(path string) (string, string, string) {
+	fileName := filepath.Base(path)
+	fileExt := filepath.Ext(fileName)
+	return filepath.Dir(path), strings.TrimSuffix(fileName, fileExt), fileExt
+}
+
+// logFileExists 检查日志文件是否存在
+//
+// 参数:
+//   - path string: 文件路径
+//
+// 返回:
+//   - bool: 是否存在，无法确定时视为存在
+func logFileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil || !os.IsNotExist(err)
+}
+
+// archive 压缩切割后的日志文件并清理历史日志
+//
+// 参数:
+//   - oldFilePath string: 切割后的日志文件路径
+func (self *Logger) archive(oldFilePath string) {
+	defer self.archiveWG.Done()
+	self.archiveLock.Lock()
+	defer self.archiveLock.Unlock()
+
+	if self.Compress {
+		err := compressLogFile(oldFilePath)
+		if err != nil {
+			compressErrorMsg := i18n.T("log.split_log_compress_error", map[string]any{
+				"name": self.Name,
+				"err":  err,
+			})
+			self.Error(compressErrorMsg)
+		}
+	}
+
+	err := self.cleanArchives()
+	if err != nil {
+		cleanErrorMsg := i18n.T("log.split_log_clean_error", map[string]any{
+			"name": self.Name,
+			"err":  err,
+		})
+		self.Error(cleanErrorMsg)
+	}
+}
+
+// compressLogFile gzip 压缩日志文件，完成后删除原文件
+//
+// 参数:
+//   - path string: 日志文件路径
+//
+// 返回:
+//   - error: 压缩过程中的错误信息
+//
+// 说明:
+//   - 压缩文件保留原文件的修改时间，保证清理时按切割时间排序
+func compressLogFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	writer := gzip.NewWriter(dst)
+	writer.Name = filepath.Base(path)
+	writer.ModTime = info.ModTime()
+	_, err = io.Copy(writer, src)
+	if err == nil {
+		err = writer.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	err = os.Rename(tmpPath, path+".gz")
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	_ = os.Chtimes(path+".gz", info.ModTime(), info.ModTime())
+	_ = src.Close()
+	return os.Remove(path)
+}
+
+// cleanArchives 按保留策略清理历史日志
+//
+// 返回:
+//   - error: 清理过程中的错误信息
+//
+// 说明:
+//   - 历史日志为同目录下 "name_<切割时间>[_n].ext" 及其 ".gz" 压缩文件，切割时间须以数字开头
+//   - 依次按 MaxAge、MaxBackups、MaxTotalSize 删除，均优先删除最旧的文件
+//   - 压缩中断时残留的 ".gz.tmp" 临时文件始终删除
+func (self *Logger) cleanArchives() error {
+	fileDir, baseName, fileExt := splitLogPath(self.Path)
+	entries, err := os.ReadDir(fileDir)
+	if err != nil {
+		return err
+	}
+
+	prefix := baseName + "_"
+	var archives []os.FileInfo
+	var errs []error
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if rest := name[len(prefix):]; rest == "" || rest[0] < '0' || rest[0] > '9' {
+			continue
+		}
+		// 压缩在 archiveLock 内执行，此时的临时文件均为残留
+		if strings.HasSuffix(name, fileExt+".gz.tmp") {
+			err = os.Remove(filepath.Join(fileDir, name))
+			if err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if !strings.HasSuffix(name, fileExt) && !strings.HasSuffix(name, fileExt+".gz") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		archives = append(archives, info)
+	}
+	// 由新到旧排序
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].ModTime().After(archives[j].ModTime())
+	})
+
+	nowTime := GetTime()
+	var totalSize int64
+	for idx, info := range archives {
+		totalSize += info.Size()
+		expired := self.MaxAge > 0 && nowTime.Sub(info.ModTime()) > self.MaxAge
+		overflow := self.MaxBackups > 0 && idx >= self.MaxBackups
+		oversize := self.MaxTotalSize > 0 && totalSize > self.MaxTotalSize
+		if expired || overflow || oversize {
+			err = os.Remove(filepath.Join(fileDir, info.Name()))
+			if err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
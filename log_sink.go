@@ -0,0 +1,97 @@
+package goi
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Sink 日志附加输出
+type Sink struct {
+	Name    string    // 输出名称
+	Writer  io.Writer // 输出对象
+	Level   Level     // 最低日志等级，为空时与日志器一致
+	Encoder Encoder   // 日志编码器，为空时使用 TextEncoder
+	lock    sync.Mutex
+}
+
+// NewStdoutSink 创建标准输出
+//
+// 参数:
+//   - level Level: 最低日志等级
+//
+// 返回:
+//   - *Sink: 日志输出
+func NewStdoutSink(level Level) *Sink {
+	return &Sink{Name: "stdout", Writer: os.Stdout, Level: level}
+}
+
+// NewStderrSink 创建标准错误输出
+//
+// 参数:
+//   - level Level: 最低日志等级
+//
+// 返回:
+//   - *Sink: 日志输出
+func NewStderrSink(level Level) *Sink {
+	return &Sink{Name: "stderr", Writer: os.Stderr, Level: level}
+}
+
+// NewWriterSink 创建任意 io.Writer 输出
+//
+// 参数:
+//   - name string: 输出名称
+//   - writer io.Writer: 输出对象
+//   - level Level: 最低日志等级
+//   - encoder Encoder: 日志编码器
+//
+// 返回:
+//   - *Sink: 日志输出
+func NewWriterSink(name string, writer io.Writer, level Level, encoder Encoder) *Sink {
+	return &Sink{Name: name, Writer: writer, Level: level, Encoder: encoder}
+}
+
+// NewFileSink 创建文件输出
+//
+// 参数:
+//   - path string: 文件路径，不存在时创建，存在时追加写入
+//   - level Level: 最低日志等级
+//   - encoder Encoder: 日志编码器
+//
+// 返回:
+//   - *Sink: 日志输出
+//   - error: 打开文件的错误信息
+//
+// 说明:
+//   - 文件输出不参与日志切割，需要切割时应使用独立的 Logger
+func NewFileSink(path string, level Level, encoder Encoder) (*Sink, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Sink{Name: path, Writer: file, Level: level, Encoder: encoder}, nil
+}
+
+// write 写入日志记录
+//
+// 参数:
+//   - entry *Entry: 日志记录
+func (self *Sink) write(entry *Entry) {
+	if self.Writer == nil || (self.Level != "" && entry.Level.Compare(self.Level) < 0) {
+		return
+	}
+	encoder := self.Encoder
+	if encoder == nil {
+		encoder = TextEncoder{}
+	}
+	line := append(encoder.Encode(entry), '\n')
+
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	_, _ = self.Writer.Write(line)
+}
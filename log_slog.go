@@ -0,0 +1,146 @@
+package goi
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"slices"
+)
+
+// slogHandler 将 log/slog 日志写入 Logger 的 slog.Handler 实现
+type slogHandler struct {
+	logger *Logger // 日志器
+	fields []Field // WithAttrs 附加的字段
+	group  string  // WithGroup 分组前缀
+}
+
+// Handler 获取写入当前日志器的 slog.Handler
+//
+// 返回:
+//   - slog.Handler: 日志处理器，日志使用当前日志器的编码器、文件与切割
+//
+// 说明:
+//   - 上下文中存在请求 ID（RequestIDKey）时自动记录
+//   - slog 级别映射: Debug -> DEBUG, Info -> INFO, Warn -> WARNING, Error -> ERROR, Error+4 及以上 -> CRITICAL
+func (self *Logger) Handler() slog.Handler {
+	return &slogHandler{logger: self}
+}
+
+// Slog 获取写入当前日志器的 slog.Logger
+//
+// 返回:
+//   - *slog.Logger: slog 日志器
+func (self *Logger) Slog() *slog.Logger {
+	return slog.New(self.Handler())
+}
+
+// Enabled 检查 slog 级别是否输出
+func (handler *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.logger.enabled(slogLevel(level))
+}
+
+// Handle 输出 slog 日志记录
+func (handler *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := slices.Clip(handler.fields)
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendAttr(fields, handler.group, attr)
+		return true
+	})
+
+	logger := handler.logger
+	if logger.requestID == "" {
+		if requestID, ok := RequestIDKey.Value(ctx); ok {
+			logger = logger.WithRequestID(requestID)
+		}
+	}
+
+	entry := logger.newEntry(slogLevel(record.Level), []any{record.Message})
+	entry.Fields = append(slices.Clip(entry.Fields), fields...)
+	if !record.Time.IsZero() {
+		entry.Time = record.Time.In(GetLocation())
+	}
+	if logger.root().Caller && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		entry.Caller = formatCaller(frame)
+	}
+	logger.root().output(entry)
+	return nil
+}
+
+// WithAttrs 派生附加字段的处理器
+func (handler *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := slices.Clip(handler.fields)
+	for _, attr := range attrs {
+		fields = appendAttr(fields, handler.group, attr)
+	}
+	return &slogHandler{logger: handler.logger, fields: fields, group: handler.group}
+}
+
+// WithGroup 派生分组的处理器，后续字段键以 "group." 为前缀
+func (handler *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+	return &slogHandler{logger: handler.logger, fields: handler.fields, group: handler.group + name + "."}
+}
+
+// slogLevel 将 slog 级别映射为日志级别
+//
+// 参数:
+//   - level slog.Level: slog 级别
+//
+// 返回:
+//   - Level: 日志级别
+func slogLevel(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return Debug
+	case level < slog.LevelWarn:
+		return INFO
+	case level < slog.LevelError:
+		return WARNING
+	case level < slog.LevelError+4:
+		return ERROR
+	default:
+		return CRITICAL
+	}
+}
+
+// slogAttr 检查参数是否为 slog.Attr
+//
+// 参数:
+//   - arg any: 参数
+//
+// 返回:
+//   - slog.Attr: slog 字段
+//   - bool: 是否为 slog.Attr
+func slogAttr(arg any) (slog.Attr, bool) {
+	attr, ok := arg.(slog.Attr)
+	return attr, ok
+}
+
+// appendAttr 将 slog 字段展开追加为日志字段
+//
+// 参数:
+//   - fields []Field: 字段列表
+//   - prefix string: 键前缀
+//   - attr slog.Attr: slog 字段，分组字段展开为 "group.key"
+//
+// 返回:
+//   - []Field: 追加后的字段列表
+func appendAttr(fields []Field, prefix string, attr slog.Attr) []Field {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, groupAttr := range value.Group() {
+			fields = appendAttr(fields, prefix, groupAttr)
+		}
+		return fields
+	}
+	if attr.Key == "" {
+		return fields
+	}
+	return append(fields, Field{Key: prefix + attr.Key, Value: value.Any()})
+}
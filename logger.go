@@ -1,12 +1,17 @@
 package goi
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -39,10 +44,18 @@ func NewLogger(path string) *Logger {
 		Logger:          nil,
 		SplitSize:       1024 * 1024 * 10, // 切割大小
 		SplitTime:       "2006-01-02",     // 切割日期，每天
+		Compress:        false,            // 压缩历史日志
+		MaxBackups:      0,                // 历史日志数量上限
+		MaxAge:          0,                // 历史日志保留时间
+		MaxTotalSize:    0,                // 历史日志总大小上限
 		CreateTime:      GetTime(),
-		PrintFunc:       nil, // 自定义日志输出格式
-		GetFileFunc:     nil, // 创建文件对象方法
-		SplitLoggerFunc: nil, // 自定义日志切割：符合切割条件时，传入日志对象，返回新的文件对象
+		Encoder:         nil,   // 日志编码器，默认文本格式
+		Caller:          false, // 是否记录调用位置
+		Level:           "",    // 最低日志等级
+		Sinks:           nil,   // 附加输出
+		PrintFunc:       nil,   // 自定义日志输出格式
+		GetFileFunc:     nil,   // 创建文件对象方法
+		SplitLoggerFunc: nil,   // 自定义日志切割：符合切割条件时，传入日志对象，返回新的文件对象
 	}
 	logger.File, err = logger.GetFile()
 	if err != nil {
@@ -57,13 +70,48 @@ type Level string
 
 // 日志等级
 const (
-	meta    Level = "" // 框架日志
-	Debug   Level = "DEBUG"
-	INFO    Level = "INFO"
-	WARNING Level = "WARNING"
-	ERROR   Level = "ERROR"
+	meta     Level = "" // 框架日志
+	Debug    Level = "DEBUG"
+	INFO     Level = "INFO"
+	WARNING  Level = "WARNING"
+	ERROR    Level = "ERROR"
+	CRITICAL Level = "CRITICAL"
 )
 
+// rank 日志等级排序值
+//
+// 返回:
+//   - int: 排序值，DEBUG < INFO < WARNING < ERROR < CRITICAL < 框架日志，未知等级按 INFO 处理
+func (level Level) rank() int {
+	switch level {
+	case Debug:
+		return 0
+	case INFO:
+		return 1
+	case WARNING:
+		return 2
+	case ERROR:
+		return 3
+	case CRITICAL:
+		return 4
+	case meta:
+		return 5
+	default:
+		return 1
+	}
+}
+
+// Compare 比较日志等级
+//
+// 参数:
+//   - other Level: 另一个日志等级
+//
+// 返回:
+//   - int: level 低于 other 返回 -1，相同返回 0，高于返回 1
+func (level Level) Compare(other Level) int {
+	return cmp.Compare(level.rank(), other.rank())
+}
+
 // Logger 日志管理器
 type Logger struct {
 	Name          string        // 日志名称
@@ -75,6 +123,22 @@ type Logger struct {
 	SplitInterval time.Duration // 日志切割检查间隔
 	CreateTime    time.Time     // 日志文件创建时间，每次日志切割时需要重置
 	lock          sync.Mutex    // 互斥锁
+	base          *Logger       // 派生日志器的源日志器
+	requestID     string        // 派生日志器绑定的请求 ID
+	fields        []Field       // 派生日志器附加的字段
+	// 切割策略
+	Compress     bool           // 是否 gzip 压缩切割后的日志文件
+	MaxBackups   int            // 最多保留的历史日志文件数量，0 表示不限制
+	MaxAge       time.Duration  // 历史日志文件最长保留时间，例如 7 * 24 * time.Hour，0 表示不限制
+	MaxTotalSize int64          // 历史日志文件总大小上限，超出时优先删除最旧的文件，0 表示不限制
+	archiveLock  sync.Mutex     // 压缩与清理互斥锁
+	archiveWG    sync.WaitGroup // 后台压缩与清理等待组
+	// 结构化日志
+	Encoder Encoder // 日志编码器，默认 TextEncoder，可选 JSONEncoder、LogfmtEncoder
+	Caller  bool    // 是否记录调用位置
+	// 输出
+	Level Level   // 最低日志等级，为空时 Debug 模式为 DEBUG，否则为 INFO
+	Sinks []*Sink // 附加输出，按顺序写入；设置后 Debug 模式不再镜像输出到控制台
 	// 自定义方法
 	PrintFunc       func(logger *Logger, level Level, logs ...any) // 自定义日志输出格式
 	GetFileFunc     func(filePath string) (*os.File, error)        // 创建文件对象方法
@@ -150,6 +214,23 @@ func (self *Logger) Error(logs ...any) {
 	self.Log(ERROR, logs...)
 }
 
+// CriticalF 记录严重错误级别日志
+//
+// 参数:
+//   - format string: 日志格式
+//   - logs ...any: 日志内容
+func (self *Logger) CriticalF(format string, logs ...any) {
+	self.Critical(fmt.Sprintf(format, logs...))
+}
+
+// Critical 记录严重错误级别日志
+//
+// 参数:
+//   - logs ...any: 日志内容
+func (self *Logger) Critical(logs ...any) {
+	self.Log(CRITICAL, logs...)
+}
+
 // LogF 记录指定级别的日志
 //
 // 参数:
@@ -160,30 +241,152 @@ func (self *Logger) LogF(level Level, format string, logs ...any) {
 	self.Log(level, fmt.Sprintf(format, logs...))
 }
 
+// With 派生附加字段的日志器
+//
+// 参数:
+//   - fields ...any: Field、slog.Attr 或交替出现的键与值，例如 With("user_id", 1, "role", "admin")
+//
+// 返回:
+//   - *Logger: 派生日志器，输出的每行日志都包含附加字段，写入源日志器
+func (self *Logger) With(fields ...any) *Logger {
+	if self == nil || len(fields) == 0 {
+		return self
+	}
+	logger := self.derive()
+	logger.fields = append(slices.Clip(self.fields), fieldsFromArgs(fields)...)
+	return logger
+}
+
+// WithRequestID 派生绑定请求 ID 的日志器
+//
+// 参数:
+//   - requestID string: 请求 ID
+//
+// 返回:
+//   - *Logger: 派生日志器，输出的每行日志都包含请求 ID，写入源日志器
+func (self *Logger) WithRequestID(requestID string) *Logger {
+	if self == nil || requestID == "" {
+		return self
+	}
+	logger := self.derive()
+	logger.requestID = requestID
+	return logger
+}
+
+// derive 复制派生日志器
+//
+// 返回:
+//   - *Logger: 派生日志器，继承请求 ID 与附加字段
+func (self *Logger) derive() *Logger {
+	root := self.root()
+	return &Logger{
+		Name:       root.Name,
+		Path:       root.Path,
+		CreateTime: root.CreateTime,
+		base:       root,
+		requestID:  self.requestID,
+		fields:     self.fields,
+	}
+}
+
+// root 获取源日志器
+//
+// 返回:
+//   - *Logger: 派生日志器返回其源日志器，否则返回自身
+func (self *Logger) root() *Logger {
+	if self.base != nil {
+		return self.base
+	}
+	return self
+}
+
 // Log 记录指定级别的日志
 //
 // 参数:
 //   - level Level: 日志级别
 //   - logs ...any: 日志内容
 func (self *Logger) Log(level Level, logs ...any) {
-	// Debug 模式下所有日志都输出到控制台
-	if Settings.Debug == true {
+	root := self.root()
+	if !root.enabled(level) {
+		return
+	}
+	entry := self.newEntry(level, logs)
+	if root.Caller {
+		entry.Caller = caller()
+	}
+	root.output(entry)
+}
+
+// enabled 检查日志级别是否输出
+//
+// 参数:
+//   - level Level: 日志级别
+//
+// 返回:
+//   - bool: 是否不低于最低日志等级
+func (self *Logger) enabled(level Level) bool {
+	minLevel := self.Level
+	if minLevel == "" {
+		minLevel = INFO
+		if Settings.Debug == true {
+			minLevel = Debug
+		}
+	}
+	return level.Compare(minLevel) >= 0
+}
+
+// newEntry 创建日志记录
+//
+// 参数:
+//   - level Level: 日志级别
+//   - logs []any: 日志内容
+//
+// 返回:
+//   - *Entry: 日志记录
+func (self *Logger) newEntry(level Level, logs []any) *Entry {
+	message := fmt.Sprintln(logs...)
+	return &Entry{
+		Time:      GetTime(),
+		Level:     level,
+		Name:      self.root().Name,
+		RequestID: self.requestID,
+		Message:   message[:len(message)-1],
+		Fields:    self.fields,
+		logs:      logs,
+	}
+}
+
+// output 输出日志记录
+//
+// 参数:
+//   - entry *Entry: 日志记录
+func (self *Logger) output(entry *Entry) {
+	if !self.enabled(entry.Level) {
+		return
+	}
+
+	// 未设置附加输出时，Debug 模式下日志同时输出到控制台
+	if len(self.Sinks) == 0 && Settings.Debug == true {
 		// 初始化控制台日志
-		consoleLogger = getConsoleLogger()
-		consoleLogger.lock.Lock()
-		consoleLogger.Print(consoleLogger, level, logs...)
-		consoleLogger.lock.Unlock()
-	} else {
+		console := getConsoleLogger()
+		console.lock.Lock()
+		console.print(entry)
+		console.lock.Unlock()
+	} else if Settings.Debug == false {
+		consoleLock.Lock()
 		consoleLogger = nil
-		// Debug 模式关闭时，跳过 Debug 级别日志
-		if level == Debug {
-			return
-		}
+		consoleLock.Unlock()
 	}
 
-	self.lock.Lock()
-	self.Print(self, level, logs...)
-	self.lock.Unlock()
+	// 仅使用附加输出时可不设置日志文件
+	if self.Logger != nil || len(self.Sinks) == 0 {
+		self.lock.Lock()
+		self.print(entry)
+		self.lock.Unlock()
+	}
+	for _, sink := range self.Sinks {
+		sink.write(entry)
+	}
 }
 
 // Print 默认日志打印格式化函数
@@ -193,6 +396,17 @@ func (self *Logger) Log(level Level, logs ...any) {
 //   - level Level: 日志级别
 //   - logs ...any: 日志内容
 func (self *Logger) Print(logger *Logger, level Level, logs ...any) {
+	logger.print(self.newEntry(level, logs))
+}
+
+// print 按编码器输出日志记录
+//
+// 参数:
+//   - entry *Entry: 日志记录
+//
+// 说明:
+//   - 设置 PrintFunc 时，请求 ID 以 "[request_id]" 前缀、附加字段以 "key=value" 追加到日志内容后交给 PrintFunc
+func (self *Logger) print(entry *Entry) {
 	if self.Logger == nil {
 		invalidObjectMsg := i18n.T("log.invalid_object")
 		panic(invalidObjectMsg)
@@ -200,16 +414,63 @@ func (self *Logger) Print(logger *Logger, level Level, logs ...any) {
 
 	// 自定义日志输出
 	if self.PrintFunc != nil {
-		self.PrintFunc(self, level, logs...)
+		logs := make([]any, 0, len(entry.logs)+len(entry.Fields)+1)
+		if entry.RequestID != "" {
+			logs = append(logs, fmt.Sprintf("[%v]", entry.RequestID))
+		}
+		if entry.logs != nil {
+			logs = append(logs, entry.logs...)
+		} else {
+			logs = append(logs, entry.Message)
+		}
+		for _, field := range entry.Fields {
+			var buf bytes.Buffer
+			appendLogfmt(&buf, field.Key, field.Value)
+			logs = append(logs, buf.String())
+		}
+		self.PrintFunc(self, entry.Level, logs...)
 		return
 	}
 
-	timeStr := fmt.Sprintf("[%v]", GetTime().Format(time.DateTime))
-	if level != "" {
-		timeStr += fmt.Sprintf(" %v", level)
+	encoder := self.Encoder
+	if encoder == nil {
+		encoder = TextEncoder{}
+	}
+	self.Logger.Print(string(encoder.Encode(entry)))
+}
+
+// caller 获取日志器外部的调用位置
+//
+// 返回:
+//   - string: 调用位置，格式 "file:line"
+func caller() string {
+	var pcs [16]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, loggerFuncPrefix) {
+			return formatCaller(frame)
+		}
+		if !more {
+			return ""
+		}
 	}
-	logs = append([]any{timeStr}, logs...)
-	logger.Logger.Println(logs...)
+}
+
+// loggerFuncPrefix 日志器方法的函数名前缀，获取调用位置时跳过
+const loggerFuncPrefix = "github.com/NeverStopDreamingWang/goi/v2.(*Logger)."
+
+// formatCaller 格式化调用位置
+//
+// 参数:
+//   - frame runtime.Frame: 调用帧
+//
+// 返回:
+//   - string: 调用位置，格式 "dir/file:line"
+func formatCaller(frame runtime.Frame) string {
+	dir, file := filepath.Split(frame.File)
+	return fmt.Sprintf("%v:%v", filepath.Join(filepath.Base(dir), file), frame.Line)
 }
 
 // CheckSplit 检查是否需要进行日志切割
@@ -237,8 +498,7 @@ func (self *Logger) CheckSplit() error {
 				"name": self.Name,
 				"err":  err,
 			})
-			panic(splitLogStatErrorMsg)
-			return nil
+			return errors.New(splitLogStatErrorMsg)
 		}
 		fileSize := fileInfo.Size()
 		if self.SplitSize <= fileSize {
@@ -253,6 +513,11 @@ func (self *Logger) CheckSplit() error {
 	defer self.lock.Unlock()
 	file, err := self.SplitLogger()
 	if err != nil {
+		// 切割失败时重新打开原日志文件，继续写入
+		if file, reopenErr := self.GetFile(); reopenErr == nil {
+			self.File = file
+			self.Logger.SetOutput(self.File)
+		}
 		return err
 	}
 	if file != nil {
@@ -282,29 +547,12 @@ func (self *Logger) SplitLogger() (*os.File, error) {
 		return self.SplitLoggerFunc(self)
 	}
 
-	var (
-		fileName string
-		baseName string
-		fileExt  string
-		fileDir  string
-		err      error
-	)
-
-	fileName = filepath.Base(self.Path)
-	fileDir = filepath.Dir(self.Path)
-	for i := len(fileName) - 1; i >= 0 && !os.IsPathSeparator(fileName[i]); i-- {
-		if fileName[i] == '.' {
-			baseName = fileName[:i]
-			fileExt = fileName[i:]
-			break
-		}
-	}
-	// 自动加 _n
+	var err error
+	fileDir, baseName, fileExt := splitLogPath(self.Path)
+	// 自动加 _n，已压缩的同名文件同样视为已存在
 	oldFilePath := filepath.Join(fileDir, fmt.Sprintf("%v_%v%v", baseName, self.CreateTime.Format(self.SplitTime), fileExt))
-	_, err = os.Stat(oldFilePath)
-	for idx := 1; err == nil || os.IsNotExist(err) == false; idx++ {
+	for idx := 1; logFileExists(oldFilePath) || logFileExists(oldFilePath+".gz"); idx++ {
 		oldFilePath = filepath.Join(fileDir, fmt.Sprintf("%v_%v_%v%v", baseName, self.CreateTime.Format(self.SplitTime), idx, fileExt))
-		_, err = os.Stat(oldFilePath)
 	}
 	err = os.Rename(self.Path, oldFilePath)
 	if err != nil {
@@ -315,7 +563,16 @@ func (self *Logger) SplitLogger() (*os.File, error) {
 		return nil, errors.New(splitLogReNameErrorMsg)
 	}
 	// 初始化新的文件对象
-	return self.GetFile()
+	file, err := self.GetFile()
+	if err != nil {
+		return nil, err
+	}
+	// 后台压缩与清理历史日志
+	if self.Compress || self.MaxBackups > 0 || self.MaxAge > 0 || self.MaxTotalSize > 0 {
+		self.archiveWG.Add(1)
+		go self.archive(oldFilePath)
+	}
+	return file, nil
 }
 
 // GetFile 默认文件创建函数
@@ -355,6 +612,25 @@ func (self *Logger) GetFile() (*os.File, error) {
 	return file, nil
 }
 
+// Close 等待后台压缩与清理完成后关闭日志文件
+//
+// 返回:
+//   - error: 关闭文件时的错误信息
+//
+// 说明:
+//   - 派生日志器关闭其源日志器
+//   - 通过 RegisterOnStartup 注册的日志器在关闭服务的后台任务阶段同样会等待压缩与清理完成
+func (self *Logger) Close() error {
+	root := self.root()
+	root.archiveWG.Wait()
+	root.lock.Lock()
+	defer root.lock.Unlock()
+	if root.File == nil {
+		return nil
+	}
+	return root.File.Close()
+}
+
 // StartupName 启动任务名称
 //
 // 返回:
@@ -377,21 +653,28 @@ func (self *Logger) OnStartup(ctx context.Context, wg *sync.WaitGroup) {
 	for {
 		select {
 		case <-ctx.Done():
+			// 等待切割后的压缩与清理完成，避免退出后残留未压缩的日志与临时文件
+			self.archiveWG.Wait()
 			return
 		case <-ticker.C:
+			// 切割失败时记录错误，下次检查时重试
 			err := self.CheckSplit()
 			if err != nil {
-				consoleLogger.Error(err)
-				panic(err)
+				self.Error(err)
 			}
 		}
 	}
 }
 
-var consoleLogger *Logger
+var (
+	consoleLogger *Logger
+	consoleLock   sync.Mutex // 保护 consoleLogger，多个 goroutine 同时输出日志时使用
+)
 
 // 默认日志
 func getConsoleLogger() *Logger {
+	consoleLock.Lock()
+	defer consoleLock.Unlock()
 	if consoleLogger != nil {
 		return consoleLogger
 	}
@@ -0,0 +1,190 @@
+package goi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newBufferLogger 创建输出到内存的日志器
+func newBufferLogger(encoder Encoder) (*Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	logger := &Logger{Name: "test", Logger: log.New(buf, "", 0), Encoder: encoder}
+	return logger, buf
+}
+
+// TestLoggerJSON 验证 JSON 编码的标准字段与附加字段
+func TestLoggerJSON(t *testing.T) {
+	prev := Settings.Debug
+	Settings.Debug = false
+	defer func() { Settings.Debug = prev }()
+
+	logger, buf := newBufferLogger(JSONEncoder{})
+	logger.Caller = true
+	logger.WithRequestID("req-1").With("user_id", 7, "err", errors.New("boom")).Info("login", "ok")
+	logger.Debug("skipped")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("invalid json %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"level":      "INFO",
+		"logger":     "test",
+		"request_id": "req-1",
+		"msg":        "login ok",
+		"user_id":    float64(7),
+		"err":        "boom",
+	}
+	for key, value := range want {
+		if line[key] != value {
+			t.Errorf("%v = %v, want %v", key, line[key], value)
+		}
+	}
+	if caller, _ := line["caller"].(string); !strings.Contains(caller, "logger_test.go:") {
+		t.Errorf("caller = %q", caller)
+	}
+}
+
+// TestLoggerLogfmt 验证 logfmt 编码的转义
+func TestLoggerLogfmt(t *testing.T) {
+	prev := Settings.Debug
+	Settings.Debug = false
+	defer func() { Settings.Debug = prev }()
+
+	logger, buf := newBufferLogger(LogfmtEncoder{})
+	logger.With("path", "/a b", "empty", "").Warning("slow request")
+
+	got := buf.String()
+	for _, want := range []string{`level=WARNING`, `logger=test`, `msg="slow request"`, `path="/a b"`, `empty=""`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q missing %q", got, want)
+		}
+	}
+}
+
+// TestLoggerSlog 验证 slog 日志写入日志器
+func TestLoggerSlog(t *testing.T) {
+	prev := Settings.Debug
+	Settings.Debug = false
+	defer func() { Settings.Debug = prev }()
+
+	logger, buf := newBufferLogger(LogfmtEncoder{})
+	ctx := RequestIDKey.WithValue(context.Background(), "req-2")
+	slogger := logger.Slog().With("component", "db").WithGroup("query")
+	slogger.InfoContext(ctx, "executed", "rows", 3)
+	slogger.DebugContext(ctx, "skipped")
+
+	got := buf.String()
+	for _, want := range []string{`level=INFO`, `request_id=req-2`, `msg=executed`, `component=db`, `query.rows=3`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q missing %q", got, want)
+		}
+	}
+	if strings.Contains(got, "skipped") {
+		t.Errorf("debug record written: %q", got)
+	}
+}
+
+// TestLoggerLevelAndSinks 验证最低日志等级与附加输出的等级过滤
+func TestLoggerLevelAndSinks(t *testing.T) {
+	prev := Settings.Debug
+	Settings.Debug = true
+	defer func() { Settings.Debug = prev }()
+
+	var all, errs bytes.Buffer
+	logger := &Logger{
+		Name:  "sinks",
+		Level: INFO,
+		Sinks: []*Sink{
+			NewWriterSink("all", &all, "", LogfmtEncoder{}),
+			NewWriterSink("errors", &errs, ERROR, nil),
+		},
+	}
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warning("warning")
+	logger.Critical("critical")
+
+	if got := strings.Count(all.String(), "\n"); got != 3 || strings.Contains(all.String(), "debug") {
+		t.Errorf("all sink = %q", all.String())
+	}
+	if got := errs.String(); strings.Count(got, "\n") != 1 || !strings.Contains(got, "CRITICAL critical") {
+		t.Errorf("errors sink = %q", got)
+	}
+	if INFO.Compare(WARNING) >= 0 || CRITICAL.Compare(ERROR) <= 0 || Debug.Compare(Debug) != 0 {
+		t.Error("unexpected level order")
+	}
+}
+
+// TestLoggerRotate 验证切割后压缩、按数量保留历史日志并清理残留的临时文件
+func TestLoggerRotate(t *testing.T) {
+	prev := Settings.Debug
+	Settings.Debug = false
+	defer func() { Settings.Debug = prev }()
+
+	dir := t.TempDir()
+	logger := NewLogger(filepath.Join(dir, "server.log"))
+	defer logger.File.Close()
+	logger.SplitSize = 1
+	logger.Compress = true
+	logger.MaxBackups = 2
+	// 上次压缩中断残留的临时文件
+	stale := filepath.Join(dir, "server_20000101.log.gz.tmp")
+	if err := os.WriteFile(stale, []byte("partial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		logger.Info("line", i)
+		if err := logger.CheckSplit(); err != nil {
+			t.Fatal(err)
+		}
+		logger.archiveWG.Wait()
+	}
+	// Close 等待最后一次切割的压缩与清理完成
+	logger.Info("line", 4)
+	if err := logger.CheckSplit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	archives, _ := filepath.Glob(filepath.Join(dir, "server_*"))
+	if len(archives) != 2 {
+		t.Fatalf("archives = %v, want 2", archives)
+	}
+	for _, archive := range archives {
+		if !strings.HasSuffix(archive, ".log.gz") {
+			t.Errorf("archive %q not compressed", archive)
+		}
+	}
+	// 保留最新的两个历史日志
+	var contents []string
+	for _, archive := range archives {
+		file, err := os.Open(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(reader)
+		file.Close()
+		contents = append(contents, string(content))
+	}
+	all := strings.Join(contents, "")
+	if !strings.Contains(all, "line 3") || !strings.Contains(all, "line 4") || strings.Contains(all, "line 2") {
+		t.Errorf("archives content = %q", contents)
+	}
+}
@@ -1,6 +1,8 @@
 package goi
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"runtime/debug"
@@ -99,17 +101,23 @@ func wrapMiddleware(middleware Middleware, getResponse getResponseFunc) getRespo
 
 // convertExceptionToResponse 将 panic 的错误转换为 Response（最常用映射）。
 // - 已知 HttpError：按 Status 返回
+// - context.DeadlineExceeded：504 Gateway Timeout
 // - 其它：统一 500 Internal Server Error（生产可扩展为 Debug 模式返回详细页）
 func convertExceptionToResponse(request *Request, exc interface{}) *Response {
+	log := request.Log()
 	switch err := exc.(type) {
 	case error:
-		Log.Error(fmt.Sprintf("%v", err))
-		Log.Error(string(debug.Stack()))
+		log.Error(fmt.Sprintf("%v", err))
+		log.Error(string(debug.Stack()))
+		// 上游调用超出请求上下文期限：504
+		if errors.Is(err, context.DeadlineExceeded) {
+			return &Response{Status: http.StatusGatewayTimeout, Data: err.Error()}
+		}
 		// 可补充对常见错误文本的分类映射；当前默认 500
 		return &Response{Status: http.StatusInternalServerError, Data: err.Error()}
 	default:
-		Log.Error(fmt.Sprintf("%v", err))
-		Log.Error(string(debug.Stack()))
+		log.Error(fmt.Sprintf("%v", err))
+		log.Error(string(debug.Stack()))
 		return &Response{Status: http.StatusInternalServerError, Data: "Internal Server Error"}
 	}
 }
@@ -0,0 +1,299 @@
+package cache
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NeverStopDreamingWang/goi/v2"
+)
+
+// Default 返回带默认配置的响应缓存中间件实例
+//
+// 默认使用 goi.Cache 存储，仅缓存 GET/HEAD 请求的 200 响应
+func Default() CacheMiddleware {
+	return CacheMiddleware{
+		// 使用全局缓存 goi.Cache
+		Cache: nil,
+
+		// 缓存键前缀，便于与业务缓存区分和按前缀失效
+		Prefix: "http:",
+
+		// 响应未指定 max-age/s-maxage 时缓存 60 秒
+		DefaultTTL: 60,
+
+		// 按内容协商相关请求头区分缓存
+		VaryHeaders: []string{"Accept", "Accept-Encoding"},
+
+		// 仅缓存 200 响应
+		Statuses: []int{http.StatusOK},
+	}
+}
+
+// CacheMiddleware 基于 goi.Cache 的 HTTP 响应缓存中间件,实现 goi.Middleware 接口
+//
+// 主要功能:
+//   - 缓存键: 由 Prefix、路径、排序后的查询参数、请求方法与 VaryHeaders 的值组成
+//   - 请求 Cache-Control: no-store 不读不写缓存，no-cache 或 max-age=0 跳过读取但写入新响应，max-age=N 仅返回不超过 N 秒的缓存
+//   - 响应 Cache-Control: private、no-store、no-cache 不缓存，s-maxage/max-age 作为缓存时间
+//   - 命中时设置 Age 响应头
+//   - 不缓存带 Set-Cookie 的响应、Vary 包含 VaryHeaders 之外请求头的响应，以及未声明 public 的带 Authorization 请求
+//
+// 注意:
+//   - 仅缓存 string、[]byte 与可 JSON 序列化的响应数据，文件、流与 RawHandler 响应直接透传
+//   - 外层中间件的 ProcessResponse 在命中缓存时仍会执行，内层中间件与视图不会执行
+type CacheMiddleware struct {
+	// 缓存后端，为 nil 时使用 goi.Cache
+	Cache goi.CacheBackend
+
+	// 缓存键前缀
+	Prefix string
+
+	// 响应未指定 s-maxage/max-age 时的缓存时间（单位：秒），0 表示不缓存此类响应
+	DefaultTTL int
+
+	// 参与缓存键计算的请求头
+	VaryHeaders []string
+
+	// 可缓存的响应状态码
+	Statuses []int
+}
+
+// entry 缓存的响应
+//
+// 字段:
+//   - Status int: HTTP状态码
+//   - Header http.Header: 响应头
+//   - Body []byte: 响应数据
+//   - Created time.Time: 缓存时间，用于计算 Age
+type entry struct {
+	Status  int
+	Header  http.Header
+	Body    []byte
+	Created time.Time
+}
+
+// state 单次请求的缓存状态
+//
+// 字段:
+//   - key string: 缓存键
+//   - hit bool: 是否命中缓存
+type state struct {
+	key string
+	hit bool
+}
+
+var stateKey = goi.NewContextKey[*state]("cache.state")
+
+// backend 获取缓存后端
+func (self CacheMiddleware) backend() goi.CacheBackend {
+	if self.Cache != nil {
+		return self.Cache
+	}
+	return goi.Cache
+}
+
+// Key 计算请求的缓存键
+//
+// 参数:
+//   - request *http.Request: HTTP 请求对象
+//
+// 返回:
+//   - string: 缓存键，HEAD 与 GET 共用缓存
+func (self CacheMiddleware) Key(request *http.Request) string {
+	method := request.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	var builder strings.Builder
+	builder.WriteString(self.Prefix)
+	builder.WriteString(request.URL.Path)
+	if query := request.URL.Query(); len(query) > 0 {
+		builder.WriteString("?")
+		builder.WriteString(query.Encode()) // Encode 按参数名排序
+	}
+	builder.WriteString("|")
+	builder.WriteString(method)
+	for _, name := range self.VaryHeaders {
+		builder.WriteString("|")
+		builder.WriteString(strings.Join(request.Header.Values(name), ","))
+	}
+	return builder.String()
+}
+
+// Invalidate 按路径前缀删除缓存的响应
+//
+// 参数:
+//   - pathPrefix string: 路径前缀，例如 "/users/"，为空时删除全部缓存的响应
+//
+// 返回:
+//   - int: 删除的缓存数量
+//   - error: 删除过程中的错误信息
+func (self CacheMiddleware) Invalidate(pathPrefix string) (int, error) {
+	cache := self.backend()
+	keys, err := cache.Keys(goi.EscapeKeyPattern(self.Prefix+pathPrefix) + "*")
+	if err != nil {
+		return 0, err
+	}
+	for _, key := range keys {
+		err = cache.Del(key)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(keys), nil
+}
+
+// ProcessRequest 请求预处理,命中缓存时直接返回缓存的响应
+func (self CacheMiddleware) ProcessRequest(request *goi.Request) any {
+	if request.Object.Method != http.MethodGet && request.Object.Method != http.MethodHead {
+		return nil
+	}
+	directives := parseCacheControl(request.Object.Header.Get("Cache-Control"))
+	if _, ok := directives["no-store"]; ok {
+		return nil
+	}
+
+	current := &state{key: self.Key(request.Object)}
+	stateKey.Set(request, current)
+	if _, ok := directives["no-cache"]; ok {
+		return nil
+	}
+
+	var cached entry
+	found, err := self.backend().Load(current.key, &cached)
+	if err != nil {
+		request.Log().Warning(err)
+		return nil
+	}
+	if !found {
+		return nil
+	}
+	age := int(goi.GetTime().Sub(cached.Created) / time.Second)
+	if maxAge, ok := directives["max-age"]; ok {
+		seconds, err := strconv.Atoi(maxAge)
+		if err == nil && (seconds == 0 || age > seconds) {
+			return nil
+		}
+	}
+
+	current.hit = true
+	response := goi.Response{Status: cached.Status, Data: cached.Body}
+	for name, values := range cached.Header {
+		response.Header()[name] = values
+	}
+	response.Header().Set("Age", strconv.Itoa(age))
+	return response
+}
+
+// ProcessException 异常处理(本中间件不处理)
+func (self CacheMiddleware) ProcessException(request *goi.Request, exception any) any {
+	return nil
+}
+
+// ProcessResponse 响应后处理,将可缓存的响应写入缓存
+func (self CacheMiddleware) ProcessResponse(request *goi.Request, response *goi.Response) {
+	current, ok := stateKey.Get(request)
+	if !ok || current.hit || !slices.Contains(self.Statuses, response.Status) {
+		return
+	}
+	header := response.Header()
+	if header.Get("Set-Cookie") != "" {
+		return
+	}
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	for _, directive := range []string{"private", "no-store", "no-cache"} {
+		if _, ok := directives[directive]; ok {
+			return
+		}
+	}
+	if _, ok := directives["public"]; !ok && request.Object.Header.Get("Authorization") != "" {
+		return
+	}
+
+	ttl := self.DefaultTTL
+	for _, directive := range []string{"s-maxage", "max-age"} {
+		if value, ok := directives[directive]; ok {
+			ttl, _ = strconv.Atoi(value)
+			break
+		}
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	if response.Data == nil || response.Streaming() {
+		return
+	}
+	body, contentType, err := response.Render(request)
+	if err != nil {
+		return
+	}
+	// Render 协商编码时会追加 Vary: Accept，需在其后检查
+	if !self.varyCovered(header.Values("Vary")) {
+		return
+	}
+	cached := entry{
+		Status:  response.Status,
+		Header:  header.Clone(),
+		Body:    body,
+		Created: goi.GetTime(),
+	}
+	if cached.Header.Get(goi.ContentType) == "" && contentType != "" {
+		cached.Header.Set(goi.ContentType, contentType)
+	}
+	err = self.backend().Set(current.key, cached, ttl)
+	if err != nil {
+		request.Log().Warning(err)
+	}
+}
+
+// varyCovered 检查响应 Vary 中的请求头是否都参与了缓存键计算
+//
+// 参数:
+//   - vary []string: 响应 Vary 头
+//
+// 返回:
+//   - bool: 是否可以缓存
+func (self CacheMiddleware) varyCovered(vary []string) bool {
+	for _, value := range vary {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name == "*" {
+				return false
+			}
+			covered := slices.ContainsFunc(self.VaryHeaders, func(header string) bool {
+				return strings.EqualFold(header, name)
+			})
+			if !covered {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parseCacheControl 解析 Cache-Control 指令
+//
+// 参数:
+//   - value string: Cache-Control 头
+//
+// 返回:
+//   - map[string]string: 指令名(小写)到参数的映射，无参数的指令值为空字符串
+func parseCacheControl(value string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, argument, _ := strings.Cut(part, "=")
+		directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(argument), `"`)
+	}
+	return directives
+}
@@ -0,0 +1,76 @@
+package cache_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/NeverStopDreamingWang/goi/v2"
+	"github.com/NeverStopDreamingWang/goi/v2/middleware/cache"
+)
+
+// TestCacheMiddleware 验证命中、Cache-Control、private 与按前缀失效
+func TestCacheMiddleware(t *testing.T) {
+	engine := goi.NewHTTPServer()
+	engine.Log = goi.NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer engine.Log.File.Close()
+
+	middleware := cache.Default()
+	middleware.Cache = goi.NewMemoryCache()
+	engine.Router.Use(middleware)
+
+	calls := 0
+	engine.Router.Path("articles/<int:id>", "文章", goi.ViewSet{GET: func(request *goi.Request) any {
+		calls++
+		return map[string]int{"calls": calls}
+	}})
+	engine.Router.Path("me", "个人", goi.ViewSet{GET: func(request *goi.Request) any {
+		calls++
+		response := goi.Response{Status: http.StatusOK, Data: "me"}
+		response.Header().Set("Cache-Control", "private")
+		return response
+	}})
+
+	serve := func(target string, cacheControl string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		if cacheControl != "" {
+			request.Header.Set("Cache-Control", cacheControl)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	first := serve("/articles/1?b=2&a=1", "")
+	second := serve("/articles/1?a=1&b=2", "")
+	if calls != 1 || second.Body.String() != first.Body.String() || second.Header().Get("Age") == "" {
+		t.Fatalf("cache miss on normalized query: calls=%d body=%q age=%q", calls, second.Body.String(), second.Header().Get("Age"))
+	}
+	if second.Header().Get(goi.ContentType) != "application/json" {
+		t.Errorf("cached Content-Type = %q", second.Header().Get(goi.ContentType))
+	}
+
+	if serve("/articles/1?a=1&b=2", "no-cache"); calls != 2 {
+		t.Errorf("no-cache served from cache, calls = %d", calls)
+	}
+	if serve("/articles/1?a=1&b=2", "no-store"); calls != 3 {
+		t.Errorf("no-store served from cache, calls = %d", calls)
+	}
+	if recorder := serve("/articles/1?a=1&b=2", ""); recorder.Body.String() != `{"calls":2}` {
+		t.Errorf("no-cache response not stored, body = %q", recorder.Body.String())
+	}
+
+	serve("/me", "")
+	serve("/me", "")
+	if calls != 5 {
+		t.Errorf("private response cached, calls = %d", calls)
+	}
+
+	if n, err := middleware.Invalidate("/articles/"); err != nil || n != 1 {
+		t.Errorf("Invalidate = %d, %v", n, err)
+	}
+	if serve("/articles/1?a=1&b=2", ""); calls != 6 {
+		t.Errorf("invalidated response served, calls = %d", calls)
+	}
+}
@@ -0,0 +1,99 @@
+package compress
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"sync"
+)
+
+// Encoder 压缩编码器
+//
+// 说明:
+//   - NewWriter 返回的写入器实现 Flush() error 时，流式响应调用 Flush 会同时刷新压缩缓冲
+//   - 可通过 RegisterEncoder 注册 br、zstd 等第三方实现
+type Encoder interface {
+	// NewWriter 创建写入 w 的压缩写入器，Close 时写出剩余数据
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+// EncoderFunc 函数形式的压缩编码器
+type EncoderFunc func(w io.Writer) (io.WriteCloser, error)
+
+// NewWriter 实现 Encoder 接口
+func (fn EncoderFunc) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return fn(w)
+}
+
+// GzipEncoder gzip 编码器
+//
+// 字段:
+//   - Level int: 压缩级别，取值同 compress/gzip，0 表示 gzip.DefaultCompression
+type GzipEncoder struct {
+	Level int
+}
+
+// NewWriter 实现 Encoder 接口
+func (encoder GzipEncoder) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	level := encoder.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
+// DeflateEncoder deflate 编码器
+//
+// 字段:
+//   - Level int: 压缩级别，取值同 compress/flate，0 表示 flate.DefaultCompression
+type DeflateEncoder struct {
+	Level int
+}
+
+// NewWriter 实现 Encoder 接口
+func (encoder DeflateEncoder) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	level := encoder.Level
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	return flate.NewWriter(w, level)
+}
+
+var encoders = struct {
+	lock sync.RWMutex
+	dict map[string]Encoder
+}{
+	dict: map[string]Encoder{
+		"gzip":    GzipEncoder{},
+		"deflate": DeflateEncoder{},
+	},
+}
+
+// RegisterEncoder 注册压缩编码器，同名时覆盖
+//
+// 参数:
+//   - encoding string: Content-Encoding 名称，例如 "br"、"zstd"
+//   - encoder Encoder: 压缩编码器
+//
+// 说明:
+//   - 注册后还需将名称加入 CompressMiddleware.Encodings 才会参与协商
+func RegisterEncoder(encoding string, encoder Encoder) {
+	encoders.lock.Lock()
+	defer encoders.lock.Unlock()
+	encoders.dict[encoding] = encoder
+}
+
+// getEncoder 获取已注册的压缩编码器
+//
+// 参数:
+//   - encoding string: Content-Encoding 名称
+//
+// 返回:
+//   - Encoder: 压缩编码器
+//   - bool: 是否已注册
+func getEncoder(encoding string) (Encoder, bool) {
+	encoders.lock.RLock()
+	defer encoders.lock.RUnlock()
+	encoder, ok := encoders.dict[encoding]
+	return encoder, ok
+}
@@ -0,0 +1,347 @@
+package compress
+
+import (
+	"bytes"
+	"embed"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/NeverStopDreamingWang/goi/v2"
+)
+
+// Default 返回带默认配置的压缩中间件实例
+//
+// 默认按 gzip、deflate 顺序协商，仅压缩不小于 1KB 的响应，跳过图片、音视频与压缩包等已压缩类型
+func Default() CompressMiddleware {
+	return CompressMiddleware{
+		// 服务端偏好顺序，注册 br、zstd 编码器后可加入此列表
+		Encodings: []string{"gzip", "deflate"},
+
+		// 小于 1KB 的响应压缩收益有限
+		MinSize: 1024,
+
+		// 已压缩的内容类型(前缀匹配)
+		SkipContentTypes: []string{
+			"image/png", "image/jpeg", "image/gif", "image/webp", "image/avif",
+			"video/", "audio/", "font/woff",
+			"application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+			"application/x-7z-compressed", "application/x-rar-compressed", "application/x-bzip2", "application/x-xz",
+		},
+	}
+}
+
+// CompressMiddleware 根据 Accept-Encoding 压缩响应的中间件,实现 goi.Middleware 接口
+//
+// 主要功能:
+//   - 协商: 按 Accept-Encoding 的 q 值选择编码，q 值相同时按 Encodings 顺序
+//   - string、[]byte 与编码后的结构化响应: 不小于 MinSize 时整体压缩，Content-Length 由框架按压缩后长度重新计算
+//   - RawHandler 与 io.Reader 流式响应: 边写边压缩并移除 Content-Length，Flush 时同时刷新压缩缓冲
+//   - 设置 Vary: Accept-Encoding，并将强 ETag 改为弱 ETag
+//
+// 注意:
+//   - 文件与文件系统响应由 http.ServeContent 处理 Range 请求，不压缩
+//   - 响应已设置 Content-Encoding 或 Cache-Control: no-transform 时不压缩
+type CompressMiddleware struct {
+	// 参与协商的编码，按服务端偏好排序，需已通过 RegisterEncoder 注册
+	Encodings []string
+
+	// 最小压缩大小（单位：字节）。流式响应仅在设置了 Content-Length 时参考
+	MinSize int
+
+	// 不压缩的内容类型，按前缀匹配，例如 "image/png"、"video/"
+	SkipContentTypes []string
+}
+
+// ProcessRequest 请求预处理(本中间件不处理)
+func (self CompressMiddleware) ProcessRequest(request *goi.Request) any {
+	return nil
+}
+
+// ProcessException 异常处理(本中间件不处理)
+func (self CompressMiddleware) ProcessException(request *goi.Request, exception any) any {
+	return nil
+}
+
+// ProcessResponse 响应后处理,协商编码并压缩响应数据
+func (self CompressMiddleware) ProcessResponse(request *goi.Request, response *goi.Response) {
+	header := response.Header()
+	if response.Data == nil || !bodyAllowed(response.Status) || header.Get("Content-Encoding") != "" {
+		return
+	}
+	if strings.Contains(strings.ToLower(header.Get("Cache-Control")), "no-transform") {
+		return
+	}
+	switch response.Data.(type) {
+	case *os.File, http.File, goi.FS, fs.FS, embed.FS:
+		return
+	}
+
+	addVary(header, "Accept-Encoding")
+	encoding, encoder := self.negotiate(request.Object.Header.Get("Accept-Encoding"))
+	if encoder == nil {
+		return
+	}
+
+	if !response.Streaming() {
+		body, defaultContentType, err := response.Render(request)
+		if err != nil {
+			return // 由框架写出时报告编码错误
+		}
+		contentType := header.Get(goi.ContentType)
+		if contentType == "" {
+			contentType = defaultContentType
+		}
+		if len(body) < self.MinSize || self.skipContentType(contentType) {
+			return
+		}
+		compressed, err := compress(encoder, body)
+		if err != nil {
+			return
+		}
+		header.Set(goi.ContentType, contentType)
+		header.Set("Content-Encoding", encoding)
+		header.Del("Content-Length")
+		weakenETag(header)
+		response.Data = compressed
+		return
+	}
+
+	var handler goi.RawHandler
+	switch value := response.Data.(type) {
+	case goi.RawHandler:
+		handler = value
+	case io.Reader:
+		handler = readerHandler(value, response.Status)
+	default:
+		return
+	}
+	response.Data = goi.RawHandler(func(w http.ResponseWriter, r *goi.Request) error {
+		writer := &compressWriter{ResponseWriter: w, middleware: self, encoding: encoding, encoder: encoder}
+		err := handler(writer, r)
+		closeErr := writer.Close()
+		if err != nil {
+			return err
+		}
+		return closeErr
+	})
+}
+
+// negotiate 按 Accept-Encoding 选择编码
+//
+// 参数:
+//   - acceptEncoding string: Accept-Encoding 请求头
+//
+// 返回:
+//   - string: 编码名称
+//   - Encoder: 压缩编码器，没有可用编码时为 nil
+func (self CompressMiddleware) negotiate(acceptEncoding string) (string, Encoder) {
+	if acceptEncoding == "" {
+		return "", nil
+	}
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		weight := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					weight = q
+				}
+			}
+		}
+		weights[name] = weight
+	}
+
+	var bestEncoding string
+	var bestEncoder Encoder
+	bestWeight := 0.0
+	for _, encoding := range self.Encodings {
+		weight, ok := weights[encoding]
+		if !ok {
+			weight, ok = weights["*"]
+		}
+		if !ok || weight <= bestWeight {
+			continue
+		}
+		if encoder, ok := getEncoder(encoding); ok {
+			bestEncoding, bestEncoder, bestWeight = encoding, encoder, weight
+		}
+	}
+	return bestEncoding, bestEncoder
+}
+
+// skipContentType 检查内容类型是否不需要压缩
+//
+// 参数:
+//   - contentType string: Content-Type
+//
+// 返回:
+//   - bool: 是否跳过压缩
+func (self CompressMiddleware) skipContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, prefix := range self.SkipContentTypes {
+		if prefix != "" && strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter 流式压缩写入器，首次写入响应头时决定是否压缩
+type compressWriter struct {
+	http.ResponseWriter
+	middleware  CompressMiddleware
+	encoding    string
+	encoder     Encoder
+	writer      io.WriteCloser // 压缩写入器，为 nil 表示不压缩
+	wroteHeader bool
+}
+
+// WriteHeader 写入响应头，按状态码、内容类型与 Content-Length 决定是否压缩
+func (w *compressWriter) WriteHeader(code int) {
+	if code < http.StatusOK || w.wroteHeader {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.wroteHeader = true
+	header := w.Header()
+	if w.compressible(code) {
+		writer, err := w.encoder.NewWriter(w.ResponseWriter)
+		if err == nil {
+			header.Del("Content-Length")
+			header.Set("Content-Encoding", w.encoding)
+			weakenETag(header)
+			w.writer = writer
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// compressible 检查响应是否需要压缩
+func (w *compressWriter) compressible(code int) bool {
+	header := w.Header()
+	if !bodyAllowed(code) || header.Get("Content-Encoding") != "" || w.middleware.skipContentType(header.Get(goi.ContentType)) {
+		return false
+	}
+	if contentLength, err := strconv.Atoi(header.Get("Content-Length")); err == nil && contentLength < w.middleware.MinSize {
+		return false
+	}
+	return true
+}
+
+// Write 写入响应数据，未设置 Content-Type 时按未压缩数据探测
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get(goi.ContentType) == "" {
+			w.Header().Set(goi.ContentType, http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.writer != nil {
+		return w.writer.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush 刷新压缩缓冲与底层连接
+func (w *compressWriter) Flush() {
+	if flusher, ok := w.writer.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close 写出压缩写入器中剩余的数据
+func (w *compressWriter) Close() error {
+	if w.writer == nil {
+		return nil
+	}
+	return w.writer.Close()
+}
+
+// Unwrap 返回底层 http.ResponseWriter，供 http.ResponseController 使用
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// readerHandler 将 io.Reader 响应转换为 RawHandler，行为与框架写出 io.Reader 一致
+//
+// 参数:
+//   - reader io.Reader: 响应数据
+//   - status int: HTTP状态码
+//
+// 返回:
+//   - goi.RawHandler: 写出函数
+func readerHandler(reader io.Reader, status int) goi.RawHandler {
+	return func(w http.ResponseWriter, r *goi.Request) error {
+		if closer, ok := reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+		if w.Header().Get(goi.ContentType) == "" {
+			w.Header().Set(goi.ContentType, "application/octet-stream")
+		}
+		w.WriteHeader(status)
+		_, err := io.Copy(w, reader)
+		return err
+	}
+}
+
+// compress 整体压缩数据
+//
+// 参数:
+//   - encoder Encoder: 压缩编码器
+//   - data []byte: 原始数据
+//
+// 返回:
+//   - []byte: 压缩后的数据
+//   - error: 压缩过程中的错误信息
+func compress(encoder Encoder, data []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer, err := encoder.NewWriter(&buffer)
+	if err != nil {
+		return nil, err
+	}
+	_, err = writer.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// bodyAllowed 检查状态码是否允许响应体
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// addVary 追加 Vary 响应头，已包含时不重复添加
+func addVary(header http.Header, name string) {
+	for _, value := range header.Values("Vary") {
+		for _, existing := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(existing), name) || strings.TrimSpace(existing) == "*" {
+				return
+			}
+		}
+	}
+	header.Add("Vary", name)
+}
+
+// weakenETag 将强 ETag 改为弱 ETag，压缩后的内容与原内容不再逐字节相同
+func weakenETag(header http.Header) {
+	etag := header.Get("ETag")
+	if etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set("ETag", "W/"+etag)
+	}
+}
@@ -0,0 +1,115 @@
+package compress_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NeverStopDreamingWang/goi/v2"
+	"github.com/NeverStopDreamingWang/goi/v2/middleware/compress"
+)
+
+// nopWriteCloser 测试用的不压缩编码器
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// TestCompressMiddleware 验证协商、阈值、跳过类型、流式压缩与自定义编码器
+func TestCompressMiddleware(t *testing.T) {
+	engine := goi.NewHTTPServer()
+	engine.Log = goi.NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer engine.Log.File.Close()
+
+	middleware := compress.Default()
+	middleware.Encodings = append(middleware.Encodings, "x-test")
+	compress.RegisterEncoder("x-test", compress.EncoderFunc(func(w io.Writer) (io.WriteCloser, error) {
+		return nopWriteCloser{w}, nil
+	}))
+	engine.Router.Use(middleware)
+
+	large := strings.Repeat("goi ", 1024)
+	engine.Router.Path("large", "大响应", goi.ViewSet{GET: func(request *goi.Request) any {
+		return large
+	}})
+	engine.Router.Path("small", "小响应", goi.ViewSet{GET: func(request *goi.Request) any {
+		return "small"
+	}})
+	engine.Router.Path("image", "图片", goi.ViewSet{GET: func(request *goi.Request) any {
+		response := goi.Response{Status: http.StatusOK, Data: []byte(large)}
+		response.Header().Set(goi.ContentType, "image/png")
+		return response
+	}})
+	engine.Router.Path("stream", "流式响应", goi.ViewSet{GET: func(request *goi.Request) any {
+		return goi.Response{Status: http.StatusOK, Data: goi.RawHandler(func(w http.ResponseWriter, r *goi.Request) error {
+			w.Header().Set(goi.ContentType, "text/plain")
+			w.Header().Set("ETag", `"v1"`)
+			for i := 0; i < 3; i++ {
+				_, err := io.WriteString(w, "chunk\n")
+				if err != nil {
+					return err
+				}
+				w.(http.Flusher).Flush()
+			}
+			return nil
+		})}
+	}})
+
+	serve := func(target string, acceptEncoding string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		if acceptEncoding != "" {
+			request.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		return recorder
+	}
+	decode := func(recorder *httptest.ResponseRecorder) string {
+		reader, err := gzip.NewReader(recorder.Body)
+		if err != nil {
+			t.Fatalf("gzip.NewReader: %v", err)
+		}
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("gzip read: %v", err)
+		}
+		return string(body)
+	}
+
+	recorder := serve("/large", "deflate;q=0.5, gzip")
+	if recorder.Header().Get("Content-Encoding") != "gzip" || recorder.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("headers = %v", recorder.Header())
+	}
+	if !strings.HasPrefix(recorder.Header().Get(goi.ContentType), "text/plain") {
+		t.Errorf("Content-Type = %q", recorder.Header().Get(goi.ContentType))
+	}
+	if body := decode(recorder); body != large {
+		t.Errorf("decoded body length = %d", len(body))
+	}
+
+	if recorder = serve("/large", "gzip;q=0, identity"); recorder.Header().Get("Content-Encoding") != "" {
+		t.Errorf("gzip;q=0 compressed with %q", recorder.Header().Get("Content-Encoding"))
+	}
+	if recorder = serve("/large", "x-test"); recorder.Header().Get("Content-Encoding") != "x-test" || recorder.Body.String() != large {
+		t.Errorf("custom encoder: encoding=%q", recorder.Header().Get("Content-Encoding"))
+	}
+	if recorder = serve("/small", "gzip"); recorder.Header().Get("Content-Encoding") != "" || recorder.Body.String() != "small" {
+		t.Errorf("small response compressed: %v", recorder.Header())
+	}
+	if recorder = serve("/image", "gzip"); recorder.Header().Get("Content-Encoding") != "" {
+		t.Errorf("image/png compressed: %v", recorder.Header())
+	}
+
+	recorder = serve("/stream", "gzip")
+	if recorder.Header().Get("Content-Encoding") != "gzip" || recorder.Header().Get("ETag") != `W/"v1"` || !recorder.Flushed {
+		t.Fatalf("stream headers = %v flushed=%v", recorder.Header(), recorder.Flushed)
+	}
+	if body := decode(recorder); body != strings.Repeat("chunk\n", 3) {
+		t.Errorf("stream body = %q", body)
+	}
+}
@@ -0,0 +1,396 @@
+package openapi
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/NeverStopDreamingWang/goi/v2"
+	"github.com/NeverStopDreamingWang/goi/v2/parser"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed ui/*
+var uiFS embed.FS
+
+// placeholderRegex 匹配路由占位符 <type:name>
+var placeholderRegex = regexp.MustCompile(`<([^/<>]+):([^/<>]+)>`)
+
+// Spec 接口描述，通过 Generator.Operation 注册到指定路由的 HTTP 方法
+//
+// 字段:
+//   - Summary string: 摘要，默认使用路由描述
+//   - Description string: 详细描述
+//   - Tags []string: 接口分组，默认使用所在路由组的描述
+//   - Deprecated bool: 是否已弃用
+//   - Query any: 查询参数结构体，字段标签与 goi.Params.ParseParams 一致
+//   - Body any: 请求体参数结构体，字段标签与 goi.Params.ParseParams 一致
+//   - BodyContentType string: 请求体类型，默认 application/json
+//   - Responses map[int]any: 状态码 -> 响应数据示例值，nil 表示无响应体
+type Spec struct {
+	Summary         string
+	Description     string
+	Tags            []string
+	Deprecated      bool
+	Query           any
+	Body            any
+	BodyContentType string
+	Responses       map[int]any
+}
+
+// Generator OpenAPI 文档生成器
+//
+// 遍历 goi.Router.GetRoute() 路由树生成文档，未注册 Spec 的路由仅包含路径参数与默认响应
+//
+// 主机路由组（goi.Router.Host）中的接口以主机描述为默认分组，并通过操作的 servers 指定主机，
+// 同一路径与方法在多个主机中重复时仅记录第一个
+type Generator struct {
+	Info    Info     // API 元数据
+	Servers []Server // 服务地址
+
+	mu       sync.RWMutex
+	specs    map[string]Spec // key: 方法 + " " + 路由名称或完整路由
+	docsPath string          // Register 挂载的文档路由组，生成时跳过
+}
+
+// New 创建 OpenAPI 文档生成器
+//
+// 参数:
+//   - title string: API 标题
+//   - version string: API 版本
+//
+// 返回:
+//   - *Generator: 文档生成器
+func New(title string, version string) *Generator {
+	return &Generator{
+		Info:  Info{Title: title, Version: version},
+		specs: make(map[string]Spec),
+	}
+}
+
+// Operation 注册接口描述
+//
+// 参数:
+//   - route string: 路由名称（通过 Router.Name 设置）或完整路由，如 "/api/user/<int:id>"
+//   - method string: HTTP方法
+//   - spec Spec: 接口描述
+func (generator *Generator) Operation(route string, method string, spec Spec) {
+	generator.mu.Lock()
+	defer generator.mu.Unlock()
+	generator.specs[strings.ToUpper(method)+" "+route] = spec
+}
+
+// getSpec 获取接口描述，路由名称优先于完整路由
+func (generator *Generator) getSpec(route goi.Route, path string, method string) (Spec, bool) {
+	generator.mu.RLock()
+	defer generator.mu.RUnlock()
+	if route.Name != "" {
+		if spec, ok := generator.specs[method+" "+route.Name]; ok {
+			return spec, true
+		}
+	}
+	spec, ok := generator.specs[method+" "+path]
+	return spec, ok
+}
+
+// Build 生成 OpenAPI 文档
+//
+// 参数:
+//   - router *goi.Router: 路由，通常为 engine.Router
+//
+// 返回:
+//   - *OpenAPI: 文档对象
+func (generator *Generator) Build(router *goi.Router) *OpenAPI {
+	builder := newSchemaBuilder()
+	document := &OpenAPI{
+		OpenAPI: Version,
+		Info:    generator.Info,
+		Servers: generator.Servers,
+		Paths:   make(map[string]*PathItem),
+	}
+	route := router.GetRoute()
+	generator.walk(document, builder, route, "", nil, nil, 0)
+	for _, hostRoute := range route.Hosts {
+		var tags []string
+		if hostRoute.Desc != "" {
+			tags = []string{hostRoute.Desc}
+		}
+		servers := []Server{hostServer(hostRoute)}
+		generator.walk(document, builder, hostRoute, "", tags, servers, 0)
+	}
+	if len(builder.schemas) > 0 {
+		document.Components = &Components{Schemas: builder.schemas}
+	}
+	return document
+}
+
+// JSON 生成 JSON 格式的 OpenAPI 文档
+//
+// 参数:
+//   - router *goi.Router: 路由
+//
+// 返回:
+//   - []byte: 文档内容
+//   - error: 序列化错误
+func (generator *Generator) JSON(router *goi.Router) ([]byte, error) {
+	return json.MarshalIndent(generator.Build(router), "", "  ")
+}
+
+// YAML 生成 YAML 格式的 OpenAPI 文档
+//
+// 参数:
+//   - router *goi.Router: 路由
+//
+// 返回:
+//   - []byte: 文档内容
+//   - error: 序列化错误
+func (generator *Generator) YAML(router *goi.Router) ([]byte, error) {
+	return yaml.Marshal(generator.Build(router))
+}
+
+// Register 挂载文档路由
+//
+// 参数:
+//   - router *goi.Router: 路由，生成该路由下的文档并在其下挂载文档路由组
+//   - path string: 文档路由组，如 "docs"
+//
+// 返回:
+//   - *goi.Router: 文档路由组
+//
+// 说明:
+//   - <path>/openapi.json: JSON 文档
+//   - <path>/openapi.yaml: YAML 文档
+//   - <path>/ui/swagger.html: Swagger UI，<path>/ 重定向到此页面
+func (generator *Generator) Register(router *goi.Router, path string) *goi.Router {
+	path = strings.Trim(path, "/")
+	generator.docsPath = path + "/"
+
+	docsRouter := router.Include(path, "OpenAPI")
+	docsRouter.Path("", "Swagger UI", goi.ViewSet{
+		GET: func(request *goi.Request) any {
+			response := goi.Response{Status: http.StatusFound, Data: ""}
+			response.Header().Set("Location", "ui/swagger.html")
+			return response
+		},
+	})
+	docsRouter.Path("openapi.json", "OpenAPI JSON", goi.ViewSet{
+		GET: generator.documentView(router, generator.JSON, parser.MIMEJSON),
+	})
+	docsRouter.Path("openapi.yaml", "OpenAPI YAML", goi.ViewSet{
+		GET: generator.documentView(router, generator.YAML, parser.MIMEYAML),
+	})
+	docsRouter.StaticDirFS("ui", "Swagger UI", uiFS, "ui")
+	return docsRouter
+}
+
+// documentView 文档视图，每次请求重新生成以反映最新路由
+func (generator *Generator) documentView(router *goi.Router, marshal func(router *goi.Router) ([]byte, error), contentType string) goi.HandlerFunc {
+	return func(request *goi.Request) any {
+		data, err := marshal(router)
+		if err != nil {
+			return goi.Response{Status: http.StatusInternalServerError, Data: err.Error()}
+		}
+		response := goi.Response{Status: http.StatusOK, Data: data}
+		response.Header().Set(goi.ContentType, contentType)
+		return response
+	}
+}
+
+// walk 遍历路由树
+//
+// 参数:
+//   - document *OpenAPI: 文档对象
+//   - builder *schemaBuilder: Schema 生成器
+//   - route goi.Route: 当前路由
+//   - path string: 上级路由组的完整路由
+//   - tags []string: 默认接口分组
+//   - servers []Server: 主机路由组的服务地址，默认路由为 nil
+//   - depth int: 路由深度，根路由与主机路由组为 0
+func (generator *Generator) walk(document *OpenAPI, builder *schemaBuilder, route goi.Route, path string, tags []string, servers []Server, depth int) {
+	path += route.Path
+	if route.Include != nil {
+		if depth == 1 && servers == nil && route.Path == generator.docsPath {
+			return
+		}
+		if depth > 0 && route.Desc != "" {
+			tags = []string{route.Desc}
+		}
+		for _, itemRoute := range route.Include {
+			generator.walk(document, builder, itemRoute, path, tags, servers, depth+1)
+		}
+		return
+	}
+
+	openapiPath, pathParameters := pathParameters(path)
+	pathItem, exists := document.Paths[openapiPath]
+	if !exists {
+		pathItem = &PathItem{Summary: route.Desc}
+	}
+	methods := []struct {
+		method    string
+		handler   goi.HandlerFunc
+		operation **Operation
+	}{
+		{http.MethodGet, route.ViewSet.GET, &pathItem.Get},
+		{http.MethodHead, route.ViewSet.HEAD, &pathItem.Head},
+		{http.MethodPost, route.ViewSet.POST, &pathItem.Post},
+		{http.MethodPut, route.ViewSet.PUT, &pathItem.Put},
+		{http.MethodPatch, route.ViewSet.PATCH, &pathItem.Patch},
+		{http.MethodDelete, route.ViewSet.DELETE, &pathItem.Delete},
+		{http.MethodOptions, route.ViewSet.Options, &pathItem.Options},
+		{http.MethodTrace, route.ViewSet.TRACE, &pathItem.Trace},
+	}
+	documented := false
+	for _, item := range methods {
+		// 已由默认路由或其它主机记录
+		if item.handler == nil || *item.operation != nil {
+			continue
+		}
+		spec, _ := generator.getSpec(route, path, item.method)
+		*item.operation = generator.operation(builder, route, item.method, spec, pathParameters, tags)
+		(*item.operation).Servers = servers
+		documented = true
+	}
+	if documented {
+		document.Paths[openapiPath] = pathItem
+	}
+}
+
+// operation 生成单个操作
+func (generator *Generator) operation(builder *schemaBuilder, route goi.Route, method string, spec Spec, pathParameters []*Parameter, tags []string) *Operation {
+	operation := &Operation{
+		Tags:        spec.Tags,
+		Summary:     spec.Summary,
+		Description: spec.Description,
+		Parameters:  append([]*Parameter(nil), pathParameters...),
+		Responses:   make(map[string]*Response),
+		Deprecated:  spec.Deprecated,
+	}
+	if operation.Tags == nil {
+		operation.Tags = tags
+	}
+	if operation.Summary == "" {
+		operation.Summary = route.Desc
+	}
+	if route.Name != "" {
+		operation.OperationID = route.Name + "." + strings.ToLower(method)
+	}
+
+	if spec.Query != nil {
+		for _, field := range paramsFields(reflect.TypeOf(spec.Query)) {
+			operation.Parameters = append(operation.Parameters, &Parameter{
+				Name:     field.name,
+				In:       "query",
+				Required: field.required,
+				Schema:   builder.fieldSchema(field),
+			})
+		}
+	}
+	if spec.Body != nil {
+		contentType := spec.BodyContentType
+		if contentType == "" {
+			contentType = parser.MIMEJSON
+		}
+		schema := builder.paramsSchema(reflect.TypeOf(spec.Body))
+		operation.RequestBody = &RequestBody{
+			Required: len(schema.Required) > 0,
+			Content:  map[string]*MediaType{contentType: {Schema: schema}},
+		}
+	}
+
+	if len(spec.Responses) == 0 {
+		operation.Responses[strconv.Itoa(http.StatusOK)] = &Response{Description: http.StatusText(http.StatusOK)}
+	}
+	for status, data := range spec.Responses {
+		response := &Response{Description: http.StatusText(status)}
+		if data != nil {
+			response.Content = map[string]*MediaType{
+				responseContentType(data): {Schema: builder.typeSchema(reflect.TypeOf(data))},
+			}
+		}
+		operation.Responses[strconv.Itoa(status)] = response
+	}
+	return operation
+}
+
+// responseContentType 与 goi.Response 写入时的默认 Content-Type 保持一致
+func responseContentType(data any) string {
+	switch data.(type) {
+	case string:
+		return "text/plain"
+	case []byte:
+		return "application/octet-stream"
+	default:
+		return parser.MIMEJSON
+	}
+}
+
+// hostServer 由主机路由组生成服务地址
+//
+// 参数:
+//   - route goi.Route: 主机路由组，如 Host 为 "<slug:tenant>.example.com"
+//
+// 返回:
+//   - Server: 服务地址，如 "{scheme}://{tenant}.example.com"，主机参数转换为服务地址变量
+func hostServer(route goi.Route) Server {
+	variables := map[string]*ServerVariable{
+		"scheme": {Enum: []string{"https", "http"}, Default: "https"},
+	}
+	host := placeholderRegex.ReplaceAllStringFunc(route.Host, func(placeholder string) string {
+		paramName := placeholderRegex.FindStringSubmatch(placeholder)[2]
+		variables[paramName] = &ServerVariable{Default: paramName}
+		return "{" + paramName + "}"
+	})
+	return Server{
+		URL:         "{scheme}://" + host,
+		Description: route.Desc,
+		Variables:   variables,
+	}
+}
+
+// pathParameters 将 goi 路由转换为 OpenAPI 路径并生成路径参数
+//
+// 参数:
+//   - path string: 完整路由，如 "/api/user/<int:id>"
+//
+// 返回:
+//   - string: OpenAPI 路径，如 "/api/user/{id}"
+//   - []*Parameter: 路径参数
+func pathParameters(path string) (string, []*Parameter) {
+	var parameters []*Parameter
+	openapiPath := placeholderRegex.ReplaceAllStringFunc(path, func(placeholder string) string {
+		match := placeholderRegex.FindStringSubmatch(placeholder)
+		typeName, paramName := match[1], match[2]
+		parameters = append(parameters, &Parameter{
+			Name:     paramName,
+			In:       "path",
+			Required: true,
+			Schema:   converterSchema(typeName),
+		})
+		return "{" + paramName + "}"
+	})
+	return openapiPath, parameters
+}
+
+// converterSchema 由路由转换器生成路径参数 Schema
+func converterSchema(typeName string) *Schema {
+	switch typeName {
+	case "int":
+		return &Schema{Type: "integer"}
+	case "string", "path":
+		return &Schema{Type: "string"}
+	case "slug":
+		return &Schema{Type: "string", Pattern: `^[-a-zA-Z0-9_]+$`}
+	case "uuid":
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+	if converter, ok := goi.GetConverter(typeName); ok {
+		return &Schema{Type: "string", Pattern: "^" + converter.Regex + "$"}
+	}
+	return &Schema{Type: "string"}
+}
@@ -0,0 +1,113 @@
+package openapi_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/NeverStopDreamingWang/goi/v2"
+	"github.com/NeverStopDreamingWang/goi/v2/openapi"
+)
+
+type userQuery struct {
+	Page int    `name:"page" type:"int"`
+	Name string `name:"name" type:"string" required:"true" allow_null:"false"`
+}
+
+type userBody struct {
+	Username string `name:"username" type:"string" required:"true"`
+	Age      int    `name:"age" type:"int"`
+	Ignored  string `name:"ignored"`
+}
+
+type user struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email,omitempty"`
+}
+
+func ExampleGenerator_Build() {
+	server := goi.NewHTTPServer()
+	userRouter := server.Router.Include("user", "用户")
+	userRouter.Path("", "用户列表", goi.ViewSet{
+		GET:  func(request *goi.Request) any { return nil },
+		POST: func(request *goi.Request) any { return nil },
+	}).Name("user-list")
+	userRouter.Path("<int:id>", "用户详情", goi.ViewSet{
+		GET: func(request *goi.Request) any { return nil },
+	})
+
+	generator := openapi.New("goi API", "1.0.0")
+	generator.Operation("user-list", http.MethodGet, openapi.Spec{
+		Query:     userQuery{},
+		Responses: map[int]any{http.StatusOK: []user{}},
+	})
+	generator.Operation("user-list", http.MethodPost, openapi.Spec{
+		Body:      userBody{},
+		Responses: map[int]any{http.StatusCreated: user{}},
+	})
+	generator.Operation("/user/<int:id>", http.MethodGet, openapi.Spec{
+		Responses: map[int]any{http.StatusOK: user{}, http.StatusNotFound: "not found"},
+	})
+	generator.Register(server.Router, "docs")
+
+	document := generator.Build(server.Router)
+	fmt.Println(len(document.Paths))
+
+	list := document.Paths["/user/"]
+	fmt.Println(list.Get.OperationID, list.Get.Tags)
+	for _, parameter := range list.Get.Parameters {
+		fmt.Println(parameter.In, parameter.Name, parameter.Required, parameter.Schema.Type)
+	}
+	fmt.Println(list.Get.Responses["200"].Content["application/json"].Schema.Items.Ref)
+	body := list.Post.RequestBody.Content["application/json"].Schema
+	fmt.Println(len(body.Properties), body.Required)
+
+	detail := document.Paths["/user/{id}"]
+	fmt.Println(detail.Get.Parameters[0].In, detail.Get.Parameters[0].Name, detail.Get.Parameters[0].Schema.Type)
+	fmt.Println(detail.Get.Responses["404"].Content["text/plain"].Schema.Type)
+	fmt.Println(document.Components.Schemas["user"].Required)
+
+	// Output:
+	// 2
+	// user-list.get [用户]
+	// query page false [integer null]
+	// query name true string
+	// #/components/schemas/user
+	// 2 [username]
+	// path id integer
+	// string
+	// [id username]
+}
+
+func ExampleGenerator_Build_host() {
+	server := goi.NewHTTPServer()
+	server.Router.Path("ping", "默认主机", goi.ViewSet{
+		GET: func(request *goi.Request) any { return nil },
+	})
+	tenantRouter := server.Router.Host("<slug:tenant>.example.com", "租户")
+	tenantRouter.Path("ping", "租户主机", goi.ViewSet{
+		GET:  func(request *goi.Request) any { return nil },
+		POST: func(request *goi.Request) any { return nil },
+	})
+	tenantRouter.Path("order/<int:id>", "订单详情", goi.ViewSet{
+		GET: func(request *goi.Request) any { return nil },
+	})
+
+	document := openapi.New("goi API", "1.0.0").Build(server.Router)
+	fmt.Println(len(document.Paths))
+
+	ping := document.Paths["/ping"]
+	fmt.Println(ping.Get.Summary, len(ping.Get.Servers))
+	fmt.Println(ping.Post.Summary, ping.Post.Tags, ping.Post.Servers[0].URL)
+
+	order := document.Paths["/order/{id}"].Get
+	fmt.Println(order.Tags, order.Parameters[0].Name, order.Servers[0].Description)
+	fmt.Println(order.Servers[0].Variables["tenant"].Default, order.Servers[0].Variables["scheme"].Enum)
+
+	// Output:
+	// 2
+	// 默认主机 0
+	// 租户主机 [租户] {scheme}://{tenant}.example.com
+	// [租户] id 租户
+	// tenant [https http]
+}
@@ -0,0 +1,262 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaNameRegex 匹配 components 中不允许的结构名字符
+var schemaNameRegex = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// schemaBuilder 由 Go 类型生成 Schema，具名结构体注册到 components 并以 $ref 引用
+type schemaBuilder struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+// newSchemaBuilder 创建 Schema 生成器
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// typeSchema 按 encoding/json 规则生成 Go 类型的 Schema
+//
+// 参数:
+//   - t reflect.Type: Go 类型，nil 表示任意值
+//
+// 返回:
+//   - *Schema: 结构定义
+func (builder *schemaBuilder) typeSchema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: builder.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: builder.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return builder.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + builder.register(t)}
+	default:
+		return &Schema{}
+	}
+}
+
+// register 注册具名结构体到 components
+//
+// 参数:
+//   - t reflect.Type: 结构体类型
+//
+// 返回:
+//   - string: components 中的结构名
+func (builder *schemaBuilder) register(t reflect.Type) string {
+	if name, ok := builder.names[t]; ok {
+		return name
+	}
+	baseName := schemaNameRegex.ReplaceAllString(t.Name(), "_")
+	name := baseName
+	for i := 2; builder.schemas[name] != nil; i++ {
+		name = fmt.Sprintf("%v_%d", baseName, i)
+	}
+	// 先占位，避免递归结构无限展开
+	builder.names[t] = name
+	builder.schemas[name] = &Schema{}
+	*builder.schemas[name] = *builder.structSchema(t)
+	return name
+}
+
+// structSchema 按 encoding/json 规则生成结构体的 object Schema
+//
+// 参数:
+//   - t reflect.Type: 结构体类型
+//
+// 返回:
+//   - *Schema: 结构定义，未标记 omitempty 的字段为必填
+func (builder *schemaBuilder) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			// 匿名嵌入结构体字段提升到外层
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				embedded := builder.structSchema(fieldType)
+				for propertyName, property := range embedded.Properties {
+					schema.Properties[propertyName] = property
+				}
+				schema.Required = append(schema.Required, embedded.Required...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = builder.typeSchema(field.Type)
+		if !strings.Contains(options, "omitempty") && !strings.Contains(options, "omitzero") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// paramsField 参数结构体字段信息，与 goi.Params.ParseParams 的标签规则一致
+type paramsField struct {
+	name      string       // 参数名
+	validator string       // type 标签，验证器名称
+	required  bool         // 是否必填
+	nullable  bool         // 是否允许 null
+	fieldType reflect.Type // 字段类型
+}
+
+// paramsFields 解析参数结构体字段
+//
+// 参数:
+//   - t reflect.Type: 参数结构体类型
+//
+// 返回:
+//   - []paramsField: 参与 ParseParams 解析的字段，无 type 标签的字段被跳过
+func paramsFields(t reflect.Type) []paramsField {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	fields := make([]paramsField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldName, _, _ := strings.Cut(field.Tag.Get("name"), ",")
+		if fieldName == "-" {
+			continue
+		}
+		if fieldName == "" {
+			fieldName, _, _ = strings.Cut(field.Tag.Get("json"), ",")
+			if fieldName == "-" {
+				continue
+			}
+		}
+		if fieldName == "" {
+			fieldName = strings.ToLower(field.Name)
+		}
+		validatorName := field.Tag.Get("type")
+		if validatorName == "" || validatorName == "-" {
+			continue
+		}
+		required := field.Tag.Get("required") == "true"
+		allowNull := field.Tag.Get("allow_null")
+		fields = append(fields, paramsField{
+			name:      fieldName,
+			validator: validatorName,
+			required:  required,
+			nullable:  !(allowNull == "false" || (required && allowNull != "true")),
+			fieldType: field.Type,
+		})
+	}
+	return fields
+}
+
+// fieldSchema 按验证器名称生成参数字段的 Schema
+//
+// 参数:
+//   - field paramsField: 参数字段
+//
+// 返回:
+//   - *Schema: 结构定义
+func (builder *schemaBuilder) fieldSchema(field paramsField) *Schema {
+	var schema *Schema
+	switch field.validator {
+	case "bool":
+		schema = &Schema{Type: "boolean"}
+	case "int":
+		schema = &Schema{Type: "integer"}
+	case "string":
+		schema = &Schema{Type: "string"}
+	case "time":
+		schema = &Schema{Type: "string", Format: "date-time"}
+	case "slice":
+		fieldType := field.fieldType
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array {
+			schema = &Schema{Type: "array", Items: builder.typeSchema(fieldType.Elem())}
+		} else {
+			schema = &Schema{Type: "array", Items: &Schema{}}
+		}
+	case "map":
+		schema = &Schema{Type: "object"}
+	case "slug":
+		schema = &Schema{Type: "string", Pattern: `^[-a-zA-Z0-9_]+$`}
+	case "uuid":
+		schema = &Schema{Type: "string", Format: "uuid"}
+	default:
+		schema = builder.typeSchema(field.fieldType)
+	}
+	if field.nullable {
+		if typeName, ok := schema.Type.(string); ok {
+			schema.Type = []string{typeName, "null"}
+		}
+	}
+	return schema
+}
+
+// paramsSchema 生成参数结构体的 object Schema
+//
+// 参数:
+//   - t reflect.Type: 参数结构体类型
+//
+// 返回:
+//   - *Schema: 结构定义
+func (builder *schemaBuilder) paramsSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, field := range paramsFields(t) {
+		schema.Properties[field.name] = builder.fieldSchema(field)
+		if field.required {
+			schema.Required = append(schema.Required, field.name)
+		}
+	}
+	return schema
+}
@@ -1,28 +1,43 @@
 package goi
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"runtime/debug"
 	"time"
 )
 
-func (engine *Engine) recovery(request *Request, responseWriter *ResponseWriter, elapsed time.Duration) {
+// recovery 捕获请求处理中的 panic 并记录访问日志
+//
+// 参数:
+//   - request *Request: HTTP请求对象
+//   - responseWriter *ResponseWriter: 响应写入器
+//   - startTime time.Time: 请求开始时间
+func (engine *Engine) recovery(request *Request, responseWriter *ResponseWriter, startTime time.Time) {
+	log := request.Log()
 	err := recover()
 	if err != nil {
-		// 如果没有写入响应，则写入 500 错误
+		// 如果没有写入响应，则写入 500 错误，上下文超时写入 504 错误
 		if responseWriter.Status == 0 && responseWriter.bytes == 0 {
-			responseWriter.WriteHeader(http.StatusInternalServerError)
-			_, _ = responseWriter.Write([]byte("Internal Server Error"))
+			if e, ok := err.(error); ok && errors.Is(e, context.DeadlineExceeded) {
+				responseWriter.WriteHeader(http.StatusGatewayTimeout)
+				_, _ = responseWriter.Write([]byte("Gateway Timeout"))
+			} else {
+				responseWriter.WriteHeader(http.StatusInternalServerError)
+				_, _ = responseWriter.Write([]byte("Internal Server Error"))
+			}
 		}
-		engine.Log.Error(fmt.Sprintf("%v", err))
-		engine.Log.Error(string(debug.Stack()))
+		log.Error(fmt.Sprintf("%v", err))
+		log.Error(string(debug.Stack()))
 	}
 
 	// 格式化时间单位（统一为毫秒，保留2位小数）
+	elapsed := GetTime().Sub(startTime)
 	timeMs := float64(elapsed) / float64(time.Millisecond)
 
-	log := fmt.Sprintf("- %v - %v %v => generated %d bytes in %.2f msecs (%s %d) %d headers",
+	accessLog := fmt.Sprintf("- %v - %v %v => generated %d bytes in %.2f msecs (%s %d) %d headers",
 		request.Object.RemoteAddr,
 		request.Object.Method,
 		request.Object.URL.Path,
@@ -32,5 +47,5 @@ func (engine *Engine) recovery(request *Request, responseWriter *ResponseWriter,
 		responseWriter.Status,
 		len(responseWriter.Header()),
 	)
-	engine.Log.Info(log)
+	log.Info(accessLog)
 }
@@ -0,0 +1,17 @@
+package render
+
+import (
+	"encoding/json"
+)
+
+var JSON jsonRenderer
+
+type jsonRenderer struct{}
+
+func (jsonRenderer) Name() string {
+	return "json"
+}
+
+func (jsonRenderer) Render(data any) ([]byte, error) {
+	return json.Marshal(data)
+}
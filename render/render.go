@@ -0,0 +1,200 @@
+// Package render 提供响应数据的编码器注册表与内容协商
+//
+// 内置 JSON、XML、YAML 编码器，MIME 类型与 parser 包一致，可通过 RegisterRenderer 注册 MessagePack 等格式
+package render
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/NeverStopDreamingWang/goi/v2/parser"
+)
+
+// Renderer 响应编码器接口
+type Renderer interface {
+	// Name 返回编码器名称，同时作为 ?format= 参数的取值，例如 "json"
+	Name() string
+	// Render 将响应数据编码为字节
+	Render(data any) ([]byte, error)
+}
+
+// rendererMu 保护 renderers 与 mediaTypes
+var rendererMu sync.RWMutex
+
+// renderers MIME 类型到编码器的映射
+var renderers = map[string]Renderer{
+	parser.MIMEJSON:    JSON,
+	parser.MIMEXML:     XML,
+	parser.MIMETextXML: XML,
+	parser.MIMEYAML:    YAML,
+}
+
+// mediaTypes 已注册的 MIME 类型，按注册顺序排列，Accept 为空或 */* 时优先使用靠前的类型
+var mediaTypes = []string{parser.MIMEJSON, parser.MIMEXML, parser.MIMETextXML, parser.MIMEYAML}
+
+// RegisterRenderer 注册自定义编码器，同一 MIME 类型重复注册时覆盖
+//
+// 参数:
+//   - mediaType string: MIME 类型，例如 "application/msgpack"
+//   - r Renderer: 编码器
+func RegisterRenderer(mediaType string, r Renderer) {
+	rendererMu.Lock()
+	defer rendererMu.Unlock()
+	if _, ok := renderers[mediaType]; !ok {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	renderers[mediaType] = r
+}
+
+// GetRenderer 根据 MIME 类型获取编码器
+//
+// 参数:
+//   - mediaType string: MIME 类型
+//
+// 返回:
+//   - Renderer: 编码器
+//   - bool: 是否已注册
+func GetRenderer(mediaType string) (Renderer, bool) {
+	rendererMu.RLock()
+	defer rendererMu.RUnlock()
+	r, ok := renderers[mediaType]
+	return r, ok
+}
+
+// acceptRange Accept 请求头中的一个媒体范围
+type acceptRange struct {
+	mediaType   string  // 媒体类型，例如 "application/json"、"text/*"、"*/*"
+	quality     float64 // q 值
+	specificity int     // 精确程度: 2 完整类型，1 type/*，0 */*
+}
+
+// parseAccept 解析 Accept 请求头
+//
+// 参数:
+//   - accept string: Accept 请求头
+//
+// 返回:
+//   - []acceptRange: 按出现顺序排列的媒体范围
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+		specificity := 2
+		if mediaType == "*/*" {
+			specificity = 0
+		} else if strings.HasSuffix(mediaType, "/*") {
+			specificity = 1
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality, specificity: specificity})
+	}
+	return ranges
+}
+
+// match 查找匹配 MIME 类型且最精确的媒体范围
+//
+// 参数:
+//   - ranges []acceptRange: 媒体范围
+//   - mediaType string: MIME 类型
+//
+// 返回:
+//   - int: 媒体范围下标，不匹配时为 -1
+func match(ranges []acceptRange, mediaType string) int {
+	index := -1
+	for i, r := range ranges {
+		matched := r.mediaType == mediaType ||
+			r.specificity == 0 ||
+			(r.specificity == 1 && strings.HasPrefix(mediaType, strings.TrimSuffix(r.mediaType, "*")))
+		if matched && (index == -1 || r.specificity > ranges[index].specificity) {
+			index = i
+		}
+	}
+	return index
+}
+
+// Negotiate 根据 Accept 请求头或 format 参数选择编码器
+//
+// 参数:
+//   - accept string: Accept 请求头，为空时视为 */*
+//   - format string: 编码器名称，例如 ?format=xml 的取值，非空时忽略 Accept
+//   - allowed []string: 可选的 MIME 类型，按偏好排列，为空时使用全部已注册类型
+//
+// 返回:
+//   - string: 选中的 MIME 类型，作为响应 Content-Type
+//   - Renderer: 选中的编码器
+//   - bool: 是否存在可接受的编码器，为 false 时应返回 406 Not Acceptable
+//
+// 说明:
+//   - 首选类型（allowed 或已注册类型中的第一个，默认 JSON）通过通配符被接受时直接选择首选类型，
+//     例如浏览器的 "text/html,application/xml;q=0.9,*/*;q=0.8" 仍返回 JSON
+//   - 否则选择 q 值最高的类型，q 值相同时依次比较媒体范围的精确程度、在 Accept 中的位置与 allowed 中的顺序
+//   - q=0 的类型不可接受
+func Negotiate(accept string, format string, allowed []string) (string, Renderer, bool) {
+	rendererMu.RLock()
+	defer rendererMu.RUnlock()
+	candidates := allowed
+	if len(candidates) == 0 {
+		candidates = mediaTypes
+	}
+
+	if format != "" {
+		for _, mediaType := range candidates {
+			if r, ok := renderers[mediaType]; ok && r.Name() == format {
+				return mediaType, r, true
+			}
+		}
+		return "", nil, false
+	}
+
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		ranges = []acceptRange{{mediaType: "*/*", quality: 1}}
+	}
+	var bestType string
+	var bestRenderer Renderer
+	best := -1
+	preferred := true
+	for _, mediaType := range candidates {
+		r, ok := renderers[mediaType]
+		if !ok {
+			continue
+		}
+		index := match(ranges, mediaType)
+		if preferred {
+			preferred = false
+			// 客户端未明确列出首选类型，仅通过通配符接受时保持服务端偏好
+			if index != -1 && ranges[index].quality > 0 && ranges[index].specificity < 2 {
+				return mediaType, r, true
+			}
+		}
+		if index == -1 || ranges[index].quality <= 0 {
+			continue
+		}
+		if best == -1 || better(ranges[index], index, ranges[best], best) {
+			bestType, bestRenderer, best = mediaType, r, index
+		}
+	}
+	return bestType, bestRenderer, best != -1
+}
+
+// better 比较两个匹配的媒体范围，a 优于 b 时返回 true
+func better(a acceptRange, aIndex int, b acceptRange, bIndex int) bool {
+	if a.quality != b.quality {
+		return a.quality > b.quality
+	}
+	if a.specificity != b.specificity {
+		return a.specificity > b.specificity
+	}
+	return aIndex < bIndex
+}
@@ -0,0 +1,70 @@
+package render_test
+
+import (
+	"testing"
+
+	"github.com/NeverStopDreamingWang/goi/v2/parser"
+	"github.com/NeverStopDreamingWang/goi/v2/render"
+)
+
+// TestNegotiate 验证 q 值、通配符、精确程度、服务端偏好、format 参数与 allowed 限制
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept  string
+		format  string
+		allowed []string
+		want    string
+		ok      bool
+	}{
+		{accept: "", want: parser.MIMEJSON, ok: true},
+		{accept: "*/*", want: parser.MIMEJSON, ok: true},
+		{accept: "application/xml", want: parser.MIMEXML, ok: true},
+		{accept: "text/xml", want: parser.MIMETextXML, ok: true},
+		{accept: "application/json;q=0.5, application/x-yaml", want: parser.MIMEYAML, ok: true},
+		{accept: "application/xml, application/json", want: parser.MIMEXML, ok: true},
+		{accept: "text/html, application/*;q=0.8", want: parser.MIMEJSON, ok: true},
+		{accept: "*/*, application/json;q=0", want: parser.MIMEXML, ok: true},
+		{accept: "text/html", ok: false},
+		// 浏览器默认 Accept，JSON 仅通过通配符接受时仍优先 JSON
+		{accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", want: parser.MIMEJSON, ok: true},
+		{accept: "application/xml, */*;q=0.1", want: parser.MIMEJSON, ok: true},
+		{accept: "application/xml, application/*;q=0", want: parser.MIMEXML, ok: true},
+		{accept: "text/*, application/x-yaml", want: parser.MIMEYAML, ok: true},
+		{accept: "application/xml", format: "yaml", want: parser.MIMEYAML, ok: true},
+		{format: "csv", ok: false},
+		{accept: "*/*", allowed: []string{parser.MIMEYAML, parser.MIMEJSON}, want: parser.MIMEYAML, ok: true},
+		{accept: "application/xml", allowed: []string{parser.MIMEJSON}, ok: false},
+		{format: "xml", allowed: []string{parser.MIMEJSON}, ok: false},
+	}
+	for _, test := range tests {
+		got, renderer, ok := render.Negotiate(test.accept, test.format, test.allowed)
+		if got != test.want || ok != test.ok || (ok && renderer == nil) {
+			t.Errorf("Negotiate(%q, %q, %v) = %q, %v, want %q, %v", test.accept, test.format, test.allowed, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+// TestXMLRenderer 验证 map 与切片的 XML 编码
+func TestXMLRenderer(t *testing.T) {
+	data, err := render.XML.Render(map[string]any{
+		"name": "goi",
+		"tags": []string{"web", "go"},
+		"meta": map[string]int{"stars": 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<response><meta><stars>1</stars></meta><name>goi</name><tags>web</tags><tags>go</tags></response>`
+	if string(data) != want {
+		t.Errorf("map = %s", data)
+	}
+
+	data, err = render.XML.Render([]map[string]int{{"id": 1}, {"id": 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<response><item><id>1</id></item><item><id>2</id></item></response>`; string(data[len(data)-len(want):]) != want {
+		t.Errorf("slice = %s", data)
+	}
+}
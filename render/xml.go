@@ -0,0 +1,117 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"slices"
+)
+
+// xmlRoot map 与切片编码为 XML 时的根元素名称
+const xmlRoot = "response"
+
+var XML xmlRenderer
+
+type xmlRenderer struct{}
+
+func (xmlRenderer) Name() string {
+	return "xml"
+}
+
+// Render 将响应数据编码为 XML
+//
+// 参数:
+//   - data any: 响应数据
+//
+// 返回:
+//   - []byte: 包含 XML 声明的文档
+//   - error: 编码过程中的错误信息
+//
+// 说明:
+//   - 结构体使用 encoding/xml 编码
+//   - 字符串键的 map 编码为 <response>，键按字典序作为子元素名称
+//   - 顶层切片编码为 <response><item>...</item></response>，嵌套切片展开为同名的重复元素
+func (xmlRenderer) Render(data any) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buffer)
+	value := reflect.ValueOf(data)
+	var err error
+	switch {
+	case isXMLMap(value):
+		err = encoder.EncodeElement(xmlValue(data), xml.StartElement{Name: xml.Name{Local: xmlRoot}})
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() != reflect.Uint8, value.Kind() == reflect.Array:
+		items := make([]any, value.Len())
+		for i := range items {
+			items[i] = xmlValue(value.Index(i).Interface())
+		}
+		err = encoder.EncodeElement(struct {
+			Items []any `xml:"item"`
+		}{items}, xml.StartElement{Name: xml.Name{Local: xmlRoot}})
+	default:
+		err = encoder.Encode(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Flush()
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// xmlMap 可编码为 XML 的字符串键 map
+type xmlMap map[string]any
+
+// MarshalXML 实现 xml.Marshaler 接口，键按字典序编码为子元素
+func (m xmlMap) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		err = encoder.EncodeElement(m[key], xml.StartElement{Name: xml.Name{Local: key}})
+		if err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+// isXMLMap 检查值是否为字符串键的 map
+func isXMLMap(value reflect.Value) bool {
+	return value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String
+}
+
+// xmlValue 将 map 及切片中的 map 转换为可编码为 XML 的值
+//
+// 参数:
+//   - data any: 响应数据
+//
+// 返回:
+//   - any: 转换后的值，其它类型原样返回
+func xmlValue(data any) any {
+	value := reflect.ValueOf(data)
+	switch {
+	case isXMLMap(value):
+		m := make(xmlMap, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = xmlValue(iter.Value().Interface())
+		}
+		return m
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() != reflect.Uint8:
+		items := make([]any, value.Len())
+		for i := range items {
+			items[i] = xmlValue(value.Index(i).Interface())
+		}
+		return items
+	}
+	return data
+}
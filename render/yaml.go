@@ -0,0 +1,17 @@
+package render
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+var YAML yamlRenderer
+
+type yamlRenderer struct{}
+
+func (yamlRenderer) Name() string {
+	return "yaml"
+}
+
+func (yamlRenderer) Render(data any) ([]byte, error) {
+	return yaml.Marshal(data)
+}
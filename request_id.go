@@ -0,0 +1,194 @@
+package goi
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// RequestIDGenerator 请求 ID 生成器
+type RequestIDGenerator interface {
+	// Generate 为请求生成唯一 ID
+	//
+	// 参数:
+	//   - request *http.Request: HTTP请求对象
+	//
+	// 返回:
+	//   - string: 请求 ID
+	Generate(request *http.Request) string
+}
+
+// RequestIDGeneratorFunc 函数形式的请求 ID 生成器
+type RequestIDGeneratorFunc func(request *http.Request) string
+
+// Generate 调用函数生成请求 ID
+//
+// 参数:
+//   - request *http.Request: HTTP请求对象
+//
+// 返回:
+//   - string: 请求 ID
+func (generator RequestIDGeneratorFunc) Generate(request *http.Request) string {
+	return generator(request)
+}
+
+// RequestID 请求 ID 设置
+//
+// 说明:
+//   - 请求期间框架交给中间件与视图的日志器 request.Log() 输出的每行日志都自动包含请求 ID，包括访问日志与异常日志
+type RequestID struct {
+	Header    string             // 回写请求 ID 的响应头，为空时不回写，默认 "X-Request-ID"
+	Generator RequestIDGenerator // 请求 ID 生成器，为空时使用 ULIDGenerator
+}
+
+// ULIDGenerator 生成 ULID 格式的请求 ID
+//
+// 说明:
+//   - 26 位 Crockford Base32 字符串，前 48 位为毫秒时间戳，后 80 位为随机数，按时间有序
+type ULIDGenerator struct{}
+
+// crockford Crockford Base32 字母表
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Generate 生成 ULID
+//
+// 参数:
+//   - request *http.Request: HTTP请求对象
+//
+// 返回:
+//   - string: ULID 字符串
+func (ULIDGenerator) Generate(request *http.Request) string {
+	var id [16]byte
+	putTimestamp(id[:], GetTime())
+	_, _ = rand.Read(id[6:])
+
+	// 128 位按 5 位一组编码，首字符仅占 3 位
+	var dst [26]byte
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+	for i := 25; i >= 0; i-- {
+		dst[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(dst[:])
+}
+
+// UUIDv7Generator 生成 UUIDv7 格式的请求 ID
+//
+// 说明:
+//   - RFC 9562 UUID 第 7 版，前 48 位为毫秒时间戳，按时间有序
+type UUIDv7Generator struct{}
+
+// Generate 生成 UUIDv7
+//
+// 参数:
+//   - request *http.Request: HTTP请求对象
+//
+// 返回:
+//   - string: UUID 字符串，例如 "0190b4d2-8f1e-7c3a-9b2d-5e6f7a8b9c0d"
+func (UUIDv7Generator) Generate(request *http.Request) string {
+	var id [16]byte
+	putTimestamp(id[:], GetTime())
+	_, _ = rand.Read(id[6:])
+	id[6] = id[6]&0x0f | 0x70 // 版本 7
+	id[8] = id[8]&0x3f | 0x80 // RFC 9562 变体
+
+	var dst [36]byte
+	hex.Encode(dst[0:8], id[0:4])
+	dst[8] = '-'
+	hex.Encode(dst[9:13], id[4:6])
+	dst[13] = '-'
+	hex.Encode(dst[14:18], id[6:8])
+	dst[18] = '-'
+	hex.Encode(dst[19:23], id[8:10])
+	dst[23] = '-'
+	hex.Encode(dst[24:], id[10:])
+	return string(dst[:])
+}
+
+// HeaderGenerator 透传请求头中的请求 ID
+//
+// 说明:
+//   - 请求头缺失或包含非法字符时使用 Fallback 生成
+//   - 仅接受 1~128 位的字母、数字及 "-_.:" 字符，防止日志注入
+type HeaderGenerator struct {
+	Header   string             // 读取请求 ID 的请求头，默认 "X-Request-ID"
+	Fallback RequestIDGenerator // 后备生成器，为空时使用 ULIDGenerator
+}
+
+// Generate 读取请求头中的请求 ID
+//
+// 参数:
+//   - request *http.Request: HTTP请求对象
+//
+// 返回:
+//   - string: 请求 ID
+func (generator HeaderGenerator) Generate(request *http.Request) string {
+	header := generator.Header
+	if header == "" {
+		header = "X-Request-ID"
+	}
+	requestID := request.Header.Get(header)
+	if validRequestID(requestID) {
+		return requestID
+	}
+	if generator.Fallback != nil {
+		return generator.Fallback.Generate(request)
+	}
+	return ULIDGenerator{}.Generate(request)
+}
+
+// putTimestamp 将毫秒时间戳写入前 6 个字节
+//
+// 参数:
+//   - dst []byte: 目标字节切片
+//   - t time.Time: 时间
+func putTimestamp(dst []byte, t time.Time) {
+	ms := uint64(t.UnixMilli())
+	dst[0] = byte(ms >> 40)
+	dst[1] = byte(ms >> 32)
+	dst[2] = byte(ms >> 24)
+	dst[3] = byte(ms >> 16)
+	dst[4] = byte(ms >> 8)
+	dst[5] = byte(ms)
+}
+
+// validRequestID 检查透传的请求 ID 是否合法
+//
+// 参数:
+//   - requestID string: 请求 ID
+//
+// 返回:
+//   - bool: 是否合法
+func validRequestID(requestID string) bool {
+	if len(requestID) == 0 || len(requestID) > 128 {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		c := requestID[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-' || c == '_' || c == '.' || c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// generateRequestID 按设置生成请求 ID
+//
+// 参数:
+//   - request *http.Request: HTTP请求对象
+//
+// 返回:
+//   - string: 请求 ID
+func (engine *Engine) generateRequestID(request *http.Request) string {
+	if engine.Settings != nil && engine.Settings.RequestID.Generator != nil {
+		return engine.Settings.RequestID.Generator.Generate(request)
+	}
+	return ULIDGenerator{}.Generate(request)
+}
@@ -0,0 +1,107 @@
+package goi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestRequestIDGenerator 验证请求 ID 的格式、唯一性与透传
+func TestRequestIDGenerator(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	cases := []struct {
+		generator RequestIDGenerator
+		regex     *regexp.Regexp
+	}{
+		{ULIDGenerator{}, regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)},
+		{UUIDv7Generator{}, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)},
+	}
+	for _, c := range cases {
+		seen := make(map[string]bool)
+		for i := 0; i < 1000; i++ {
+			requestID := c.generator.Generate(request)
+			if !c.regex.MatchString(requestID) {
+				t.Fatalf("%T generated invalid id %q", c.generator, requestID)
+			}
+			if seen[requestID] {
+				t.Fatalf("%T generated duplicate id %q", c.generator, requestID)
+			}
+			seen[requestID] = true
+		}
+	}
+
+	generator := HeaderGenerator{Fallback: RequestIDGeneratorFunc(func(*http.Request) string { return "fallback" })}
+	request.Header.Set("X-Request-ID", "upstream-1")
+	if got := generator.Generate(request); got != "upstream-1" {
+		t.Errorf("passthrough = %q, want %q", got, "upstream-1")
+	}
+	request.Header.Set("X-Request-ID", "bad id\n")
+	if got := generator.Generate(request); got != "fallback" {
+		t.Errorf("invalid passthrough = %q, want %q", got, "fallback")
+	}
+}
+
+// logMiddleware 通过请求日志器输出日志的中间件
+type logMiddleware struct{}
+
+func (middleware logMiddleware) ProcessRequest(request *Request) any {
+	request.Log().Info("middleware log")
+	return nil
+}
+
+func (middleware logMiddleware) ProcessException(request *Request, exception any) any { return nil }
+
+func (middleware logMiddleware) ProcessResponse(request *Request, response *Response) {}
+
+// TestServeHTTPRequestID 验证请求 ID 回写响应头并写入 Engine 的日志
+func TestServeHTTPRequestID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	engine := NewHTTPServer()
+	engine.Log = NewLogger(path)
+	defer engine.Log.File.Close()
+	engine.Settings = &settings{RequestID: RequestID{
+		Header:    "X-Request-ID",
+		Generator: HeaderGenerator{},
+	}}
+
+	var viewID string
+	engine.Router.Use(logMiddleware{})
+	engine.Router.Path("ping", "测试", ViewSet{GET: func(request *Request) any {
+		viewID = request.ID()
+		request.Log().Info("view log")
+		// 仅持有上下文的代码获取同一日志器
+		if logger, ok := LoggerKey.Value(request.Context()); !ok || logger != request.Log() {
+			t.Errorf("LoggerKey = %v, %v", logger, ok)
+		} else {
+			logger.Info("context log")
+		}
+		return "pong"
+	}})
+
+	request := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	request.Header.Set("X-Request-ID", "trace-42")
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+
+	if got := recorder.Header().Get("X-Request-ID"); got != "trace-42" || viewID != "trace-42" {
+		t.Errorf("request id = %q (view %q), want %q", got, viewID, "trace-42")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "[trace-42]") {
+		t.Errorf("access log missing request id: %q", content)
+	}
+	// 中间件与视图的日志写入处理请求的 Engine 的日志器并包含请求 ID
+	for _, line := range []string{"[trace-42] middleware log", "[trace-42] view log", "[trace-42] context log"} {
+		if !strings.Contains(string(content), line) {
+			t.Errorf("%q missing from engine log: %q", line, content)
+		}
+	}
+}
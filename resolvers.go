@@ -1,7 +1,9 @@
 package goi
 
 import (
+	"net"
 	"regexp"
+	"regexp/syntax"
 	"strings"
 
 	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
@@ -13,16 +15,20 @@ type ParamInfo struct {
 	Converter *Converter // 转换器指针
 }
 
-// compilePattern 将路由定义编译为正则
-// 支持占位符: <type:name>，type 来自 converter.go 中的注册转换器
-func (router *Router) compilePattern() {
-	if router.pattern != "" {
-		return
-	}
+// placeholderRegex 匹配路由占位符 <type:name>
+var placeholderRegex = regexp.MustCompile(`<([^/<>]+):([^/<>]+)>`)
 
-	// 查找占位符 <type:name>
-	re := regexp.MustCompile(`<([^/<>]+):([^/<>]+)>`)
-	matches := re.FindAllStringSubmatchIndex(router.path, -1)
+// compilePath 将路由定义编译为正则表达式（不含锚点）
+// 支持占位符: <type:name>，type 来自 converter.go 中的注册转换器
+//
+// 参数:
+//   - path string: 路由定义
+//
+// 返回:
+//   - string: 路由正则表达式
+//   - []ParamInfo: 参数信息列表
+func compilePath(path string) (string, []ParamInfo) {
+	matches := placeholderRegex.FindAllStringSubmatchIndex(path, -1)
 
 	paramInfos := make([]ParamInfo, 0, len(matches))
 	patternBuilder := strings.Builder{}
@@ -35,11 +41,11 @@ func (router *Router) compilePattern() {
 		nameStart, nameEnd := loc[4], loc[5]
 
 		// 追加占位符之前的字面量
-		patternBuilder.WriteString(regexp.QuoteMeta(router.path[last:fullStart]))
+		patternBuilder.WriteString(regexp.QuoteMeta(path[last:fullStart]))
 
 		// 解析类型与名称
-		typeName := router.path[typeStart:typeEnd]
-		paramName := router.path[nameStart:nameEnd]
+		typeName := path[typeStart:typeEnd]
+		paramName := path[nameStart:nameEnd]
 
 		converter, ok := GetConverter(typeName)
 		if !ok {
@@ -60,8 +66,18 @@ func (router *Router) compilePattern() {
 	}
 
 	// 追加尾部字面量
-	patternBuilder.WriteString(regexp.QuoteMeta(router.path[last:]))
-	router.pattern = patternBuilder.String()
+	patternBuilder.WriteString(regexp.QuoteMeta(path[last:]))
+	return patternBuilder.String(), paramInfos
+}
+
+// compilePattern 将路由定义编译为正则
+// 支持占位符: <type:name>，type 来自 converter.go 中的注册转换器
+func (router *Router) compilePattern() {
+	if router.pattern != "" {
+		return
+	}
+
+	router.pattern, router.paramInfos = compilePath(router.path)
 
 	var rePattern string
 	if router.include == nil { // 不是路由组
@@ -70,74 +86,117 @@ func (router *Router) compilePattern() {
 		rePattern = "^" + router.pattern
 	}
 	router.regex = regexp.MustCompile(rePattern)
-	router.paramInfos = paramInfos
 }
 
-// match 尝试匹配，返回 参数映射 与 是否匹配
+// matchesSlash 判断转换器正则是否可能匹配 '/'
+//
+// 可匹配 '/' 的转换器(如 path)会跨越多个路径片段，路由树中按路径节点处理
 //
 // 参数:
-//   - path string: 待匹配的URL路径
-//   - params Params: 匹配的参数映射
+//   - converter *Converter: 转换器
 //
 // 返回:
-//   - Middlewares: 匹配的路由中间件
-//   - bool: 是否匹配成功
-func (router Router) match(path string, params Params) (Middlewares, bool) {
-	// 保护：确保已编译正则，避免空指针
-	if router.regex == nil {
-		(&router).compilePattern()
+//   - bool: 是否可能匹配 '/'
+func matchesSlash(converter *Converter) bool {
+	re, err := syntax.Parse(converter.Regex, syntax.Perl)
+	if err != nil {
+		return true
 	}
-	loc := router.regex.FindStringSubmatch(path)
-	if loc == nil || len(loc)-1 != len(router.paramInfos) {
-		return nil, false
-	}
-	if len(router.paramInfos) > 0 {
-		// 第0个是完整匹配，从1开始依次为各参数
-		for i, paramInfo := range router.paramInfos {
-			// i 对应第 i+1 个分组
-			if i+1 < len(loc) {
-				rawValue := loc[i+1]
-				convertedValue, err := paramInfo.Converter.ToGo(rawValue)
-				if err != nil {
-					return nil, false
+	var walk func(re *syntax.Regexp) bool
+	walk = func(re *syntax.Regexp) bool {
+		switch re.Op {
+		case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+			return true
+		case syntax.OpLiteral:
+			for _, r := range re.Rune {
+				if r == '/' {
+					return true
+				}
+			}
+		case syntax.OpCharClass:
+			for i := 0; i+1 < len(re.Rune); i += 2 {
+				if re.Rune[i] <= '/' && '/' <= re.Rune[i+1] {
+					return true
 				}
-				params[paramInfo.Name] = convertedValue
 			}
 		}
+		for _, sub := range re.Sub {
+			if walk(sub) {
+				return true
+			}
+		}
+		return false
 	}
-	return router.middlewares, true
+	return walk(re)
 }
 
-// resolve 解析URL路径
+// matchParams 使用正则匹配值并转换参数
 //
 // 参数:
-//   - Path string: 待解析的URL路径
-//   - params Params: 匹配的参数映射
+//   - regex *regexp.Regexp: 匹配正则
+//   - paramInfos []ParamInfo: 参数信息列表
+//   - value string: 待匹配的值
+//   - params []routeParam: 已匹配的参数列表
 //
 // 返回:
-//   - *ViewSet: 匹配的视图集
-//   - Middlewares: 匹配的路由中间件
+//   - []routeParam: 追加后的参数列表
 //   - bool: 是否匹配成功
-func (router Router) resolve(Path string, params Params) (*ViewSet, Middlewares, bool) {
-	middlewares, ok := router.match(Path, params)
-	if ok == false {
-		return nil, nil, false
+func matchParams(regex *regexp.Regexp, paramInfos []ParamInfo, value string, params []routeParam) ([]routeParam, bool) {
+	loc := regex.FindStringSubmatch(value)
+	if loc == nil || len(loc)-1 != len(paramInfos) {
+		return params, false
 	}
-	if router.include == nil {
-		return &router.viewSet, middlewares, true
+	// 第0个是完整匹配，从1开始依次为各参数
+	for i, paramInfo := range paramInfos {
+		convertedValue, err := paramInfo.Converter.ToGo(loc[i+1])
+		if err != nil {
+			return params, false
+		}
+		params = append(params, routeParam{name: paramInfo.Name, value: convertedValue})
 	}
-	// 子路由
-	for _, itemRouter := range router.include {
-		viewSet, middlewaresChild, isPattern := itemRouter.resolve(Path[len(router.path):], params)
-		if isPattern == true {
-			// 合并中间件
-			middlewares = append(middlewares, middlewaresChild...)
-			return viewSet, middlewares, isPattern
+	return params, true
+}
+
+// resolve 解析URL路径
+//
+// 先按主机路由组匹配 Host，匹配的主机路由组中无匹配路由时回退到默认路由
+//
+// 参数:
+//   - host string: 请求 Host，可包含端口
+//   - Path string: 待解析的URL路径
+//   - params Params: 匹配的参数映射，主机参数与路由参数合并写入
+//
+// 返回:
+//   - *routeEntry: 匹配的路由项，未匹配返回 nil
+func (router *Router) resolve(host string, Path string, params Params) *routeEntry {
+	table := router.getTable()
+
+	var entry *routeEntry
+	var routeParams []routeParam
+	if len(table.hosts) > 0 {
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
 		}
+		for _, hostTable := range table.hosts {
+			hostParams, ok := matchParams(hostTable.regex, hostTable.paramInfos, host, nil)
+			if !ok {
+				continue
+			}
+			entry, routeParams = hostTable.tree.find(Path)
+			if entry != nil {
+				routeParams = append(hostParams, routeParams...)
+			}
+			break
+		}
+	}
+	if entry == nil {
+		entry, routeParams = table.tree.find(Path)
+	}
+	if entry == nil {
+		return nil
 	}
-	// 无匹配
-	if router.noRoute != nil {
-		return router.noRoute, middlewares, true
+	for _, param := range routeParams {
+		params[param.name] = param.value
 	}
-	return nil, nil, false
+	return entry
 }
@@ -0,0 +1,68 @@
+package goi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
+)
+
+// Reverse 根据路由名称反向解析 URL
+//
+// 参数:
+//   - name string: 路由名称，通过 Name 设置
+//   - params Params: 路由参数，键为 <type:name> 中的 name
+//
+// 返回:
+//   - string: 完整 URL 路径，包含所有上级路由组前缀
+//   - error: 路由不存在、缺少参数或参数不匹配转换器时返回错误
+//
+// 说明:
+//   - 参数值使用 fmt.Sprint 格式化后按转换器正则校验
+//   - 参数值会进行路径转义，path 类型参数保留 '/'
+func (router *Router) Reverse(name string, params Params) (string, error) {
+	entry, ok := router.getTable().names[name]
+	if !ok {
+		reverseNameNotExistsMsg := i18n.T("router.reverse_name_not_exists", map[string]any{
+			"name": name,
+		})
+		return "", errors.New(reverseNameNotExistsMsg)
+	}
+
+	matches := placeholderRegex.FindAllStringIndex(entry.path, -1)
+	urlBuilder := strings.Builder{}
+	last := 0
+	for i, loc := range matches {
+		urlBuilder.WriteString(entry.path[last:loc[0]])
+		last = loc[1]
+
+		paramInfo := entry.paramInfos[i]
+		value, ok := params[paramInfo.Name]
+		if !ok || value == nil {
+			reverseParamRequiredMsg := i18n.T("router.reverse_param_required", map[string]any{
+				"name":  name,
+				"param": paramInfo.Name,
+			})
+			return "", errors.New(reverseParamRequiredMsg)
+		}
+		valueStr := fmt.Sprint(value)
+		if !entry.regexes[i].MatchString(valueStr) {
+			reverseParamInvalidMsg := i18n.T("router.reverse_param_invalid", map[string]any{
+				"name":    name,
+				"param":   paramInfo.Name,
+				"value":   valueStr,
+				"pattern": paramInfo.Converter.Regex,
+			})
+			return "", errors.New(reverseParamInvalidMsg)
+		}
+		segments := strings.Split(valueStr, "/")
+		for j, segment := range segments {
+			segments[j] = url.PathEscape(segment)
+		}
+		urlBuilder.WriteString(strings.Join(segments, "/"))
+	}
+	urlBuilder.WriteString(entry.path[last:])
+	return urlBuilder.String(), nil
+}
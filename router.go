@@ -6,23 +6,37 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
 )
 
 // 路由表
 type Router struct {
-	path        string      // 路由
-	desc        string      // 描述
-	viewSet     ViewSet     // 视图方法
-	noRoute     *ViewSet    // 无路由视图
-	include     []*Router   // 子路由
-	middlewares Middlewares // 路由中间件
+	path        string        // 路由
+	name        string        // 路由名称，用于反向解析
+	desc        string        // 描述
+	viewSet     ViewSet       // 视图方法
+	noRoute     *ViewSet      // 无路由视图
+	include     []*Router     // 子路由
+	middlewares Middlewares   // 路由中间件
+	timeout     time.Duration // 路由超时时间，0 表示继承上级路由
+	renderers   []string      // 可协商的响应 MIME 类型，为空表示继承上级路由
 
 	// 预编译
 	pattern    string         // 路由正则表达式
 	regex      *regexp.Regexp // 路由正则表达式匹配对象
 	paramInfos []ParamInfo    // 参数信息列表
+
+	// 主机路由组
+	host           string         // 主机，如 "<slug:tenant>.example.com"
+	hostRegex      *regexp.Regexp // 主机匹配正则
+	hostParamInfos []ParamInfo    // 主机参数信息列表
+	hosts          []*Router      // 主机路由组，仅根路由使用
+
+	parent *Router                    // 父路由
+	table  atomic.Pointer[routeTable] // 路由查找表，仅根路由使用，注册变更后重新编译
 }
 
 // 创建路由
@@ -44,7 +58,7 @@ func newRouter() *Router {
 
 // hasChildRouter 判断是否包含指定子路由（通过指针引用校验）
 // 返回 true 表示当前路由的 include 中已包含该子路由
-func (router Router) hasChildRouter(child *Router) {
+func (router *Router) hasChildRouter(child *Router) {
 	if router.include == nil || child == nil {
 		return
 	}
@@ -65,6 +79,165 @@ func (router Router) hasChildRouter(child *Router) {
 	}
 }
 
+// addChild 编译并添加子路由
+//
+// 参数:
+//   - child *Router: 子路由
+func (router *Router) addChild(child *Router) {
+	child.compilePattern()
+	router.hasChildRouter(child)
+	child.parent = router
+	router.include = append(router.include, child)
+	router.invalidate()
+}
+
+// invalidate 注册变更后清除根路由已编译的路由树
+func (router *Router) invalidate() {
+	router.root().table.Store(nil)
+}
+
+// root 获取根路由
+//
+// 返回:
+//   - *Router: 根路由
+func (router *Router) root() *Router {
+	root := router
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root
+}
+
+// getTable 获取路由查找表，未编译时编译
+//
+// 返回:
+//   - *routeTable: 路由查找表
+func (router *Router) getTable() *routeTable {
+	root := router.root()
+	table := root.table.Load()
+	if table == nil {
+		table = root.compile()
+		root.table.Store(table)
+	}
+	return table
+}
+
+// Name 设置路由名称，用于 Reverse 反向解析
+//
+// 参数:
+//   - name string: 路由名称，在整个路由表中唯一
+//
+// 返回:
+//   - *Router: 当前路由实例
+func (router *Router) Name(name string) *Router {
+	if router.name == name {
+		return router
+	}
+	if name != "" && router.root().findName(name) != nil {
+		nameAlreadyExistsMsg := i18n.T("router.name_already_exists", map[string]any{
+			"name": name,
+		})
+		panic(nameAlreadyExistsMsg)
+	}
+	router.name = name
+	router.invalidate()
+	return router
+}
+
+// Timeout 设置路由超时时间，作用于当前路由及其子路由，子路由可覆盖
+//
+// 参数:
+//   - timeout time.Duration: 超时时间，0 表示继承上级路由
+//
+// 返回:
+//   - *Router: 当前路由实例
+//
+// 说明:
+//   - 超时后取消请求上下文（Request.Context()）并返回 504，客户端断开时返回 503，响应经过中间件的 ProcessResponse
+//   - 视图仍在后台运行直至返回，应通过请求上下文及时退出，如使用数据库引擎的 *Context 方法
+func (router *Router) Timeout(timeout time.Duration) *Router {
+	router.timeout = timeout
+	router.invalidate()
+	return router
+}
+
+// Renderers 限制响应数据可协商的 MIME 类型，作用于当前路由及其子路由，子路由可覆盖
+//
+// 参数:
+//   - mediaTypes ...string: MIME 类型，按偏好排列，例如 parser.MIMEJSON、parser.MIMEXML，为空表示继承上级路由
+//
+// 返回:
+//   - *Router: 当前路由实例
+//
+// 说明:
+//   - MIME 类型需已通过 render.RegisterRenderer 注册，默认可协商全部已注册类型
+//   - Accept 与 ?format= 均不匹配时返回 406
+func (router *Router) Renderers(mediaTypes ...string) *Router {
+	router.renderers = mediaTypes
+	router.invalidate()
+	return router
+}
+
+// findName 查找指定名称的路由
+//
+// 参数:
+//   - name string: 路由名称
+//
+// 返回:
+//   - *Router: 路由，不存在返回 nil
+func (router *Router) findName(name string) *Router {
+	if router.name == name {
+		return router
+	}
+	for _, itemRouter := range router.include {
+		if found := itemRouter.findName(name); found != nil {
+			return found
+		}
+	}
+	for _, hostRouter := range router.hosts {
+		if found := hostRouter.findName(name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// Host 创建一个主机路由组，按请求 Host 匹配
+//
+// 参数:
+//   - host string: 主机，支持占位符 <type:name>，如 "<slug:tenant>.example.com"
+//   - desc string: 描述
+//
+// 返回:
+//   - *Router: 主机路由组，与根路由一样以 "/" 为路径注册子路由
+//
+// 说明:
+//   - 主机路由组注册在根路由上，根路由中间件在主机路由组中间件之前执行
+//   - 匹配时忽略端口与大小写，无参数的主机优先于含参数的主机
+//   - 主机参数与路由参数一同写入 PathParams
+//   - 主机路由组中无匹配路由时回退到默认路由
+func (router *Router) Host(host string, desc string) *Router {
+	root := router.root()
+	for _, hostRouter := range root.hosts {
+		if strings.EqualFold(hostRouter.host, host) {
+			hostAlreadyExistsMsg := i18n.T("router.host_already_exists", map[string]any{
+				"host": host,
+			})
+			panic(hostAlreadyExistsMsg)
+		}
+	}
+	pattern, paramInfos := compilePath(host)
+	hostRouter := newRouter()
+	hostRouter.desc = desc
+	hostRouter.host = host
+	hostRouter.hostRegex = regexp.MustCompile("(?i)^" + pattern + "$")
+	hostRouter.hostParamInfos = paramInfos
+	hostRouter.parent = root
+	root.hosts = append(root.hosts, hostRouter)
+	root.invalidate()
+	return hostRouter
+}
+
 // Include 创建一个子路由
 //
 // 参数:
@@ -85,9 +258,7 @@ func (router *Router) Include(path string, desc string) *Router {
 		include:     make([]*Router, 0, 0),
 		middlewares: nil,
 	}
-	includeRouter.compilePattern()
-	router.hasChildRouter(includeRouter)
-	router.include = append(router.include, includeRouter)
+	router.addChild(includeRouter)
 	return includeRouter
 }
 
@@ -100,6 +271,7 @@ func (router *Router) Include(path string, desc string) *Router {
 //   - *Router: 当前路由实例
 func (router *Router) Use(middleware ...Middleware) *Router {
 	router.middlewares = append(router.middlewares, middleware...)
+	router.invalidate()
 	return router
 }
 
@@ -109,7 +281,10 @@ func (router *Router) Use(middleware ...Middleware) *Router {
 //   - path: string 路由
 //   - desc: string 描述
 //   - viewSet: ViewSet 视图方法
-func (router *Router) Path(path string, desc string, viewSet ViewSet) {
+//
+// 返回:
+//   - *Router: 注册的路由实例，可链式调用 Name 设置路由名称
+func (router *Router) Path(path string, desc string, viewSet ViewSet) *Router {
 	includeRouter := &Router{
 		path:        path,
 		desc:        desc,
@@ -118,9 +293,8 @@ func (router *Router) Path(path string, desc string, viewSet ViewSet) {
 		include:     nil,
 		middlewares: nil,
 	}
-	includeRouter.compilePattern()
-	router.hasChildRouter(includeRouter)
-	router.include = append(router.include, includeRouter)
+	router.addChild(includeRouter)
+	return includeRouter
 }
 
 // StaticFile 注册静态文件路由
@@ -129,7 +303,10 @@ func (router *Router) Path(path string, desc string, viewSet ViewSet) {
 //   - path: string 路由
 //   - desc: string 描述
 //   - filePath: string 文件路径
-func (router *Router) StaticFile(path string, desc string, filePath string) {
+//
+// 返回:
+//   - *Router: 注册的路由实例，可链式调用 Name 设置路由名称
+func (router *Router) StaticFile(path string, desc string, filePath string) *Router {
 	view := StaticFileView(filePath)
 	includeRouter := &Router{
 		path: path,
@@ -142,9 +319,8 @@ func (router *Router) StaticFile(path string, desc string, filePath string) {
 		include:     nil,
 		middlewares: nil,
 	}
-	includeRouter.compilePattern()
-	router.hasChildRouter(includeRouter)
-	router.include = append(router.include, includeRouter)
+	router.addChild(includeRouter)
+	return includeRouter
 }
 
 // StaticDir 注册静态目录路由
@@ -153,7 +329,10 @@ func (router *Router) StaticFile(path string, desc string, filePath string) {
 //   - path: string 路由
 //   - desc: string 描述
 //   - dirPath: http.Dir 静态映射路径
-func (router *Router) StaticDir(path string, desc string, dirPath http.Dir) {
+//
+// 返回:
+//   - *Router: 注册的路由实例，可链式调用 Name 设置路由名称
+func (router *Router) StaticDir(path string, desc string, dirPath http.Dir) *Router {
 	if dirPath == "" {
 		dirPath = "."
 	}
@@ -173,9 +352,8 @@ func (router *Router) StaticDir(path string, desc string, dirPath http.Dir) {
 		include:     nil,
 		middlewares: nil,
 	}
-	includeRouter.compilePattern()
-	router.hasChildRouter(includeRouter)
-	router.include = append(router.include, includeRouter)
+	router.addChild(includeRouter)
+	return includeRouter
 }
 
 // StaticFileFS 注册 embed.FS 静态文件路由
@@ -185,7 +363,10 @@ func (router *Router) StaticDir(path string, desc string, dirPath http.Dir) {
 //   - desc: string 描述
 //   - fileFS: embed.FS 嵌入式文件系统
 //   - defaultPath: string 嵌入文件默认路径
-func (router *Router) StaticFileFS(path string, desc string, fileFS embed.FS, defaultPath string) {
+//
+// 返回:
+//   - *Router: 注册的路由实例，可链式调用 Name 设置路由名称
+func (router *Router) StaticFileFS(path string, desc string, fileFS embed.FS, defaultPath string) *Router {
 	view := StaticFileFSView(fileFS, defaultPath)
 	includeRouter := &Router{
 		path: path,
@@ -198,9 +379,8 @@ func (router *Router) StaticFileFS(path string, desc string, fileFS embed.FS, de
 		include:     nil,
 		middlewares: nil,
 	}
-	includeRouter.compilePattern()
-	router.hasChildRouter(includeRouter)
-	router.include = append(router.include, includeRouter)
+	router.addChild(includeRouter)
+	return includeRouter
 }
 
 // StaticDirFS 注册 embed.FS 静态目录路由
@@ -211,10 +391,13 @@ func (router *Router) StaticFileFS(path string, desc string, fileFS embed.FS, de
 //   - dirFS: embed.FS 嵌入式文件系统
 //   - basePath: string 嵌入文件基础路径
 //
+// 返回:
+//   - *Router: 注册的路由实例，可链式调用 Name 设置路由名称
+//
 // 说明:
 //   - path 路径之后自动添加 /<path:fileName> 参数
 //   - 以 basePath 作为根锚定嵌入式文件系统，再以 fileName 查找文件
-func (router *Router) StaticDirFS(path string, desc string, dirFS embed.FS, basePath string) {
+func (router *Router) StaticDirFS(path string, desc string, dirFS embed.FS, basePath string) *Router {
 	if strings.HasSuffix(path, "/") == false {
 		path = path + "/"
 	}
@@ -239,9 +422,8 @@ func (router *Router) StaticDirFS(path string, desc string, dirFS embed.FS, base
 		include:     nil,
 		middlewares: nil,
 	}
-	includeRouter.compilePattern()
-	router.hasChildRouter(includeRouter)
-	router.include = append(router.include, includeRouter)
+	router.addChild(includeRouter)
+	return includeRouter
 }
 
 // NoRoute 注册未匹配路由时的处理视图，默认返回 404
@@ -250,16 +432,22 @@ func (router *Router) StaticDirFS(path string, desc string, dirFS embed.FS, base
 //   - viewSet: ViewSet 视图方法
 func (router *Router) NoRoute(viewSet ViewSet) {
 	router.noRoute = &viewSet
+	router.invalidate()
 }
 
 // Route 为 Router 路由的副本
 type Route struct {
-	Path     string      // 路由
-	Desc     string      // 描述
-	ViewSet  ViewSet     // 视图方法
-	NoRoute  *ViewSet    // 无路由视图
-	Include  []Route     // 子路由
-	Handlers Middlewares // 路由中间件
+	Host      string        // 主机
+	Path      string        // 路由
+	Name      string        // 路由名称
+	Desc      string        // 描述
+	ViewSet   ViewSet       // 视图方法
+	NoRoute   *ViewSet      // 无路由视图
+	Include   []Route       // 子路由
+	Hosts     []Route       // 主机路由组
+	Handlers  Middlewares   // 路由中间件
+	Timeout   time.Duration // 路由超时时间
+	Renderers []string      // 可协商的响应 MIME 类型
 
 	// 预编译
 	Pattern    string         // 路由正则表达式
@@ -271,7 +459,7 @@ type Route struct {
 //
 // 返回:
 //   - Route: 路由信息
-func (router Router) GetRoute() Route {
+func (router *Router) GetRoute() Route {
 	var children []Route
 	if len(router.include) > 0 {
 		children = make([]Route, 0, len(router.include))
@@ -279,13 +467,25 @@ func (router Router) GetRoute() Route {
 			children = append(children, itemRouter.GetRoute())
 		}
 	}
+	var hosts []Route
+	if len(router.hosts) > 0 {
+		hosts = make([]Route, 0, len(router.hosts))
+		for _, hostRouter := range router.hosts {
+			hosts = append(hosts, hostRouter.GetRoute())
+		}
+	}
 	return Route{
+		Host:       router.host,
 		Path:       router.path,
+		Name:       router.name,
 		Desc:       router.desc,
 		ViewSet:    router.viewSet,
 		NoRoute:    router.noRoute,
 		Include:    children,
+		Hosts:      hosts,
 		Handlers:   router.middlewares,
+		Timeout:    router.timeout,
+		Renderers:  router.renderers,
 		Pattern:    router.pattern,
 		Regex:      router.regex,
 		ParamInfos: router.paramInfos,
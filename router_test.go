@@ -0,0 +1,244 @@
+package goi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NeverStopDreamingWang/goi/v2/parser"
+)
+
+// testMiddleware 记录名称的空中间件
+type testMiddleware string
+
+func (middleware testMiddleware) ProcessRequest(request *Request) any { return nil }
+
+func (middleware testMiddleware) ProcessException(request *Request, exception any) any { return nil }
+
+func (middleware testMiddleware) ProcessResponse(request *Request, response *Response) {}
+
+// viewNamed 返回一个以 name 作为响应数据的视图集
+func viewNamed(name string) ViewSet {
+	return ViewSet{GET: func(request *Request) any { return name }}
+}
+
+// resolveName 解析路径并返回视图名称
+func resolveName(t *testing.T, router *Router, path string) (string, Params, Middlewares) {
+	t.Helper()
+	params := make(Params)
+	entry := router.resolve("", path, params)
+	if entry == nil {
+		return "", params, nil
+	}
+	return entry.viewSet.GET(nil).(string), params, entry.middlewares
+}
+
+// TestRouterResolvePriority 静态片段优先于参数片段，参数片段优先于路径片段，与注册顺序无关
+func TestRouterResolvePriority(t *testing.T) {
+	router := newRouter()
+	userRouter := router.Include("user", "用户")
+	userRouter.Path("<path:rest>", "路径", viewNamed("path"))
+	userRouter.Path("<int:id>", "参数", viewNamed("param"))
+	userRouter.Path("me", "静态", viewNamed("static"))
+	userRouter.Path("<int:id>/profile", "参数子路径", viewNamed("profile"))
+
+	cases := []struct {
+		path string
+		want string
+	}{
+		{"/user/me", "static"},
+		{"/user/5", "param"},
+		{"/user/5/profile", "profile"},
+		{"/user/abc", "path"},
+		{"/user/5/other", "path"},
+	}
+	for _, c := range cases {
+		got, _, _ := resolveName(t, router, c.path)
+		if got != c.want {
+			t.Errorf("resolve(%q) = %q, want %q", c.path, got, c.want)
+		}
+	}
+}
+
+// TestRouterResolveParams 验证参数转换与路由组参数
+func TestRouterResolveParams(t *testing.T) {
+	router := newRouter()
+	articleRouter := router.Include("user/<int:uid>", "用户文章")
+	articleRouter.Path("article/<slug:slug>", "文章", viewNamed("article"))
+	router.StaticDir("static", "静态目录", "")
+
+	name, params, _ := resolveName(t, router, "/user/7/article/hello-world")
+	if name != "article" {
+		t.Fatalf("resolve article = %q", name)
+	}
+	if params["uid"] != 7 || params["slug"] != "hello-world" {
+		t.Fatalf("unexpected params: %v", params)
+	}
+
+	params = make(Params)
+	entry := router.resolve("", "/static/css/app.css", params)
+	if entry == nil || params["fileName"] != "css/app.css" {
+		t.Fatalf("unexpected static params: %v", params)
+	}
+
+	if name, _, _ = resolveName(t, router, "/user/abc/article/x"); name != "" {
+		t.Fatalf("expected no match, got %q", name)
+	}
+}
+
+// TestRouterResolveMiddlewaresAndNoRoute 验证中间件合并与无路由回退
+func TestRouterResolveMiddlewaresAndNoRoute(t *testing.T) {
+	router := newRouter()
+	router.Use(testMiddleware("root"))
+	router.NoRoute(viewNamed("root-404"))
+	apiRouter := router.Include("api", "接口")
+	apiRouter.Use(testMiddleware("api"))
+	apiRouter.Path("ping", "ping", viewNamed("ping"))
+	v1Router := apiRouter.Include("v1", "v1")
+	v1Router.NoRoute(viewNamed("v1-404"))
+
+	name, _, middlewares := resolveName(t, router, "/api/ping")
+	if name != "ping" {
+		t.Fatalf("resolve ping = %q", name)
+	}
+	if len(middlewares) != 2 || middlewares[0] != testMiddleware("root") || middlewares[1] != testMiddleware("api") {
+		t.Fatalf("unexpected middlewares: %v", middlewares)
+	}
+
+	if name, _, middlewares = resolveName(t, router, "/api/v1/missing"); name != "v1-404" || len(middlewares) != 2 {
+		t.Fatalf("resolve v1 missing = %q %v", name, middlewares)
+	}
+	if name, _, _ = resolveName(t, router, "/api/missing"); name != "root-404" {
+		t.Fatalf("resolve api missing = %q", name)
+	}
+
+	// 注册变更后路由树重新编译
+	v1Router.Path("ping", "ping", viewNamed("v1-ping"))
+	if name, _, _ = resolveName(t, router, "/api/v1/ping"); name != "v1-ping" {
+		t.Fatalf("resolve v1 ping = %q", name)
+	}
+}
+
+// TestRouterReverse 验证按路由名称反向解析
+func TestRouterReverse(t *testing.T) {
+	router := newRouter()
+	userRouter := router.Include("api/<slug:version>", "接口")
+	userRouter.Path("user/<int:id>", "用户详情", viewNamed("user")).Name("user-detail")
+	router.StaticDir("static", "静态目录", "").Name("static")
+
+	url, err := router.Reverse("user-detail", Params{"version": "v1", "id": 5})
+	if err != nil || url != "/api/v1/user/5" {
+		t.Fatalf("Reverse user-detail = %q, %v", url, err)
+	}
+	url, err = userRouter.Reverse("static", Params{"fileName": "css/a b.css"})
+	if err != nil || url != "/static/css/a%20b.css" {
+		t.Fatalf("Reverse static = %q, %v", url, err)
+	}
+	if _, err = router.Reverse("user-detail", Params{"version": "v1", "id": "abc"}); err == nil {
+		t.Fatal("expected invalid param error")
+	}
+	if _, err = router.Reverse("user-detail", Params{"id": 5}); err == nil {
+		t.Fatal("expected missing param error")
+	}
+	if _, err = router.Reverse("missing", nil); err == nil {
+		t.Fatal("expected missing name error")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected duplicate name panic")
+		}
+	}()
+	router.Path("other", "其他", viewNamed("other")).Name("user-detail")
+}
+
+// TestRouterResolveHost 验证主机路由组匹配、主机参数与回退默认路由
+func TestRouterResolveHost(t *testing.T) {
+	router := newRouter()
+	router.Use(testMiddleware("root"))
+	router.Path("ping", "默认", viewNamed("default-ping"))
+	router.Path("about", "默认", viewNamed("default-about"))
+	tenantRouter := router.Host("<slug:tenant>.example.com", "租户")
+	tenantRouter.Use(testMiddleware("tenant"))
+	tenantRouter.Path("ping", "租户", viewNamed("tenant-ping"))
+	apiRouter := router.Host("api.example.com", "接口")
+	apiRouter.Path("ping", "接口", viewNamed("api-ping"))
+
+	cases := []struct {
+		host   string
+		path   string
+		want   string
+		tenant any
+	}{
+		{"acme.example.com", "/ping", "tenant-ping", "acme"},
+		{"ACME.example.com:8080", "/ping", "tenant-ping", "ACME"},
+		{"api.example.com", "/ping", "api-ping", nil},
+		{"acme.example.com", "/about", "default-about", nil},
+		{"other.org", "/ping", "default-ping", nil},
+	}
+	for _, c := range cases {
+		params := make(Params)
+		entry := router.resolve(c.host, c.path, params)
+		if entry == nil {
+			t.Fatalf("resolve(%q, %q) not matched", c.host, c.path)
+		}
+		if got := entry.viewSet.GET(nil); got != c.want || params["tenant"] != c.tenant {
+			t.Errorf("resolve(%q, %q) = %v %v, want %v %v", c.host, c.path, got, params["tenant"], c.want, c.tenant)
+		}
+		if c.want == "tenant-ping" && len(entry.middlewares) != 2 {
+			t.Errorf("unexpected tenant middlewares: %v", entry.middlewares)
+		}
+	}
+}
+
+// TestRouterRenderers 验证响应内容协商、路由限制继承与 406
+func TestRouterRenderers(t *testing.T) {
+	engine := NewHTTPServer()
+	engine.Log = NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer engine.Log.File.Close()
+
+	view := ViewSet{GET: func(request *Request) any { return map[string]string{"name": "goi"} }}
+	engine.Router.Path("item", "全部格式", view)
+	apiRouter := engine.Router.Include("api", "接口").Renderers(parser.MIMEJSON)
+	apiRouter.Path("item", "仅 JSON", view)
+	apiRouter.Path("text", "文本", ViewSet{GET: func(request *Request) any { return "text" }})
+
+	serve := func(target string, accept string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := serve("/item", "application/xml")
+	if recorder.Header().Get(ContentType) != parser.MIMEXML || !strings.Contains(recorder.Body.String(), "<name>goi</name>") {
+		t.Errorf("xml: %q %q", recorder.Header().Get(ContentType), recorder.Body.String())
+	}
+	if recorder.Header().Get("Vary") != "Accept" {
+		t.Errorf("Vary = %q", recorder.Header().Get("Vary"))
+	}
+	if recorder = serve("/item?format=yaml", "application/json"); recorder.Header().Get(ContentType) != parser.MIMEYAML || recorder.Body.String() != "name: goi\n" {
+		t.Errorf("yaml: %q %q", recorder.Header().Get(ContentType), recorder.Body.String())
+	}
+	if recorder = serve("/item", ""); recorder.Body.String() != `{"name":"goi"}` {
+		t.Errorf("default: %q", recorder.Body.String())
+	}
+	// 浏览器默认 Accept
+	if recorder = serve("/item", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,*/*;q=0.8"); recorder.Body.String() != `{"name":"goi"}` {
+		t.Errorf("browser: %q %q", recorder.Header().Get(ContentType), recorder.Body.String())
+	}
+	if recorder = serve("/api/item", "application/xml"); recorder.Code != http.StatusNotAcceptable {
+		t.Errorf("restricted route status = %d", recorder.Code)
+	}
+	if recorder = serve("/api/item", "application/*"); recorder.Header().Get(ContentType) != parser.MIMEJSON {
+		t.Errorf("restricted route Content-Type = %q", recorder.Header().Get(ContentType))
+	}
+	if recorder = serve("/api/text", "application/xml"); recorder.Code != http.StatusOK || recorder.Body.String() != "text" {
+		t.Errorf("string response negotiated: %d %q", recorder.Code, recorder.Body.String())
+	}
+}
@@ -2,14 +2,15 @@ package goi
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -23,23 +24,49 @@ var Log *Logger
 var Validation = newValidation()
 
 var serverChan = make(chan os.Signal, 1)
+var reloadChan = make(chan os.Signal, 1)
 
 // Engine 实现 ServeHTTP 接口
 type Engine struct {
-	startTime  *time.Time  // 启动时间
-	server     http.Server // net/http 服务
-	Router     *Router     // 路由
-	Settings   *settings   // 设置
-	Cache      *cache      // 缓存
-	Log        *Logger     // 日志
-	Validation *validation // 验证管理器
+	startTime    *time.Time          // 启动时间
+	lock         sync.Mutex          // 保护 started、servers、addrs、certificates、startups 与 callbacks
+	started      bool                // 是否已启动
+	servers      []*http.Server      // 各监听器的 net/http 服务
+	addrs        []net.Addr          // 各监听器的实际监听地址
+	certificates []*certificateStore // 各监听器的 SSL 证书
+	startups     []Startup           // 该 Engine 的后台任务
+	callbacks    []ShutdownCallback  // 该 Engine 的关闭回调
+	serveErr     chan error          // 监听器异常退出时的错误信息
+	taskCtx      context.Context     // 后台任务上下文，关闭服务时取消
+	taskCancel   context.CancelFunc  // 取消 taskCtx
+	taskWait     sync.WaitGroup      // 等待后台任务退出
+	closing      context.Context     // 开始关闭服务时取消，通知事件流等长连接退出
+	stopClose    context.CancelFunc  // 取消 closing
+	webSockets   *webSocketManager   // WebSocket 连接管理器
+	stopOnce     sync.Once           // 确保关闭流程只执行一次
+	stopErr      error               // 关闭流程的错误信息
+	stopped      chan struct{}       // 关闭流程结束时关闭
+	Router       *Router             // 路由
+	Settings     *settings           // 设置
+	Cache        *cache              // 缓存
+	Log          *Logger             // 日志
+	Validation   *validation         // 验证管理器
 }
 
 // 创建一个 Http 服务
 func NewHTTPServer() *Engine {
+	taskCtx, taskCancel := context.WithCancel(context.Background())
+	closing, stopClose := context.WithCancel(context.Background())
 	return &Engine{
 		startTime:  nil,
-		server:     http.Server{},
+		servers:    nil,
+		serveErr:   make(chan error, 1),
+		taskCtx:    taskCtx,
+		taskCancel: taskCancel,
+		closing:    closing,
+		stopClose:  stopClose,
+		webSockets: newWebSocketManager(),
+		stopped:    make(chan struct{}),
 		Router:     newRouter(),
 		Settings:   Settings,
 		Cache:      Cache,
@@ -49,7 +76,93 @@ func NewHTTPServer() *Engine {
 }
 
 // 启动 http 服务
+//
+// 说明:
+//   - 阻塞直至服务停止，收到 Interrupt、Kill 或 SIGTERM 信号时按 Settings.Shutdown 关闭服务
+//   - 关闭过程中再次收到信号时强制退出
+//   - 收到 SIGHUP 信号时重新加载 SSL 证书
+//   - 启动失败或监听器异常退出时记录日志并 panic，需要返回错误时使用 Start
 func (engine *Engine) RunServer() {
+	err := engine.Start(context.Background())
+	if err != nil {
+		engine.Log.Error(err)
+		panic(err)
+	}
+
+	// 注册关闭信号
+	signal.Notify(serverChan, os.Interrupt, os.Kill, syscall.SIGTERM)
+
+	// 收到 SIGHUP 信号时重新加载证书
+	signal.Notify(reloadChan, syscall.SIGHUP)
+	go func() {
+		for range reloadChan {
+			_ = engine.ReloadCertificates() // 错误已记录日志
+		}
+	}()
+
+	go func() {
+		// 等待关闭信号
+		sig := <-serverChan
+		switch sig {
+		case os.Kill, os.Interrupt, syscall.SIGTERM:
+			go func() {
+				_ = engine.StopServer() // 各阶段错误已记录日志
+			}()
+		default:
+			invalidOperationMsg := i18n.T("server.invalid_operation", map[string]any{
+				"name": sig,
+			})
+			panic(invalidOperationMsg)
+		}
+
+		// 关闭过程中再次收到信号时强制退出
+		sig = <-serverChan
+		forceExitMsg := i18n.T("server.shutdown.force_exit", map[string]any{
+			"name": sig,
+		})
+		engine.Log.Log(meta, forceExitMsg)
+		os.Exit(1)
+	}()
+
+	// 等待关闭流程结束
+	select {
+	case err = <-engine.serveErr:
+		engine.Log.Error(err)
+		panic(err)
+	case <-engine.stopped:
+	}
+}
+
+// Start 启动 http 服务，创建全部监听器后立即返回
+//
+// 参数:
+//   - ctx context.Context: 创建监听器使用的上下文，不影响启动后的服务
+//
+// 返回:
+//   - error: 已启动、网络协议无效、证书读取失败或监听失败时的错误信息
+//
+// 说明:
+//   - 不注册信号处理，由调用方通过 Shutdown 或 StopServer 关闭服务
+//   - 任一监听器创建失败时关闭已创建的监听器，不启动后台任务，修改设置后可重新启动
+//   - 启动成功后每个 Engine 只能启动一次
+//   - 监听器启动后异常退出的错误通过 Err 获取
+//
+// 示例:
+//
+//	err := Server.Start(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	defer Server.Shutdown(context.Background())
+func (engine *Engine) Start(ctx context.Context) error {
+	engine.lock.Lock()
+	if engine.started {
+		engine.lock.Unlock()
+		return errors.New(i18n.T("server.already_started"))
+	}
+	engine.started = true
+	engine.lock.Unlock()
+
 	startedMsg := i18n.T("server.started")
 	engine.Log.Log(meta, startedMsg)
 
@@ -86,155 +199,175 @@ func (engine *Engine) RunServer() {
 	})
 	engine.Log.Log(meta, logInfoMsg)
 
-	// 初始化缓存
-	engine.Cache.initCache()
+	// 创建全部监听器，任一监听器失败时不启动服务
+	listeners := engine.listeners()
+	lns := make([]net.Listener, 0, len(listeners))
+	servers := make([]*http.Server, 0, len(listeners))
+	addrs := make([]net.Addr, 0, len(listeners))
+	certificates := make([]*certificateStore, 0)
+	for _, listener := range listeners {
+		ln, store, err := listener.listen(ctx)
+		if err != nil {
+			for _, ln := range lns {
+				ln.Close()
+			}
+			// 启动失败后允许修改设置重新启动
+			engine.lock.Lock()
+			engine.started = false
+			engine.lock.Unlock()
+			return err
+		}
+		lns = append(lns, ln)
+		servers = append(servers, &http.Server{Handler: listener.handler(engine)})
+		addrs = append(addrs, ln.Addr())
+		if store != nil {
+			certificates = append(certificates, store)
+		}
+	}
+	engine.lock.Lock()
+	engine.servers = servers
+	engine.addrs = addrs
+	engine.certificates = certificates
+	engine.lock.Unlock()
+
+	// 复制全局注册的后台任务与关闭回调，缓存的任务与回调只注册到首个初始化缓存的 Engine
+	engine.copyRegistered()
+	engine.Cache.initCache(engine)
 
 	// 启动后台任务
-	for _, task := range startup.startups {
+	engine.lock.Lock()
+	startups := slices.Clone(engine.startups)
+	engine.lock.Unlock()
+	for _, task := range startups {
 		taskNameMsg := i18n.T("server.startup_task", map[string]any{
 			"name": task.StartupName(),
 		})
 		engine.Log.Log(meta, taskNameMsg)
-		startup.waitGroup.Add(1)
-		go task.OnStartup(startup.ctx, startup.waitGroup)
+		engine.taskWait.Add(1)
+		go task.OnStartup(engine.taskCtx, &engine.taskWait)
 	}
 
-	// 注册关闭信号
-	signal.Notify(serverChan, os.Interrupt, os.Kill, syscall.SIGTERM)
-
-	go func() {
-		// 等待关闭信号
-		sig := <-serverChan
-		switch sig {
-		case os.Kill, os.Interrupt, syscall.SIGTERM:
-			err := engine.StopServer()
-			if err != nil {
-				engine.Log.Error(err)
-				panic(err)
-			}
-		default:
-			invalidOperationMsg := i18n.T("server.invalid_operation", map[string]any{
-				"name": sig,
-			})
-			panic(invalidOperationMsg)
+	// 定期检查证书文件
+	for _, store := range certificates {
+		if store.ssl.ReloadInterval > 0 {
+			engine.taskWait.Add(1)
+			go engine.watchCertificate(engine.taskCtx, store, store.ssl.ReloadInterval)
 		}
-	}()
-
-	engine.server = http.Server{
-		Addr:    fmt.Sprintf("%v:%v", engine.Settings.BindAddress, engine.Settings.Port),
-		Handler: engine,
 	}
-	ln, err := net.Listen(engine.Settings.Network, engine.server.Addr)
-	if err != nil {
-		engine.Log.Error(err)
-		panic(err)
+
+	for i, ln := range lns {
+		listeners[i].logListen(engine, ln.Addr())
+		go engine.serve(servers[i], ln)
 	}
-	if engine.Settings.SSL.Enabled == true {
-		certificate, err := os.ReadFile(engine.Settings.SSL.CertPath)
-		if err != nil {
-			engine.Log.Error(err)
-			panic(err)
-		}
-		key, err := os.ReadFile(engine.Settings.SSL.KeyPath)
-		if err != nil {
-			engine.Log.Error(err)
-			panic(err)
-		}
-		cert, err := tls.X509KeyPair(certificate, key)
-		if err != nil {
-			engine.Log.Error(err)
-			panic(err)
-		}
-		engine.server.TLSConfig = &tls.Config{
-			Certificates: []tls.Certificate{cert},
-		}
+	return nil
+}
 
-		listenAddressMsg := i18n.T("server.listen_address", map[string]any{
-			"bind_address": fmt.Sprintf("https://%v:%v [%v]", engine.Settings.BindAddress, engine.Settings.Port, engine.Settings.Network),
-		})
-		engine.Log.Log(meta, listenAddressMsg)
-		if engine.Settings.BindDomain != "" {
-			listenDomainMsg := i18n.T("server.listen_address", map[string]any{
-				"bind_address": fmt.Sprintf("https://%v:%v [%v]", engine.Settings.BindDomain, engine.Settings.Port, engine.Settings.Network),
-			})
-			engine.Log.Log(meta, listenDomainMsg)
-		}
-		err = engine.server.ServeTLS(ln, engine.Settings.SSL.CertPath, engine.Settings.SSL.KeyPath)
-	} else {
-		listenAddressMsg := i18n.T("server.listen_address", map[string]any{
-			"bind_address": fmt.Sprintf("http://%v:%v [%v]", engine.Settings.BindAddress, engine.Settings.Port, engine.Settings.Network),
-		})
-		engine.Log.Log(meta, listenAddressMsg)
-		if engine.Settings.BindDomain != "" {
-			listenDomainMsg := i18n.T("server.listen_address", map[string]any{
-				"bind_address": fmt.Sprintf("http://%v:%v [%v]", engine.Settings.BindDomain, engine.Settings.Port, engine.Settings.Network),
-			})
-			engine.Log.Log(meta, listenDomainMsg)
+// serve 在监听器上提供服务，异常退出时将错误发送到 serveErr
+//
+// 参数:
+//   - server *http.Server: net/http 服务
+//   - ln net.Listener: 网络监听
+func (engine *Engine) serve(server *http.Server, ln net.Listener) {
+	err := server.Serve(ln)
+	if err != nil && err != http.ErrServerClosed {
+		select {
+		case engine.serveErr <- err:
+		default:
 		}
-		err = engine.server.Serve(ln)
 	}
-	if err != nil && err != http.ErrServerClosed {
-		engine.Log.Error(err)
-		panic(err)
+}
+
+// Shutdown 关闭 http 服务
+//
+// 参数:
+//   - ctx context.Context: 取消后不再等待关闭流程结束
+//
+// 返回:
+//   - error: 各阶段的错误信息，ctx 先取消时为 ctx.Err()
+//
+// 说明:
+//   - 按 Settings.Shutdown 的顺序与超时时间执行关闭流程，与 StopServer 相同
+//   - ctx 取消后关闭流程仍在后台继续执行
+func (engine *Engine) Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- engine.StopServer()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
-// 停止 http 服务
-func (engine *Engine) StopServer() (err error) {
-	// 执行用户定义的回调函数（逆序执行：先进后出）
-	for i := len(shutdown.callbacks) - 1; i >= 0; i-- {
-		shutdownCallback := shutdown.callbacks[i]
-		shutdownHandlerMsg := i18n.T("server.shutdown_callback", map[string]any{
-			"name": shutdownCallback.ShutdownName(),
-		})
-		engine.Log.Log(meta, shutdownHandlerMsg)
+// Err 获取监听器异常退出的错误
+//
+// 返回:
+//   - <-chan error: 监听器异常退出时接收到错误，仅保留第一个错误，正常关闭服务时不会接收到错误
+//
+// 说明:
+//   - RunServer 同样从该通道读取错误，使用 Start 启动服务时由调用方读取
+//
+// 示例:
+//
+//	select {
+//	case err := <-Server.Err():
+//		return err
+//	case <-ctx.Done():
+//		return Server.Shutdown(context.Background())
+//	}
+func (engine *Engine) Err() <-chan error {
+	return engine.serveErr
+}
 
-		err = shutdownCallback.OnShutdown()
-		if err != nil {
-			shutdownHandlerErrorMsg := i18n.T("server.shutdown_callback_error", map[string]any{
-				"err": err,
-			})
-			return errors.New(shutdownHandlerErrorMsg)
-		}
-	}
+// Addrs 获取全部监听器的实际监听地址
+//
+// 返回:
+//   - []net.Addr: 按 Settings.Listeners 顺序排列，未启动时为空
+//
+// 说明:
+//   - 监听端口为 0 时可通过该方法获取系统分配的端口
+func (engine *Engine) Addrs() []net.Addr {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	return slices.Clone(engine.addrs)
+}
 
-	if len(engine.Settings.Databases) != 0 {
-		closeDatabaseMsg := i18n.T("server.close_database")
-		engine.Log.Log(meta, closeDatabaseMsg)
-	}
-	for name, database := range engine.Settings.Databases {
-		err = database.Close()
-		if err != nil {
-			closeDatabaseErrorMsg := i18n.T("server.close_database_error", map[string]any{
-				"engine": database.Engine,
-				"name":   name,
-				"err":    err,
+// 停止 http 服务
+//
+// 返回:
+//   - error: 各阶段的错误信息
+//
+// 说明:
+//   - 按 Shutdown 中的顺序依次执行各阶段，某一阶段失败或超时后继续执行后续阶段
+//   - 多次调用时只执行一次，后续调用等待并返回首次调用的结果
+func (engine *Engine) StopServer() error {
+	engine.stopOnce.Do(func() {
+		defer close(engine.stopped)
+		timeouts := engine.Settings.Shutdown
+		engine.stopErr = errors.Join(
+			engine.shutdownPhase(i18n.T("server.shutdown.drain"), timeouts.DrainTimeout, engine.drain),
+			engine.shutdownPhase(i18n.T("server.shutdown.tasks"), timeouts.TaskTimeout, engine.stopTasks),
+			engine.shutdownPhase(i18n.T("server.shutdown.callbacks"), timeouts.CallbackTimeout, engine.runShutdownCallbacks),
+			engine.shutdownPhase(i18n.T("server.shutdown.databases"), timeouts.DatabaseTimeout, engine.closeDatabases),
+		)
+
+		stopTimeMsg := i18n.T("server.stop_time", map[string]any{
+			"stop_time": GetTime().Format(time.DateTime),
+		})
+		engine.Log.Log(meta, stopTimeMsg)
+		if engine.startTime != nil {
+			runTimeMsg := i18n.T("server.run_time", map[string]any{
+				"run_time": engine.runTimeStr(),
 			})
-			return errors.New(closeDatabaseErrorMsg)
+			engine.Log.Log(meta, runTimeMsg)
 		}
-	}
-
-	// 取消所有 goroutine 任务
-	startup.cancel()
-	// 等待所有 goroutine 任务结束
-	startup.waitGroup.Wait()
-
-	stopTimeMsg := i18n.T("server.stop_time", map[string]any{
-		"stop_time": GetTime().Format(time.DateTime),
-	})
-	engine.Log.Log(meta, stopTimeMsg)
-	runTimeMsg := i18n.T("server.run_time", map[string]any{
-		"run_time": engine.runTimeStr(),
+		stopMsg := i18n.T("server.stopped")
+		engine.Log.Log(meta, stopMsg)
 	})
-	engine.Log.Log(meta, runTimeMsg)
-	stopMsg := i18n.T("server.stopped")
-	engine.Log.Log(meta, stopMsg)
-	// 关闭服务器
-	err = engine.server.Shutdown(context.Background())
-	if err != nil {
-		return err
-	}
-	return nil
+	<-engine.stopped
+	return engine.stopErr
 }
 
 // 获取当前运行时间 返回时间间隔
@@ -284,8 +417,10 @@ func (engine *Engine) runTimeStr() string {
 //   - w http.ResponseWriter: 响应写入器
 //   - r *http.Request: HTTP请求对象
 func (engine *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	requestID := GetTime()
-	ctx := context.WithValue(r.Context(), "requestID", requestID)
+	startTime := GetTime()
+	requestID := engine.generateRequestID(r)
+	ctx := RequestIDKey.WithValue(r.Context(), requestID)
+	ctx = LoggerKey.WithValue(ctx, engine.Log.WithRequestID(requestID))
 	r = r.WithContext(ctx)
 
 	// 初始化请求
@@ -293,13 +428,21 @@ func (engine *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Object:     r,
 		PathParams: make(Params),
 		Params:     make(Params),
+		engine:     engine,
 	}
 	responseWriter := &ResponseWriter{ResponseWriter: w}
+	if engine.Settings != nil && engine.Settings.RequestID.Header != "" {
+		responseWriter.Header().Set(engine.Settings.RequestID.Header, requestID)
+	}
 
-	defer engine.recovery(request, responseWriter, GetTime().Sub(requestID))
+	defer engine.recovery(request, responseWriter, startTime)
 
 	response := engine.handler(request)
-	_, err := response.write(request, responseWriter)
+	etagMode := ETagWeak
+	if engine.Settings != nil {
+		etagMode = engine.Settings.ETag
+	}
+	_, err := response.write(request, responseWriter, etagMode)
 	if err != nil {
 		panic(err)
 	}
@@ -314,13 +457,15 @@ func (engine *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 //   - *Response: HTTP响应对象指针
 func (engine *Engine) handler(request *Request) (response *Response) {
 	// 路由解析
-	viewSet, middlewares, isPattern := engine.Router.resolve(request.Object.URL.Path, request.PathParams)
-	if isPattern == false || viewSet == nil {
+	entry := engine.Router.resolve(request.Object.Host, request.Object.URL.Path, request.PathParams)
+	if entry == nil || entry.viewSet == nil {
 		urlNotAllowedMsg := i18n.T("server.url_not_allowed", map[string]any{
 			"path": request.Object.URL.Path,
 		})
 		return &Response{Status: http.StatusNotFound, Data: urlNotAllowedMsg}
 	}
+	viewSet, middlewares := entry.viewSet, entry.middlewares
+	request.renderers = entry.renderers
 
 	// 设置 Allow 响应头
 	defer func() {
@@ -330,10 +475,31 @@ func (engine *Engine) handler(request *Request) (response *Response) {
 
 	// 获取视图处理函数
 	handlerFunc, err := viewSet.GetHandlerFunc(request.Object.Method)
+	if webSocketHandler := viewSet.getWebSocketHandler(request.Object); webSocketHandler != nil {
+		handlerFunc, err = webSocketHandler, nil
+	}
 	if handlerFunc == nil || err != nil {
 		return &Response{Status: http.StatusMethodNotAllowed, Data: err.Error()}
 	}
 
+	// 合并视图中间件与方法中间件：路由组中间件 -> 视图中间件 -> 方法中间件
+	if viewMiddlewares := viewSet.GetMiddlewares(request.Object.Method); len(viewMiddlewares) > 0 {
+		middlewares = append(slices.Clip(middlewares), viewMiddlewares...)
+	}
+
+	// 路由超时：中间件与视图共用带超时的上下文，超时响应同样经过中间件
+	if entry.timeout > 0 {
+		parent := request.Context()
+		ctx, cancel := context.WithTimeout(parent, entry.timeout)
+		defer cancel()
+		request.WithContext(ctx)
+		handlerFunc = timeoutHandler(handlerFunc)
+		// 响应在 handler 返回后写入，事件流等流式响应不受路由超时限制
+		defer func() {
+			request.WithContext(detachedContext{Context: parent, values: request.Context()})
+		}()
+	}
+
 	// 中间件，处理请求之前：构建洋葱链后获取响应
 	middlewareChain := middlewares.loadMiddleware(handlerFunc)
 	return middlewareChain(request)
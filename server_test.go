@@ -0,0 +1,171 @@
+package goi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestEngineStart 验证 Start 非阻塞启动、返回错误以及 Shutdown 关闭服务
+func TestEngineStart(t *testing.T) {
+	engine := NewHTTPServer()
+	engine.Log = NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer engine.Log.File.Close()
+	savedLog := Log
+	Log = engine.Log
+	defer func() { Log = savedLog }()
+	engine.Settings = newSettings()
+	engine.Settings.Listeners = []Listener{{Address: "127.0.0.1:0"}}
+	engine.Router.Path("ping", "ping", ViewSet{GET: func(request *Request) any { return "pong" }})
+
+	invalid := NewHTTPServer()
+	invalid.Log = engine.Log
+	invalid.Settings = newSettings()
+	invalid.Settings.Network = "udp"
+	if err := invalid.Start(context.Background()); err == nil {
+		t.Error("Start with udp network succeeded")
+	}
+	// 启动失败后修改设置可重新启动
+	invalid.Settings.Listeners = []Listener{{Address: "127.0.0.1:0"}}
+	if err := invalid.Start(context.Background()); err != nil {
+		t.Errorf("Start after failure = %v", err)
+	}
+	invalid.Shutdown(context.Background())
+
+	if err := engine.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Start(context.Background()); err == nil {
+		t.Error("second Start succeeded")
+	}
+	addrs := engine.Addrs()
+	if len(addrs) != 1 {
+		t.Fatalf("Addrs = %v", addrs)
+	}
+	url := "http://" + addrs[0].String() + "/ping"
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	if string(body) != "pong" {
+		t.Errorf("body = %q", body)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err = engine.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown = %v", err)
+	}
+	if _, err = http.Get(url); err == nil {
+		t.Error("request after Shutdown succeeded")
+	}
+	if engine.taskCtx.Err() == nil {
+		t.Error("startup tasks not cancelled")
+	}
+	select {
+	case err = <-engine.Err():
+		t.Errorf("Err after Shutdown = %v", err)
+	default:
+	}
+
+	// 监听器异常退出时通过 Err 获取错误
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.Close()
+	failed := NewHTTPServer()
+	go failed.serve(&http.Server{Handler: failed}, ln)
+	select {
+	case err = <-failed.Err():
+		if !errors.Is(err, net.ErrClosed) {
+			t.Errorf("Err = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Err not received")
+	}
+}
+
+// countTask 记录运行次数的后台任务与关闭回调
+type countTask struct {
+	name      string
+	startups  *atomic.Int32
+	shutdowns *atomic.Int32
+}
+
+func (task countTask) StartupName() string  { return task.name }
+func (task countTask) ShutdownName() string { return task.name }
+
+func (task countTask) OnStartup(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	task.startups.Add(1)
+	<-ctx.Done()
+}
+
+func (task countTask) OnShutdown() error {
+	task.shutdowns.Add(1)
+	return nil
+}
+
+// countBackend 注册后台任务与关闭回调的缓存后端
+type countBackend struct {
+	CacheBackend
+	countTask
+}
+
+// TestEngineRegistered 验证全局任务与回调在每个 Engine 中各执行一次，共享缓存的任务与回调只执行一次
+func TestEngineRegistered(t *testing.T) {
+	savedStartup, savedShutdown := startup, shutdown
+	startup, shutdown = newStartupManager(), newShutdownManager()
+	defer func() { startup, shutdown = savedStartup, savedShutdown }()
+
+	logger := NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer logger.File.Close()
+	savedLog := Log
+	Log = logger
+	defer func() { Log = savedLog }()
+
+	var globalStartups, globalShutdowns, cacheStartups, cacheShutdowns atomic.Int32
+	global := countTask{name: "global", startups: &globalStartups, shutdowns: &globalShutdowns}
+	RegisterOnStartup(global)
+	RegisterOnShutdown(global)
+	sharedCache := newCache()
+	sharedCache.Backend = countBackend{countTask: countTask{name: "cache", startups: &cacheStartups, shutdowns: &cacheShutdowns}}
+
+	engines := make([]*Engine, 2)
+	for i := range engines {
+		engines[i] = NewHTTPServer()
+		engines[i].Log = logger
+		engines[i].Settings = newSettings()
+		engines[i].Settings.Listeners = []Listener{{Address: "127.0.0.1:0"}}
+		engines[i].Cache = sharedCache
+		if err := engines[i].Start(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 启动后注册的任务与回调不影响已启动的 Engine
+	late := countTask{name: "late", startups: &globalStartups, shutdowns: &globalShutdowns}
+	RegisterOnStartup(late)
+	RegisterOnShutdown(late)
+
+	for _, engine := range engines {
+		if err := engine.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if globalStartups.Load() != 2 || globalShutdowns.Load() != 2 {
+		t.Errorf("global task = %d/%d, want 2/2", globalStartups.Load(), globalShutdowns.Load())
+	}
+	if cacheStartups.Load() != 1 || cacheShutdowns.Load() != 1 {
+		t.Errorf("cache task = %d/%d, want 1/1", cacheStartups.Load(), cacheShutdowns.Load())
+	}
+}
@@ -1,6 +1,7 @@
 package goi
 
 import (
+	"crypto/tls"
 	"os"
 	"time"
 
@@ -16,17 +17,30 @@ const (
 )
 
 // SSL
+//
+// 说明:
+//   - 证书通过 tls.Config.GetCertificate 提供，修改证书文件后可通过 SIGHUP、ReloadCertificates 或 ReloadInterval 重新加载
+//   - 设置 ClientCAPath 时启用双向认证（mTLS），通过 Request.ClientCertificate 获取已验证的客户端证书
 type SSL struct {
-	Enabled  bool
-	Type     string
-	CertPath string
-	KeyPath  string
+	Enabled      bool
+	Type         string
+	CertPath     string
+	KeyPath      string
+	Certificates []CertificatePair // 其它证书，按客户端 SNI 选择，均不匹配时使用 CertPath 证书
+
+	MinVersion   uint16   // 最低 TLS 版本，例如 tls.VersionTLS12，0 表示使用 crypto/tls 默认值
+	CipherSuites []uint16 // TLS 1.2 及以下版本的加密套件，为空时使用 crypto/tls 默认值
+
+	ClientCAPath string             // 客户端 CA 证书文件路径（PEM），不为空时启用双向认证
+	ClientAuth   tls.ClientAuthType // 客户端认证方式，设置 ClientCAPath 时默认 tls.RequireAndVerifyClientCert
+
+	ReloadInterval time.Duration // 检查证书文件修改的间隔，0 表示不检查
 }
 
 // 项目设置
 type settings struct {
 	Debug       bool                 // 是否开启 Debug 模式
-	Network     string               // 网络协议 "tcp"、"tcp4"、"tcp6"、"udp"、"udp4"、"udp6
+	Network     string               // 网络协议 "tcp"、"tcp4"、"tcp6"、"unix"
 	BindAddress string               // 监听地址
 	Port        uint16               // 服务端口
 	BindDomain  string               // 绑定域名
@@ -35,6 +49,10 @@ type settings struct {
 	PrivateKey  string               // 项目 RSA 私钥
 	PublicKey   string               // 项目 RSA 公钥
 	SSL         SSL                  // SSL
+	Listeners   []Listener           // 监听器列表，不为空时忽略 Network、BindAddress、Port 与 SSL
+	RequestID   RequestID            // 请求 ID
+	ETag        ETagMode             // 自动 ETag 模式，默认 ETagWeak
+	Shutdown    Shutdown             // 关闭服务各阶段超时时间
 	Databases   map[string]*Database // 数据库配置
 
 	// TIMEZONE
@@ -60,7 +78,16 @@ func newSettings() *settings {
 		PrivateKey:  "",
 		PublicKey:   "",
 		SSL:         SSL{},
-		Databases:   make(map[string]*Database),
+		Listeners:   nil,
+		RequestID:   RequestID{Header: "X-Request-ID", Generator: ULIDGenerator{}},
+		ETag:        ETagWeak,
+		Shutdown: Shutdown{
+			DrainTimeout:    30 * time.Second,
+			TaskTimeout:     10 * time.Second,
+			CallbackTimeout: 10 * time.Second,
+			DatabaseTimeout: 10 * time.Second,
+		},
+		Databases: make(map[string]*Database),
 
 		// TIMEZONE
 		UseTZ:    true,
@@ -1,5 +1,29 @@
 package goi
 
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
+)
+
+// Shutdown 关闭服务各阶段的超时时间，0 表示不限制
+//
+// 关闭顺序:
+//  1. 停止接收新连接，通知事件流与 WebSocket 连接退出，等待处理中的请求完成，超时后强制断开
+//  2. 取消后台任务（Startup）并等待退出
+//  3. 逆序执行关闭回调（ShutdownCallback）
+//  4. 关闭数据库连接
+type Shutdown struct {
+	DrainTimeout    time.Duration // 等待处理中的请求完成，默认 30 秒
+	TaskTimeout     time.Duration // 等待后台任务退出，默认 10 秒
+	CallbackTimeout time.Duration // 执行全部关闭回调，默认 10 秒
+	DatabaseTimeout time.Duration // 关闭全部数据库连接，默认 10 秒
+}
+
 // shutdownManager 关闭服务管理器
 type shutdownManager struct {
 	callbacks []ShutdownCallback
@@ -28,6 +52,167 @@ type ShutdownCallback interface {
 //
 // 注意:
 //   - 回调函数会逆序执行（先注册的后执行）
+//   - 回调函数在处理中的请求完成、后台任务退出之后，数据库连接关闭之前执行
+//   - 请在 RunServer 或 Start 执行之前注册，Engine 启动时复制已注册的回调，之后注册的回调不会执行
+//   - 未启动的 Engine 关闭时不执行回调
 func RegisterOnShutdown(shutdownCallback ShutdownCallback) {
 	shutdown.callbacks = append(shutdown.callbacks, shutdownCallback)
 }
+
+// registerOnShutdown 注册仅在该 Engine 关闭时执行的回调
+//
+// 参数:
+//   - shutdownCallback ShutdownCallback: 回调函数
+func (engine *Engine) registerOnShutdown(shutdownCallback ShutdownCallback) {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	engine.callbacks = append(engine.callbacks, shutdownCallback)
+}
+
+// shutdownPhase 执行关闭阶段并记录日志
+//
+// 参数:
+//   - phase string: 阶段名称
+//   - timeout time.Duration: 超时时间，0 表示不限制
+//   - run func(ctx context.Context) error: 阶段处理函数，ctx 在超时后取消
+//
+// 返回:
+//   - error: 阶段处理函数的错误信息，超时时为超时错误
+//
+// 说明:
+//   - 超时后不再等待处理函数返回，继续执行下一阶段
+func (engine *Engine) shutdownPhase(phase string, timeout time.Duration, run func(ctx context.Context) error) error {
+	phaseStartMsg := i18n.T("server.shutdown.phase_start", map[string]any{
+		"phase": phase,
+	})
+	engine.Log.Log(meta, phaseStartMsg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	}
+	defer cancel()
+
+	startTime := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- run(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		phaseTimeoutMsg := i18n.T("server.shutdown.phase_timeout", map[string]any{
+			"phase":   phase,
+			"timeout": timeout,
+		})
+		err = errors.New(phaseTimeoutMsg)
+	}
+	if err != nil {
+		engine.Log.Error(err)
+		return err
+	}
+	phaseDoneMsg := i18n.T("server.shutdown.phase_done", map[string]any{
+		"phase":   phase,
+		"elapsed": time.Since(startTime).Round(time.Millisecond),
+	})
+	engine.Log.Log(meta, phaseDoneMsg)
+	return nil
+}
+
+// drain 停止全部监听器接收新连接并等待处理中的请求完成
+//
+// 参数:
+//   - ctx context.Context: 超时后取消，强制断开剩余连接
+//
+// 返回:
+//   - error: 关闭过程中的错误信息
+func (engine *Engine) drain(ctx context.Context) error {
+	// 通知事件流退出，WebSocket 连接不受 http.Server.Shutdown 管理，单独关闭
+	engine.stopClose()
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(1)
+	go func() {
+		defer waitGroup.Done()
+		if count := engine.webSockets.closeAll(ctx); count > 0 {
+			closeWebSocketMsg := i18n.T("server.close_websocket", map[string]any{
+				"count": count,
+			})
+			engine.Log.Log(meta, closeWebSocketMsg)
+		}
+	}()
+	engine.lock.Lock()
+	servers := engine.servers
+	engine.lock.Unlock()
+	errs := make([]error, len(servers))
+	for i, server := range servers {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			errs[i] = server.Shutdown(ctx)
+			if errors.Is(errs[i], context.DeadlineExceeded) {
+				server.Close()
+			}
+		}()
+	}
+	waitGroup.Wait()
+	return errors.Join(errs...)
+}
+
+// stopTasks 取消后台任务并等待退出
+func (engine *Engine) stopTasks(ctx context.Context) error {
+	engine.taskCancel()
+	engine.taskWait.Wait()
+	return nil
+}
+
+// runShutdownCallbacks 逆序执行关闭回调，单个回调失败不影响其它回调
+func (engine *Engine) runShutdownCallbacks(ctx context.Context) error {
+	engine.lock.Lock()
+	callbacks := slices.Clone(engine.callbacks)
+	engine.lock.Unlock()
+	var errs []error
+	for i := len(callbacks) - 1; i >= 0; i-- {
+		if ctx.Err() != nil {
+			break
+		}
+		shutdownCallback := callbacks[i]
+		shutdownHandlerMsg := i18n.T("server.shutdown_callback", map[string]any{
+			"name": shutdownCallback.ShutdownName(),
+		})
+		engine.Log.Log(meta, shutdownHandlerMsg)
+
+		err := shutdownCallback.OnShutdown()
+		if err != nil {
+			shutdownHandlerErrorMsg := i18n.T("server.shutdown_callback_error", map[string]any{
+				"err": err,
+			})
+			errs = append(errs, errors.New(shutdownHandlerErrorMsg))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeDatabases 关闭全部数据库连接
+func (engine *Engine) closeDatabases(ctx context.Context) error {
+	if len(engine.Settings.Databases) != 0 {
+		closeDatabaseMsg := i18n.T("server.close_database")
+		engine.Log.Log(meta, closeDatabaseMsg)
+	}
+	var errs []error
+	for name, database := range engine.Settings.Databases {
+		if ctx.Err() != nil {
+			break
+		}
+		err := database.Close()
+		if err != nil {
+			closeDatabaseErrorMsg := i18n.T("server.close_database_error", map[string]any{
+				"engine": database.Engine,
+				"name":   name,
+				"err":    err,
+			})
+			errs = append(errs, errors.New(closeDatabaseErrorMsg))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,139 @@
+package goi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// orderCallback 记录执行顺序的关闭回调
+type orderCallback struct {
+	name  string
+	lock  *sync.Mutex
+	order *[]string
+	err   error
+}
+
+func (callback orderCallback) ShutdownName() string { return callback.name }
+
+func (callback orderCallback) OnShutdown() error {
+	callback.lock.Lock()
+	defer callback.lock.Unlock()
+	*callback.order = append(*callback.order, callback.name)
+	return callback.err
+}
+
+// TestStopServer 验证处理中的请求在关闭时完成、各阶段依次执行且回调错误不中断后续回调
+func TestStopServer(t *testing.T) {
+	// 使用独立的回调列表，避免影响其它测试
+	savedShutdown := shutdown
+	shutdown = newShutdownManager()
+	defer func() { shutdown = savedShutdown }()
+
+	engine := NewHTTPServer()
+	engine.Log = NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer engine.Log.File.Close()
+	engine.Settings = newSettings()
+	engine.Settings.Shutdown.DrainTimeout = 5 * time.Second
+
+	var lock sync.Mutex
+	var order []string
+	record := func(name string) {
+		lock.Lock()
+		defer lock.Unlock()
+		order = append(order, name)
+	}
+	started := make(chan struct{})
+	engine.Router.Path("slow", "慢请求", ViewSet{GET: func(request *Request) any {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		record("request")
+		return "done"
+	}})
+	engine.taskWait.Add(1)
+	go func() {
+		defer engine.taskWait.Done()
+		<-engine.taskCtx.Done()
+		record("task")
+	}()
+	RegisterOnShutdown(orderCallback{name: "second", lock: &lock, order: &order})
+	RegisterOnShutdown(orderCallback{name: "first", lock: &lock, order: &order, err: errors.New("boom")})
+	engine.copyRegistered() // 未调用 Start，手动复制注册的回调
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: engine}
+	engine.servers = []*http.Server{server}
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+
+	result := make(chan string, 1)
+	go func() {
+		response, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			result <- err.Error()
+			return
+		}
+		response.Body.Close()
+		result <- response.Status
+	}()
+	<-started
+
+	stopErr := engine.StopServer()
+	if stopErr == nil || !strings.Contains(stopErr.Error(), "boom") {
+		t.Errorf("StopServer error = %v", stopErr)
+	}
+	if status := <-result; status != "200 OK" {
+		t.Errorf("in-flight request = %s", status)
+	}
+	if err = <-served; err != http.ErrServerClosed {
+		t.Errorf("Serve = %v", err)
+	}
+	if engine.closing.Err() == nil {
+		t.Error("closing context not cancelled")
+	}
+	want := []string{"request", "task", "first", "second"}
+	if strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+
+	// 重复调用返回首次结果
+	if again := engine.StopServer(); again != stopErr {
+		t.Errorf("second StopServer = %v", again)
+	}
+}
+
+// TestShutdownPhaseTimeout 验证阶段超时后返回错误并取消上下文
+func TestShutdownPhaseTimeout(t *testing.T) {
+	engine := NewHTTPServer()
+	engine.Log = NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer engine.Log.File.Close()
+
+	cancelled := make(chan struct{})
+	err := engine.shutdownPhase("slow", 50*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		close(cancelled)
+		return nil
+	})
+	if err == nil {
+		t.Fatal("shutdownPhase did not time out")
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("phase context not cancelled")
+	}
+
+	err = engine.shutdownPhase("fast", 0, func(ctx context.Context) error { return nil })
+	if err != nil {
+		t.Errorf("shutdownPhase = %v", err)
+	}
+}
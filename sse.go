@@ -0,0 +1,304 @@
+package goi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
+)
+
+// DefaultSSEHeartbeat 事件流默认心跳间隔
+const DefaultSSEHeartbeat = 15 * time.Second
+
+// EventStream Server-Sent Events 事件流
+//
+// 由 SSE 创建，所有写入方法均可在多个 goroutine 中并发调用
+type EventStream struct {
+	request   *Request
+	writer    http.ResponseWriter
+	flusher   *http.ResponseController
+	ctx       context.Context
+	cancel    context.CancelFunc
+	heartbeat chan time.Duration
+
+	lock      sync.Mutex
+	lastWrite time.Time // 最近一次写入时间，心跳仅在空闲时发送
+}
+
+// SSE 创建 Server-Sent Events 响应
+//
+// 参数:
+//   - handler func(stream *EventStream) error: 事件流处理函数，返回后结束响应
+//
+// 返回:
+//   - RawHandler: 可作为视图返回值或 Response.Data
+//
+// 说明:
+//   - 设置 Content-Type: text/event-stream 与 Cache-Control: no-cache，并立即发送响应头
+//   - 每隔 DefaultSSEHeartbeat 在空闲时发送注释心跳，可通过 SetHeartbeat 调整
+//   - 客户端断开或服务停止时取消 stream.Context()，处理函数应据此退出
+//   - 因上下文取消而返回的错误不视为失败
+//   - 路由的 Timeout 只限制视图返回 SSE 之前的处理，不限制事件流的持续时间
+//
+// 示例:
+//
+//	return goi.SSE(func(stream *goi.EventStream) error {
+//		for {
+//			select {
+//			case <-stream.Context().Done():
+//				return nil
+//			case message := <-messages:
+//				if err := stream.Send("message", message.ID, message); err != nil {
+//					return err
+//				}
+//			}
+//		}
+//	})
+func SSE(handler func(stream *EventStream) error) RawHandler {
+	return func(w http.ResponseWriter, r *Request) error {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		// 服务停止时取消事件流，避免长连接阻塞 http.Server.Shutdown
+		if r.engine != nil {
+			stop := context.AfterFunc(r.engine.closing, cancel)
+			defer stop()
+		}
+
+		header := w.Header()
+		header.Set(ContentType, "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("X-Accel-Buffering", "no") // 关闭 nginx 代理缓冲
+		header.Del("Content-Length")
+
+		stream := &EventStream{
+			request:   r,
+			writer:    w,
+			flusher:   http.NewResponseController(w),
+			ctx:       ctx,
+			cancel:    cancel,
+			heartbeat: make(chan time.Duration),
+			lastWrite: time.Now(),
+		}
+		w.WriteHeader(http.StatusOK)
+		err := stream.flusher.Flush()
+		if err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+
+		done := make(chan struct{})
+		go stream.keepAlive(done)
+		err = handler(stream)
+		cancel()
+		<-done // 等待心跳结束，处理函数返回后不能再写入响应
+		if err != nil && errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	}
+}
+
+// Context 获取事件流上下文
+//
+// 返回:
+//   - context.Context: 客户端断开、服务停止或写入失败时取消
+func (stream *EventStream) Context() context.Context {
+	return stream.ctx
+}
+
+// Request 获取事件流的请求对象
+//
+// 返回:
+//   - *Request: HTTP请求对象
+func (stream *EventStream) Request() *Request {
+	return stream.request
+}
+
+// LastEventID 获取客户端重连时携带的 Last-Event-ID
+//
+// 返回:
+//   - string: 断线前收到的最后一个事件 ID，首次连接时为空
+func (stream *EventStream) LastEventID() string {
+	return stream.request.Object.Header.Get("Last-Event-ID")
+}
+
+// Send 发送事件
+//
+// 参数:
+//   - event string: 事件类型，为空时客户端按 "message" 处理
+//   - id string: 事件 ID，客户端重连时通过 Last-Event-ID 返回，为空表示不设置
+//   - data any: 事件数据，string 与 []byte 原样发送，其它类型编码为 JSON
+//
+// 返回:
+//   - error: 编码或写入过程中的错误信息，事件流已取消时返回上下文错误
+//
+// 说明:
+//   - 多行数据按行（"\n"、"\r\n" 或 "\r"）拆分为多个 data 字段，客户端收到后以换行拼接
+//   - event 与 id 不能包含换行
+func (stream *EventStream) Send(event string, id string, data any) error {
+	err := checkSSEField("event", event)
+	if err != nil {
+		return err
+	}
+	err = checkSSEField("id", id)
+	if err != nil {
+		return err
+	}
+
+	var payload string
+	switch value := data.(type) {
+	case string:
+		payload = value
+	case []byte:
+		payload = string(value)
+	default:
+		dataByte, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		payload = string(dataByte)
+	}
+
+	var builder strings.Builder
+	if id != "" {
+		builder.WriteString("id: " + id + "\n")
+	}
+	if event != "" {
+		builder.WriteString("event: " + event + "\n")
+	}
+	for _, line := range strings.Split(sseLineBreaks.Replace(payload), "\n") {
+		builder.WriteString("data: " + line + "\n")
+	}
+	builder.WriteString("\n")
+	return stream.write(builder.String())
+}
+
+// Retry 设置客户端断线后的重连间隔
+//
+// 参数:
+//   - interval time.Duration: 重连间隔，精确到毫秒
+//
+// 返回:
+//   - error: 写入过程中的错误信息
+func (stream *EventStream) Retry(interval time.Duration) error {
+	return stream.write("retry: " + strconv.FormatInt(interval.Milliseconds(), 10) + "\n\n")
+}
+
+// Comment 发送注释，客户端会忽略注释，可用于保持连接
+//
+// 参数:
+//   - text string: 注释内容
+//
+// 返回:
+//   - error: 写入过程中的错误信息
+func (stream *EventStream) Comment(text string) error {
+	var builder strings.Builder
+	for _, line := range strings.Split(sseLineBreaks.Replace(text), "\n") {
+		builder.WriteString(": " + line + "\n")
+	}
+	builder.WriteString("\n")
+	return stream.write(builder.String())
+}
+
+// SetHeartbeat 设置心跳间隔
+//
+// 参数:
+//   - interval time.Duration: 心跳间隔，小于等于 0 时关闭心跳
+func (stream *EventStream) SetHeartbeat(interval time.Duration) {
+	select {
+	case stream.heartbeat <- interval:
+	case <-stream.ctx.Done():
+	}
+}
+
+// write 写入并刷新数据，失败时取消事件流
+//
+// 参数:
+//   - data string: 按 SSE 格式编码的数据
+//
+// 返回:
+//   - error: 写入过程中的错误信息
+func (stream *EventStream) write(data string) error {
+	stream.lock.Lock()
+	defer stream.lock.Unlock()
+	if err := stream.ctx.Err(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(stream.writer, data)
+	if err == nil {
+		err = stream.flusher.Flush()
+		if errors.Is(err, http.ErrNotSupported) {
+			err = nil
+		}
+	}
+	if err != nil {
+		stream.cancel()
+		return err
+	}
+	stream.lastWrite = time.Now()
+	return nil
+}
+
+// keepAlive 在空闲时发送心跳，直至事件流取消
+//
+// 参数:
+//   - done chan struct{}: 退出时关闭
+func (stream *EventStream) keepAlive(done chan struct{}) {
+	defer close(done)
+	interval := DefaultSSEHeartbeat
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		var tick <-chan time.Time
+		if interval > 0 {
+			tick = timer.C
+		}
+		select {
+		case <-stream.ctx.Done():
+			return
+		case interval = <-stream.heartbeat:
+			if interval > 0 {
+				timer.Reset(interval)
+			}
+		case <-tick:
+			stream.lock.Lock()
+			idle := time.Since(stream.lastWrite)
+			stream.lock.Unlock()
+			if idle >= interval {
+				if stream.Comment("heartbeat") != nil {
+					return
+				}
+				idle = 0
+			}
+			timer.Reset(interval - idle)
+		}
+	}
+}
+
+// sseLineBreaks 将 "\r\n" 与单独的 "\r" 统一为 "\n"，客户端会将三者都视为换行
+var sseLineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// checkSSEField 检查事件字段不包含换行
+//
+// 参数:
+//   - name string: 字段名称
+//   - value string: 字段值
+//
+// 返回:
+//   - error: 包含换行时的错误信息
+func checkSSEField(name string, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		sseInvalidFieldMsg := i18n.T("server.sse_invalid_field", map[string]any{
+			"name":  name,
+			"value": value,
+		})
+		return errors.New(sseInvalidFieldMsg)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package goi
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSSE 验证事件格式、Last-Event-ID、心跳与客户端断开后退出
+func TestSSE(t *testing.T) {
+	engine := NewHTTPServer()
+	engine.Log = NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer engine.Log.File.Close()
+
+	exited := make(chan error, 1)
+	engine.Router.Path("events", "事件流", ViewSet{GET: func(request *Request) any {
+		return SSE(func(stream *EventStream) error {
+			stream.SetHeartbeat(10 * time.Millisecond)
+			err := stream.Retry(3 * time.Second)
+			if err != nil {
+				return err
+			}
+			err = stream.Send("user", "2", map[string]string{"last": stream.LastEventID()})
+			if err != nil {
+				return err
+			}
+			err = stream.Send("", "", "line1\nline2")
+			if err != nil {
+				return err
+			}
+			// 单独的 \r 同样是换行，不能借此注入 event 与 id 字段
+			err = stream.Send("", "", "ok\revent: admin\rid: 999")
+			if err != nil {
+				return err
+			}
+			err = stream.Comment("a\r\nb\rc")
+			if err != nil {
+				return err
+			}
+			if err = stream.Send("bad\nevent", "", ""); err == nil {
+				t.Error("Send accepted event with line break")
+			}
+			<-stream.Context().Done()
+			exited <- stream.Send("late", "", "")
+			return stream.Context().Err()
+		})
+	}})
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+	request.Header.Set("Last-Event-ID", "1")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Header.Get(ContentType) != "text/event-stream" || response.Header.Get("Cache-Control") != "no-cache" {
+		t.Errorf("headers = %v", response.Header)
+	}
+
+	want := []string{
+		"retry: 3000", "",
+		"id: 2", "event: user", `data: {"last":"1"}`, "",
+		"data: line1", "data: line2", "",
+		"data: ok", "data: event: admin", "data: id: 999", "",
+		": a", ": b", ": c", "",
+		": heartbeat", "",
+	}
+	reader := bufio.NewReader(response.Body)
+	for _, line := range want {
+		got, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got = strings.TrimSuffix(got, "\n"); got != line {
+			t.Fatalf("line = %q, want %q", got, line)
+		}
+	}
+	response.Body.Close()
+
+	select {
+	case err := <-exited:
+		if err == nil {
+			t.Error("Send after disconnect succeeded")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler did not exit after client disconnected")
+	}
+}
+
+// TestSSETimeoutGroup 验证设置了超时的路由组中，事件流的持续时间不受路由超时限制
+func TestSSETimeoutGroup(t *testing.T) {
+	engine := NewHTTPServer()
+	engine.Log = NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer engine.Log.File.Close()
+
+	key := NewContextKey[string]("user")
+	api := engine.Router.Include("api", "接口").Timeout(20 * time.Millisecond)
+	api.Path("events", "事件流", ViewSet{GET: func(request *Request) any {
+		key.Set(request, "goi")
+		return SSE(func(stream *EventStream) error {
+			// 超过路由超时时间后继续发送
+			time.Sleep(100 * time.Millisecond)
+			if stream.Context().Err() != nil {
+				return stream.Context().Err()
+			}
+			user, _ := key.Value(stream.Context())
+			return stream.Send("", "", user)
+		})
+	}})
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/api/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK || string(body) != "data: goi\n\n" {
+		t.Errorf("events = %d %q", response.StatusCode, body)
+	}
+}
@@ -2,26 +2,20 @@ package goi
 
 import (
 	"context"
+	"slices"
 	"sync"
 )
 
 // startupManager 启动服务管理器
 type startupManager struct {
-	ctx       context.Context
-	cancel    context.CancelFunc
-	waitGroup *sync.WaitGroup
-	startups  []Startup
+	startups []Startup
 }
 
 var startup = newStartupManager()
 
 func newStartupManager() *startupManager {
-	ctx, cancel := context.WithCancel(context.Background())
 	return &startupManager{
-		ctx:       ctx,
-		cancel:    cancel,
-		waitGroup: &sync.WaitGroup{},
-		startups:  make([]Startup, 0),
+		startups: make([]Startup, 0),
 	}
 }
 
@@ -39,7 +33,29 @@ type Startup interface {
 //   - task Startup: 要注册的任务
 //
 // 注意:
-//   - 请在 RunServer 执行之前注册，之后注册的任务不会执行
+//   - 请在 RunServer 或 Start 执行之前注册，Engine 启动时复制已注册的任务，之后注册的任务不会执行
+//   - 任务在每个启动的 Engine 中各执行一次，ctx 在该 Engine 关闭时取消
 func RegisterOnStartup(task Startup) {
 	startup.startups = append(startup.startups, task)
 }
+
+// copyRegistered 复制 RegisterOnStartup 与 RegisterOnShutdown 注册的任务与回调
+//
+// 说明:
+//   - Start 时调用，之后的全局注册不影响该 Engine
+func (engine *Engine) copyRegistered() {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	engine.startups = slices.Clone(startup.startups)
+	engine.callbacks = slices.Clone(shutdown.callbacks)
+}
+
+// registerOnStartup 注册仅在该 Engine 中运行的后台任务，需在后台任务启动前调用
+//
+// 参数:
+//   - task Startup: 要注册的任务
+func (engine *Engine) registerOnStartup(task Startup) {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	engine.startups = append(engine.startups, task)
+}
@@ -0,0 +1,77 @@
+package goi
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+
+	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
+)
+
+// timeoutHandler 在请求上下文结束前执行视图处理函数
+//
+// 参数:
+//   - handlerFunc HandlerFunc: 视图处理函数
+//
+// 返回:
+//   - HandlerFunc: 包装后的视图处理函数，作为中间件链的最内层
+//
+// 说明:
+//   - 视图处理函数在独立的协程中执行，使用请求对象及 PathParams、Params 的副本，超时后继续运行也不会修改当前请求
+//   - 超时返回 504，客户端断开等取消返回 503，响应经过中间件的 ProcessResponse
+//   - 视图处理函数中的 panic 会转交到当前协程重新抛出，由中间件的 ProcessException 处理
+//   - 超时时间由调用方通过请求上下文设置
+func timeoutHandler(handlerFunc HandlerFunc) HandlerFunc {
+	return func(request *Request) any {
+		ctx := request.Context()
+		handlerRequest := *request
+		handlerRequest.PathParams = maps.Clone(request.PathParams)
+		handlerRequest.Params = maps.Clone(request.Params)
+
+		done := make(chan any, 1)
+		panicked := make(chan any, 1)
+		go func() {
+			defer func() {
+				if err := recover(); err != nil {
+					panicked <- err
+				}
+			}()
+			done <- handlerFunc(&handlerRequest)
+		}()
+
+		select {
+		case content := <-done:
+			// 视图已返回，同步视图对请求对象的修改，例如 CheckPreconditions 声明的 ETag
+			*request = handlerRequest
+			return content
+		case err := <-panicked:
+			panic(err)
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) {
+				requestCanceledMsg := i18n.T("server.request_canceled", map[string]any{
+					"path": request.Object.URL.Path,
+				})
+				return Response{Status: http.StatusServiceUnavailable, Data: requestCanceledMsg}
+			}
+			requestTimeoutMsg := i18n.T("server.request_timeout", map[string]any{
+				"path": request.Object.URL.Path,
+			})
+			return Response{Status: http.StatusGatewayTimeout, Data: requestTimeoutMsg}
+		}
+	}
+}
+
+// detachedContext 取消信号来自 Context，上下文值来自 values
+//
+// 说明:
+//   - 路由超时的响应在 handler 返回后写入，写入阶段使用超时前的取消信号，保留视图设置的上下文值
+type detachedContext struct {
+	context.Context
+	values context.Context
+}
+
+// Value 从 values 获取上下文值
+func (ctx detachedContext) Value(key any) any {
+	return ctx.values.Value(key)
+}
@@ -0,0 +1,237 @@
+package goi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
+)
+
+// CertificatePair 证书与私钥文件路径
+type CertificatePair struct {
+	CertPath string // 证书文件路径（PEM）
+	KeyPath  string // 私钥文件路径（PEM）
+}
+
+// certificateStore 监听器的证书，支持重新加载
+type certificateStore struct {
+	ssl SSL
+
+	lock         sync.RWMutex
+	certificates []*tls.Certificate   // 服务端证书，第一个为默认证书
+	clientCAs    *x509.CertPool       // 客户端 CA 证书池
+	modTimes     map[string]time.Time // 加载时各文件的修改时间
+}
+
+// newCertificateStore 加载 SSL 配置中的证书
+//
+// 参数:
+//   - ssl SSL: SSL 配置
+//
+// 返回:
+//   - *certificateStore: 证书存储
+//   - error: 证书读取失败时的错误信息
+func newCertificateStore(ssl SSL) (*certificateStore, error) {
+	store := &certificateStore{ssl: ssl}
+	err := store.load()
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// pairs 获取全部证书与私钥文件路径，第一个为 SSL.CertPath 与 SSL.KeyPath
+func (store *certificateStore) pairs() []CertificatePair {
+	pairs := []CertificatePair{{CertPath: store.ssl.CertPath, KeyPath: store.ssl.KeyPath}}
+	return append(pairs, store.ssl.Certificates...)
+}
+
+// files 获取需要检查变更的文件
+func (store *certificateStore) files() []string {
+	files := make([]string, 0)
+	for _, pair := range store.pairs() {
+		files = append(files, pair.CertPath, pair.KeyPath)
+	}
+	if store.ssl.ClientCAPath != "" {
+		files = append(files, store.ssl.ClientCAPath)
+	}
+	return files
+}
+
+// load 重新读取全部证书，任一文件读取失败时保留原有证书
+//
+// 返回:
+//   - error: 证书读取失败时的错误信息
+func (store *certificateStore) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range store.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	certificates := make([]*tls.Certificate, 0)
+	for _, pair := range store.pairs() {
+		cert, err := tls.LoadX509KeyPair(pair.CertPath, pair.KeyPath)
+		if err != nil {
+			return err
+		}
+		certificates = append(certificates, &cert)
+	}
+
+	var clientCAs *x509.CertPool
+	if store.ssl.ClientCAPath != "" {
+		pem, err := os.ReadFile(store.ssl.ClientCAPath)
+		if err != nil {
+			return err
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			invalidClientCAMsg := i18n.T("server.tls.invalid_client_ca", map[string]any{
+				"path": store.ssl.ClientCAPath,
+			})
+			return errors.New(invalidClientCAMsg)
+		}
+	}
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	store.certificates = certificates
+	store.clientCAs = clientCAs
+	store.modTimes = modTimes
+	return nil
+}
+
+// changed 检查证书文件是否在上次加载后被修改
+func (store *certificateStore) changed() bool {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+	for file, modTime := range store.modTimes {
+		info, err := os.Stat(file)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// getCertificate 按 SNI 选择证书，实现 tls.Config.GetCertificate
+//
+// 参数:
+//   - hello *tls.ClientHelloInfo: 客户端握手信息
+//
+// 返回:
+//   - *tls.Certificate: 第一个支持客户端请求的证书，均不支持时为默认证书
+//   - error: 始终为 nil
+func (store *certificateStore) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	store.lock.RLock()
+	certificates := store.certificates
+	store.lock.RUnlock()
+	for _, cert := range certificates {
+		if hello.SupportsCertificate(cert) == nil {
+			return cert, nil
+		}
+	}
+	return certificates[0], nil
+}
+
+// configure 将证书、版本、加密套件与客户端认证设置到 TLS 配置
+//
+// 参数:
+//   - tlsConfig *tls.Config: 监听器的 TLS 配置
+//
+// 说明:
+//   - 客户端 CA 通过 GetConfigForClient 在每次握手时读取，重新加载后对新连接生效
+func (store *certificateStore) configure(tlsConfig *tls.Config) {
+	if tlsConfig.GetCertificate == nil {
+		tlsConfig.GetCertificate = store.getCertificate
+	}
+	if store.ssl.MinVersion != 0 {
+		tlsConfig.MinVersion = store.ssl.MinVersion
+	}
+	if len(store.ssl.CipherSuites) != 0 {
+		tlsConfig.CipherSuites = store.ssl.CipherSuites
+	}
+	if store.ssl.ClientCAPath == "" {
+		return
+	}
+	tlsConfig.ClientAuth = store.ssl.ClientAuth
+	if tlsConfig.ClientAuth == tls.NoClientCert {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	base := tlsConfig.Clone()
+	tlsConfig.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		store.lock.RLock()
+		clientCAs := store.clientCAs
+		store.lock.RUnlock()
+		config := base.Clone()
+		config.ClientCAs = clientCAs
+		return config, nil
+	}
+}
+
+// ReloadCertificates 重新加载全部监听器的证书与客户端 CA
+//
+// 返回:
+//   - error: 证书读取失败时的错误信息，失败的监听器继续使用原有证书
+//
+// 说明:
+//   - RunServer 收到 SIGHUP 信号时调用
+//   - 已建立的连接不受影响，新连接使用重新加载后的证书
+func (engine *Engine) ReloadCertificates() error {
+	engine.lock.Lock()
+	stores := engine.certificates
+	engine.lock.Unlock()
+	var errs []error
+	for _, store := range stores {
+		errs = append(errs, engine.reloadCertificate(store))
+	}
+	return errors.Join(errs...)
+}
+
+// reloadCertificate 重新加载证书并记录日志
+func (engine *Engine) reloadCertificate(store *certificateStore) error {
+	err := store.load()
+	if err != nil {
+		reloadErrorMsg := i18n.T("server.tls.reload_error", map[string]any{
+			"path": store.ssl.CertPath,
+			"err":  err,
+		})
+		engine.Log.Error(reloadErrorMsg)
+		return errors.New(reloadErrorMsg)
+	}
+	reloadedMsg := i18n.T("server.tls.reloaded", map[string]any{
+		"path": store.ssl.CertPath,
+	})
+	engine.Log.Log(meta, reloadedMsg)
+	return nil
+}
+
+// watchCertificate 定期检查证书文件，修改后重新加载
+//
+// 参数:
+//   - ctx context.Context: 取消后停止检查
+//   - store *certificateStore: 证书存储
+//   - interval time.Duration: 检查间隔
+func (engine *Engine) watchCertificate(ctx context.Context, store *certificateStore, interval time.Duration) {
+	defer engine.taskWait.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if store.changed() {
+				_ = engine.reloadCertificate(store) // 错误已记录日志
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package goi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// generateTestCertificate 生成自签证书，文件名为 CommonName
+func generateTestCertificate(t *testing.T, dir string, name string, serial int64, usage x509.ExtKeyUsage) {
+	t.Helper()
+	err := GenerateECCCertificate(x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{usage},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{name},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestTLS 验证 SNI 证书选择、最低版本、双向认证与证书热加载
+func TestTLS(t *testing.T) {
+	dir := t.TempDir()
+	generateTestCertificate(t, dir, "a.test", 1, x509.ExtKeyUsageServerAuth)
+	generateTestCertificate(t, dir, "b.test", 1, x509.ExtKeyUsageServerAuth)
+	generateTestCertificate(t, dir, "client", 1, x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewHTTPServer()
+	engine.Log = NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer engine.Log.File.Close()
+	savedLog := Log
+	Log = engine.Log
+	defer func() { Log = savedLog }()
+	engine.Settings = newSettings()
+	engine.Settings.Listeners = []Listener{{Address: "127.0.0.1:0", SSL: SSL{
+		Enabled:        true,
+		CertPath:       filepath.Join(dir, "a.test.crt"),
+		KeyPath:        filepath.Join(dir, "a.test.key"),
+		Certificates:   []CertificatePair{{CertPath: filepath.Join(dir, "b.test.crt"), KeyPath: filepath.Join(dir, "b.test.key")}},
+		MinVersion:     tls.VersionTLS12,
+		ClientCAPath:   filepath.Join(dir, "client.crt"),
+		ClientAuth:     tls.VerifyClientCertIfGiven,
+		ReloadInterval: 20 * time.Millisecond,
+	}}}
+	engine.Router.Path("whoami", "客户端证书", ViewSet{GET: func(request *Request) any {
+		if cert := request.ClientCertificate(); cert != nil {
+			return cert.Subject.CommonName
+		}
+		return "anonymous"
+	}})
+	if err = engine.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Shutdown(context.Background())
+	url := "https://" + engine.Addrs()[0].String() + "/whoami"
+
+	get := func(config *tls.Config) (*x509.Certificate, string, error) {
+		config.InsecureSkipVerify = true
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config, DisableKeepAlives: true}}
+		response, err := client.Get(url)
+		if err != nil {
+			return nil, "", err
+		}
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		return response.TLS.PeerCertificates[0], string(body), nil
+	}
+
+	// SNI
+	for serverName, want := range map[string]string{"": "a.test", "a.test": "a.test", "b.test": "b.test", "c.test": "a.test"} {
+		cert, _, err := get(&tls.Config{ServerName: serverName})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cert.Subject.CommonName != want {
+			t.Errorf("SNI %q = %s, want %s", serverName, cert.Subject.CommonName, want)
+		}
+	}
+
+	// 最低 TLS 版本
+	if _, _, err = get(&tls.Config{MaxVersion: tls.VersionTLS11}); err == nil {
+		t.Error("TLS 1.1 handshake succeeded")
+	}
+
+	// 双向认证
+	if _, body, err := get(&tls.Config{}); err != nil || body != "anonymous" {
+		t.Errorf("without client certificate = %q %v", body, err)
+	}
+	if _, body, err := get(&tls.Config{Certificates: []tls.Certificate{clientCert}}); err != nil || body != "client" {
+		t.Errorf("with client certificate = %q %v", body, err)
+	}
+
+	// 修改证书文件后自动重新加载
+	generateTestCertificate(t, dir, "a.test", 2, x509.ExtKeyUsageServerAuth)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		cert, _, err := get(&tls.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cert.SerialNumber.Int64() == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("certificate not reloaded")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// 重新加载失败时继续使用原有证书
+	if err = os.WriteFile(filepath.Join(dir, "b.test.key"), []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = engine.ReloadCertificates(); err == nil {
+		t.Error("ReloadCertificates with invalid key succeeded")
+	}
+	if cert, _, err := get(&tls.Config{ServerName: "b.test"}); err != nil || cert.Subject.CommonName != "b.test" {
+		t.Errorf("after failed reload = %v %v", cert, err)
+	}
+}
@@ -0,0 +1,318 @@
+package goi
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// routeEntry 路由树中的可匹配项
+type routeEntry struct {
+	viewSet     *ViewSet      // 视图方法
+	middlewares Middlewares   // 合并后的路由中间件（由外向内）
+	timeout     time.Duration // 路由超时时间，继承最近设置的上级路由
+	renderers   []string      // 可协商的响应 MIME 类型，继承最近设置的上级路由
+}
+
+// routeParam 已匹配的路由参数
+type routeParam struct {
+	name  string
+	value any
+}
+
+// routeNode 路由树节点
+//
+// 每个节点对应路由中以 '/' 分隔的一个片段，子节点按优先级依次匹配:
+//   - statics: 静态片段，精确匹配
+//   - params: 参数片段，按注册顺序使用片段正则匹配单个片段
+//   - paths: 路径片段，含可匹配 '/' 的转换器，使用正则匹配剩余的全部路径
+type routeNode struct {
+	segment    string                // 路由片段
+	statics    map[string]*routeNode // 静态子节点
+	params     []*routeNode          // 参数子节点
+	paths      []*routeNode          // 路径子节点
+	regex      *regexp.Regexp        // 参数、路径节点的匹配正则
+	paramInfos []ParamInfo           // 参数信息列表
+	route      *routeEntry           // 路由
+	noRoute    *routeEntry           // 路由组的无路由视图
+}
+
+// newRouteNode 创建路由树节点
+//
+// 参数:
+//   - segment string: 路由片段
+//
+// 返回:
+//   - *routeNode: 路由树节点
+func newRouteNode(segment string) *routeNode {
+	return &routeNode{
+		segment: segment,
+		statics: make(map[string]*routeNode),
+	}
+}
+
+// routeTable 由路由表编译的查找表
+type routeTable struct {
+	tree  *routeNode               // 默认路由树
+	hosts []*hostTable             // 主机路由组，无参数的主机优先
+	names map[string]*reverseEntry // 路由名称索引
+}
+
+// hostTable 主机路由组的查找表
+type hostTable struct {
+	regex      *regexp.Regexp // 主机匹配正则
+	paramInfos []ParamInfo    // 主机参数信息列表
+	tree       *routeNode     // 路由树
+}
+
+// reverseEntry 命名路由的反向解析信息
+type reverseEntry struct {
+	path       string           // 完整路由
+	paramInfos []ParamInfo      // 完整路由中按顺序出现的参数信息
+	regexes    []*regexp.Regexp // 参数值校验正则，与 paramInfos 一一对应
+}
+
+// compile 由路由表编译路由查找表
+//
+// 返回:
+//   - *routeTable: 路由查找表
+func (router *Router) compile() *routeTable {
+	table := &routeTable{names: make(map[string]*reverseEntry)}
+	table.tree = router.compileTree(routeEntry{}, table.names)
+	for _, hostRouter := range router.hosts {
+		table.hosts = append(table.hosts, &hostTable{
+			regex:      hostRouter.hostRegex,
+			paramInfos: hostRouter.hostParamInfos,
+			tree:       hostRouter.compileTree(routeEntry{middlewares: router.middlewares, timeout: router.timeout, renderers: router.renderers}, table.names),
+		})
+	}
+	slices.SortStableFunc(table.hosts, func(a, b *hostTable) int {
+		return min(len(a.paramInfos), 1) - min(len(b.paramInfos), 1)
+	})
+	return table
+}
+
+// compileTree 编译路由树
+//
+// 路由组的路径、参数、中间件、超时时间与响应类型逐级合并到子路由，每个视图以完整路径插入路由树
+//
+// 参数:
+//   - inherited routeEntry: 上级路由的中间件、超时时间与响应类型
+//   - names map[string]*reverseEntry: 路由名称索引
+//
+// 返回:
+//   - *routeNode: 路由树根节点
+func (router *Router) compileTree(inherited routeEntry, names map[string]*reverseEntry) *routeNode {
+	tree := newRouteNode("")
+	var walk func(router *Router, path string, paramInfos []ParamInfo, inherited routeEntry)
+	walk = func(router *Router, path string, paramInfos []ParamInfo, inherited routeEntry) {
+		path += router.path
+		if router.timeout > 0 {
+			inherited.timeout = router.timeout
+		}
+		if len(router.renderers) > 0 {
+			inherited.renderers = router.renderers
+		}
+		paramInfos = append(slices.Clip(paramInfos), router.paramInfos...)
+		merged := make(Middlewares, 0, len(inherited.middlewares)+len(router.middlewares))
+		merged = append(merged, inherited.middlewares...)
+		merged = append(merged, router.middlewares...)
+		inherited.middlewares = merged
+		if router.name != "" {
+			regexes := make([]*regexp.Regexp, 0, len(paramInfos))
+			for _, paramInfo := range paramInfos {
+				regexes = append(regexes, regexp.MustCompile("^"+paramInfo.Converter.Regex+"$"))
+			}
+			names[router.name] = &reverseEntry{path: path, paramInfos: paramInfos, regexes: regexes}
+		}
+		if router.include == nil {
+			entry := inherited
+			entry.viewSet = &router.viewSet
+			tree.insert(path, false, &entry)
+			return
+		}
+		if router.noRoute != nil {
+			entry := inherited
+			entry.viewSet = router.noRoute
+			tree.insert(path, true, &entry)
+		}
+		for _, itemRouter := range router.include {
+			walk(itemRouter, path, paramInfos, inherited)
+		}
+	}
+	walk(router, "", nil, inherited)
+	return tree
+}
+
+// insert 插入路由
+//
+// 参数:
+//   - path string: 完整路由
+//   - group bool: 是否为路由组（前缀匹配，注册无路由视图）
+//   - entry *routeEntry: 路由项
+func (tree *routeNode) insert(path string, group bool, entry *routeEntry) {
+	segments := strings.Split(path, "/")
+	if group {
+		// 路由组以 '/' 结尾，最后一个空片段仅表示边界
+		segments = segments[:len(segments)-1]
+	}
+	node := tree
+	for i, segment := range segments {
+		_, paramInfos := compilePath(segment)
+		if len(paramInfos) == 0 {
+			child, ok := node.statics[segment]
+			if !ok {
+				child = newRouteNode(segment)
+				node.statics[segment] = child
+			}
+			node = child
+			continue
+		}
+		if slices.ContainsFunc(paramInfos, func(paramInfo ParamInfo) bool { return matchesSlash(paramInfo.Converter) }) {
+			// 剩余全部路径编译为一个路径节点
+			rest := strings.Join(segments[i:], "/")
+			if group {
+				rest += "/"
+			}
+			node = node.child(&node.paths, rest, group)
+			break
+		}
+		node = node.child(&node.params, segment, false)
+	}
+	if group {
+		if node.noRoute == nil {
+			node.noRoute = entry
+		}
+	} else if node.route == nil {
+		node.route = entry
+	}
+}
+
+// child 获取或创建参数、路径子节点
+//
+// 参数:
+//   - children *[]*routeNode: 子节点列表
+//   - segment string: 路由片段
+//   - prefix bool: 是否为前缀匹配
+//
+// 返回:
+//   - *routeNode: 子节点
+func (node *routeNode) child(children *[]*routeNode, segment string, prefix bool) *routeNode {
+	for _, child := range *children {
+		if child.segment == segment {
+			return child
+		}
+	}
+	pattern, paramInfos := compilePath(segment)
+	if prefix {
+		pattern = "^" + pattern
+	} else {
+		pattern = "^" + pattern + "$"
+	}
+	child := newRouteNode(segment)
+	child.regex = regexp.MustCompile(pattern)
+	child.paramInfos = paramInfos
+	*children = append(*children, child)
+	return child
+}
+
+// routeSearch 单次路由查找的状态
+type routeSearch struct {
+	segments      []string     // 请求路径片段
+	params        []routeParam // 已匹配的参数
+	noRoute       *routeEntry  // 最深的已匹配路由组的无路由视图
+	noRouteDepth  int          // 无路由视图所在深度
+	noRouteParams []routeParam // 无路由视图匹配的参数
+}
+
+// find 查找路由
+//
+// 优先级: 静态片段 > 参数片段 > 路径片段，子树无法匹配时回溯尝试下一优先级；
+// 均无法匹配时回退到最深的已匹配路由组的无路由视图
+//
+// 参数:
+//   - path string: 请求路径
+//
+// 返回:
+//   - *routeEntry: 匹配的路由项，未匹配返回 nil
+//   - []routeParam: 匹配的参数
+func (tree *routeNode) find(path string) (*routeEntry, []routeParam) {
+	search := &routeSearch{
+		segments:     strings.Split(path, "/"),
+		noRouteDepth: -1,
+	}
+	entry := search.walk(tree, 0)
+	if entry != nil {
+		return entry, search.params
+	}
+	return search.noRoute, search.noRouteParams
+}
+
+// walk 深度优先匹配
+//
+// 参数:
+//   - node *routeNode: 当前节点
+//   - index int: 待匹配的片段下标
+//
+// 返回:
+//   - *routeEntry: 匹配的路由项
+func (search *routeSearch) walk(node *routeNode, index int) *routeEntry {
+	// 路由组需要至少还有一个片段（边界 '/'）
+	if node.noRoute != nil && index < len(search.segments) && index > search.noRouteDepth {
+		search.setNoRoute(node.noRoute, index)
+	}
+	if index == len(search.segments) {
+		return node.route
+	}
+
+	segment := search.segments[index]
+	if child, ok := node.statics[segment]; ok {
+		if entry := search.walk(child, index+1); entry != nil {
+			return entry
+		}
+	}
+
+	for _, child := range node.params {
+		size := len(search.params)
+		params, ok := matchParams(child.regex, child.paramInfos, segment, search.params)
+		search.params = params
+		if ok {
+			if entry := search.walk(child, index+1); entry != nil {
+				return entry
+			}
+		}
+		search.params = search.params[:size]
+	}
+
+	if len(node.paths) == 0 {
+		return nil
+	}
+	rest := strings.Join(search.segments[index:], "/")
+	for _, child := range node.paths {
+		size := len(search.params)
+		params, ok := matchParams(child.regex, child.paramInfos, rest, search.params)
+		search.params = params
+		if ok {
+			if child.route != nil {
+				return child.route
+			}
+			if child.noRoute != nil && index+1 > search.noRouteDepth {
+				search.setNoRoute(child.noRoute, index+1)
+			}
+		}
+		search.params = search.params[:size]
+	}
+	return nil
+}
+
+// setNoRoute 记录无路由视图
+//
+// 参数:
+//   - entry *routeEntry: 无路由视图
+//   - depth int: 所在深度
+func (search *routeSearch) setNoRoute(entry *routeEntry, depth int) {
+	search.noRoute = entry
+	search.noRouteDepth = depth
+	search.noRouteParams = slices.Clone(search.params)
+}
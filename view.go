@@ -2,7 +2,9 @@ package goi
 
 import (
 	"errors"
+	"maps"
 	"net/http"
+	"strings"
 
 	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
 )
@@ -24,6 +26,71 @@ type ViewSet struct {
 	Options  HandlerFunc // 查询支持的方法
 	TRACE    HandlerFunc // 回显请求
 	NoMethod HandlerFunc // 未注册或不支持的HTTP方法时的处理函数，默认返回 405
+
+	WebSocket *WebSocket // WebSocket 升级处理，GET 请求携带 Upgrade: websocket 时执行
+
+	Middlewares       Middlewares            // 视图中间件，作用于所有方法（包括 NoMethod）
+	MethodMiddlewares map[string]Middlewares // 方法中间件，key 为 HTTP 方法，在视图中间件之后执行
+}
+
+// Use 注册视图中间件，作用于所有方法
+//
+// 参数:
+//   - middleware ...Middleware: 中间件
+//
+// 返回:
+//   - ViewSet: 注册中间件后的视图副本
+func (viewSet ViewSet) Use(middleware ...Middleware) ViewSet {
+	middlewares := make(Middlewares, 0, len(viewSet.Middlewares)+len(middleware))
+	middlewares = append(middlewares, viewSet.Middlewares...)
+	viewSet.Middlewares = append(middlewares, middleware...)
+	return viewSet
+}
+
+// UseMethod 注册方法中间件，仅作用于指定的 HTTP 方法
+//
+// 参数:
+//   - method string: HTTP方法，如 http.MethodPost
+//   - middleware ...Middleware: 中间件
+//
+// 返回:
+//   - ViewSet: 注册中间件后的视图副本
+func (viewSet ViewSet) UseMethod(method string, middleware ...Middleware) ViewSet {
+	method = strings.ToUpper(method)
+	methodMiddlewares := make(map[string]Middlewares, len(viewSet.MethodMiddlewares)+1)
+	maps.Copy(methodMiddlewares, viewSet.MethodMiddlewares)
+	middlewares := make(Middlewares, 0, len(methodMiddlewares[method])+len(middleware))
+	middlewares = append(middlewares, methodMiddlewares[method]...)
+	methodMiddlewares[method] = append(middlewares, middleware...)
+	viewSet.MethodMiddlewares = methodMiddlewares
+	return viewSet
+}
+
+// GetMiddlewares 获取指定方法的视图中间件与方法中间件
+//
+// 参数:
+//   - method string: HTTP方法
+//
+// 返回:
+//   - Middlewares: 视图中间件在前，方法中间件在后
+//
+// 说明:
+//   - HEAD 未定义而回退到 GET 时，使用 GET 的方法中间件
+func (viewSet ViewSet) GetMiddlewares(method string) Middlewares {
+	method = strings.ToUpper(method)
+	if method == http.MethodHead && viewSet.HEAD == nil && viewSet.GET != nil {
+		method = http.MethodGet
+	}
+	methodMiddlewares := viewSet.MethodMiddlewares[method]
+	if len(viewSet.Middlewares) == 0 {
+		return methodMiddlewares
+	}
+	if len(methodMiddlewares) == 0 {
+		return viewSet.Middlewares
+	}
+	middlewares := make(Middlewares, 0, len(viewSet.Middlewares)+len(methodMiddlewares))
+	middlewares = append(middlewares, viewSet.Middlewares...)
+	return append(middlewares, methodMiddlewares...)
 }
 
 // GetHandlerFunc 获取视图方法
@@ -78,13 +145,30 @@ func (viewSet ViewSet) GetHandlerFunc(method string) (HandlerFunc, error) {
 	return handlerFunc, err
 }
 
+// getWebSocketHandler 获取 WebSocket 升级处理函数
+//
+// 参数:
+//   - r *http.Request: HTTP请求对象
+//
+// 返回:
+//   - HandlerFunc: GET 升级请求，或未定义 GET 时的普通 GET 请求返回升级处理函数，否则为 nil
+func (viewSet ViewSet) getWebSocketHandler(r *http.Request) HandlerFunc {
+	if viewSet.WebSocket == nil || r.Method != http.MethodGet {
+		return nil
+	}
+	if viewSet.GET != nil && !isWebSocketUpgrade(r) {
+		return nil
+	}
+	return viewSet.WebSocket.serve
+}
+
 // GetMethods 获取允许的HTTP方法列表
 //
 // 返回:
 //   - []string: 允许的HTTP方法列表
 func (viewSet ViewSet) GetMethods() []string {
 	var methods []string
-	if viewSet.GET != nil {
+	if viewSet.GET != nil || viewSet.WebSocket != nil {
 		methods = append(methods, http.MethodGet)
 	}
 	if viewSet.HEAD != nil {
@@ -0,0 +1,183 @@
+package goi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// recordMiddleware 记录执行顺序的中间件
+type recordMiddleware struct {
+	name   string
+	record *[]string
+	reject bool // 请求阶段直接返回 401
+}
+
+func (middleware recordMiddleware) ProcessRequest(request *Request) any {
+	*middleware.record = append(*middleware.record, middleware.name)
+	if middleware.reject {
+		return Response{Status: http.StatusUnauthorized, Data: "unauthorized"}
+	}
+	return nil
+}
+
+func (middleware recordMiddleware) ProcessException(request *Request, exception any) any {
+	*middleware.record = append(*middleware.record, "exception:"+middleware.name)
+	return Response{Status: http.StatusInternalServerError, Data: middleware.name}
+}
+
+func (middleware recordMiddleware) ProcessResponse(request *Request, response *Response) {}
+
+// TestHandlerViewSetMiddlewares 验证路由组、视图与方法中间件的组合顺序
+func TestHandlerViewSetMiddlewares(t *testing.T) {
+	var record []string
+	engine := &Engine{Router: newRouter()}
+	engine.Router.Use(recordMiddleware{name: "group", record: &record})
+
+	viewSet := ViewSet{
+		GET:      func(request *Request) any { return "get" },
+		POST:     func(request *Request) any { return "post" },
+		PUT:      func(request *Request) any { panic("put") },
+		NoMethod: func(request *Request) any { return "no-method" },
+	}
+	viewSet = viewSet.Use(recordMiddleware{name: "view", record: &record})
+	viewSet = viewSet.UseMethod(http.MethodPost, recordMiddleware{name: "auth", record: &record, reject: true})
+	viewSet = viewSet.UseMethod(http.MethodPut, recordMiddleware{name: "put", record: &record})
+	engine.Router.Path("item", "条目", viewSet)
+
+	cases := []struct {
+		method string
+		status int
+		record []string
+	}{
+		{http.MethodGet, http.StatusOK, []string{"group", "view"}},
+		{http.MethodHead, http.StatusOK, []string{"group", "view"}},
+		{http.MethodPost, http.StatusUnauthorized, []string{"group", "view", "auth"}},
+		{http.MethodPut, http.StatusInternalServerError, []string{"group", "view", "put", "exception:put"}},
+		{http.MethodDelete, http.StatusOK, []string{"group", "view"}},
+	}
+	for _, c := range cases {
+		record = nil
+		request := &Request{
+			Object:     httptest.NewRequest(c.method, "/item", nil),
+			PathParams: make(Params),
+			Params:     make(Params),
+		}
+		response := engine.handler(request)
+		if response.Status != c.status || !slices.Equal(record, c.record) {
+			t.Errorf("%v /item = %d %v, want %d %v", c.method, response.Status, record, c.status, c.record)
+		}
+	}
+}
+
+// TestHandlerTimeout 验证路由超时的继承与覆盖
+func TestHandlerTimeout(t *testing.T) {
+	engine := &Engine{Router: newRouter()}
+	slow := ViewSet{GET: func(request *Request) any {
+		select {
+		case <-request.Context().Done():
+			return "canceled"
+		case <-time.After(time.Second):
+			return "done"
+		}
+	}}
+	api := engine.Router.Include("api", "接口").Timeout(10 * time.Millisecond)
+	api.Path("slow", "超时", slow)
+	api.Path("long", "覆盖超时", slow).Timeout(2 * time.Second)
+
+	cases := []struct {
+		path   string
+		status int
+	}{
+		{"/api/slow", http.StatusGatewayTimeout},
+		{"/api/long", http.StatusOK},
+	}
+	for _, c := range cases {
+		request := &Request{
+			Object:     httptest.NewRequest(http.MethodGet, c.path, nil),
+			PathParams: make(Params),
+			Params:     make(Params),
+		}
+		response := engine.handler(request)
+		if response.Status != c.status {
+			t.Errorf("GET %v = %d, want %d", c.path, response.Status, c.status)
+		}
+	}
+}
+
+// headerMiddleware 在响应阶段设置响应头
+type headerMiddleware struct{}
+
+func (middleware headerMiddleware) ProcessRequest(request *Request) any { return nil }
+
+func (middleware headerMiddleware) ProcessException(request *Request, exception any) any { return nil }
+
+func (middleware headerMiddleware) ProcessResponse(request *Request, response *Response) {
+	response.Header().Set("X-Middleware", "ok")
+	// 读取视图写入的参数
+	for key, value := range request.Params {
+		response.Header().Set("X-Param-"+key, fmt.Sprint(value))
+	}
+}
+
+// TestHandlerTimeoutOutlive 验证超时后仍在运行的视图修改请求对象不会产生数据竞争，超时响应经过中间件
+func TestHandlerTimeoutOutlive(t *testing.T) {
+	engine := NewHTTPServer()
+	engine.Log = NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer engine.Log.File.Close()
+	engine.Settings = newSettings()
+
+	key := NewContextKey[int]("counter")
+	finished := make(chan struct{})
+	engine.Router.Use(headerMiddleware{})
+	engine.Router.Path("slow", "超时", ViewSet{GET: func(request *Request) any {
+		defer close(finished)
+		<-request.Context().Done()
+		// 超时后继续修改请求对象
+		for i := 0; i < 100; i++ {
+			request.WithContext(context.WithValue(request.Context(), key, i))
+			key.Set(request, i)
+			request.CheckPreconditions(`"v1"`, time.Time{})
+			request.Params.Set("late", i)
+			request.PathParams.Set("late", i)
+		}
+		return "late"
+	}}).Timeout(10 * time.Millisecond)
+	engine.Router.Path("fast", "未超时", ViewSet{GET: func(request *Request) any {
+		request.Params.Set("view", "fast")
+		return "fast"
+	}}).Timeout(time.Second)
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if recorder.Code != http.StatusGatewayTimeout || recorder.Header().Get("X-Middleware") != "ok" {
+		t.Errorf("timeout = %d %v", recorder.Code, recorder.Header())
+	}
+	if recorder.Header().Get("ETag") != "" {
+		t.Errorf("late ETag leaked into timeout response: %q", recorder.Header().Get("ETag"))
+	}
+	<-finished
+
+	// 未超时时视图写入的参数同步到当前请求
+	recorder = httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if recorder.Code != http.StatusOK || recorder.Header().Get("X-Param-View") != "fast" {
+		t.Errorf("fast = %d %v", recorder.Code, recorder.Header())
+	}
+
+	// 客户端断开
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	finished = make(chan struct{})
+	recorder = httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(ctx))
+	if recorder.Code != http.StatusServiceUnavailable || recorder.Header().Get("X-Middleware") != "ok" {
+		t.Errorf("canceled = %d %v", recorder.Code, recorder.Header())
+	}
+	<-finished
+}
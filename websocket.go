@@ -0,0 +1,368 @@
+package goi
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
+)
+
+// WebSocket 握手常量
+const (
+	webSocketGUID    = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	webSocketVersion = "13"
+)
+
+// DefaultWebSocketReadLimit 单条 WebSocket 消息的默认大小上限（解压后）
+const DefaultWebSocketReadLimit = 1 << 20
+
+// WebSocketCloseTimeout 发送关闭帧后等待对端关闭帧的时间
+const WebSocketCloseTimeout = 5 * time.Second
+
+// WebSocket 升级处理配置，作为 ViewSet.WebSocket 使用
+//
+// 说明:
+//   - GET 请求携带 Upgrade: websocket 时执行，请求依次经过路由、视图与方法中间件
+//   - 握手成功返回 101 响应，中间件的 ProcessResponse 可在握手前设置响应头，访问日志记录 101 与连接时长
+//   - ViewSet 未定义 GET 时，普通 GET 请求返回 426 Upgrade Required
+//   - 连接独立于路由超时，服务停止时发送 1001 关闭帧
+//
+// 示例:
+//
+//	Server.Router.Path("ws/echo", "回显", goi.ViewSet{
+//		WebSocket: &goi.WebSocket{
+//			Handler: func(conn *goi.WebSocketConn) error {
+//				for {
+//					messageType, data, err := conn.ReadMessage()
+//					if err != nil {
+//						return err
+//					}
+//					if err = conn.WriteMessage(messageType, data); err != nil {
+//						return err
+//					}
+//				}
+//			},
+//		},
+//	})
+type WebSocket struct {
+	// 连接处理函数，返回后框架发送关闭帧并断开连接
+	Handler func(conn *WebSocketConn) error
+
+	// 支持的子协议，按服务端偏好排列，选中客户端 Sec-WebSocket-Protocol 中第一个匹配的子协议
+	Subprotocols []string
+
+	// 来源校验，返回 false 时响应 403，为 nil 时仅允许无 Origin 或与 Host 相同的来源
+	CheckOrigin func(request *Request) bool
+
+	// 单条消息的大小上限（单位：字节，解压后），0 表示 DefaultWebSocketReadLimit，超出时以 1009 关闭连接
+	ReadLimit int64
+
+	// 是否启用 permessage-deflate 压缩（RFC 7692），客户端未请求时不生效
+	Compression bool
+}
+
+// isWebSocketUpgrade 检查请求是否为 WebSocket 升级请求
+//
+// 参数:
+//   - r *http.Request: HTTP请求对象
+//
+// 返回:
+//   - bool: Upgrade 请求头是否包含 websocket
+func isWebSocketUpgrade(r *http.Request) bool {
+	return headerContainsToken(r.Header, "Upgrade", "websocket")
+}
+
+// headerContainsToken 检查逗号分隔的请求头是否包含指定值（不区分大小写）
+func headerContainsToken(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, item := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// serve 校验握手请求并返回 101 响应，实现 HandlerFunc
+//
+// 参数:
+//   - request *Request: HTTP请求对象
+//
+// 返回:
+//   - any: 握手成功时为 101 响应，数据为接管连接的 RawHandler，否则为 400/403/426 响应
+func (webSocket *WebSocket) serve(request *Request) any {
+	r := request.Object
+	if !isWebSocketUpgrade(r) || !headerContainsToken(r.Header, "Connection", "upgrade") {
+		response := Response{Status: http.StatusUpgradeRequired, Data: i18n.T("server.websocket_upgrade_required")}
+		response.Header().Set("Upgrade", "websocket")
+		response.Header().Set("Connection", "Upgrade")
+		return response
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != webSocketVersion {
+		response := Response{Status: http.StatusUpgradeRequired, Data: i18n.T("server.websocket_bad_handshake", map[string]any{
+			"header": "Sec-WebSocket-Version",
+		})}
+		response.Header().Set("Sec-WebSocket-Version", webSocketVersion)
+		return response
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return Response{Status: http.StatusBadRequest, Data: i18n.T("server.websocket_bad_handshake", map[string]any{
+			"header": "Sec-WebSocket-Key",
+		})}
+	}
+	checkOrigin := webSocket.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(request) {
+		return Response{Status: http.StatusForbidden, Data: i18n.T("server.websocket_origin_denied", map[string]any{
+			"origin": r.Header.Get("Origin"),
+		})}
+	}
+
+	response := Response{Status: http.StatusSwitchingProtocols}
+	header := response.Header()
+	header.Set("Upgrade", "websocket")
+	header.Set("Connection", "Upgrade")
+	sum := sha1.Sum([]byte(key + webSocketGUID))
+	header.Set("Sec-WebSocket-Accept", base64.StdEncoding.EncodeToString(sum[:]))
+	subprotocol := webSocket.selectSubprotocol(r)
+	if subprotocol != "" {
+		header.Set("Sec-WebSocket-Protocol", subprotocol)
+	}
+	compress := webSocket.Compression && acceptDeflate(r.Header.Values("Sec-WebSocket-Extensions"))
+	if compress {
+		header.Set("Sec-WebSocket-Extensions", "permessage-deflate; server_no_context_takeover; client_no_context_takeover")
+	}
+	readLimit := webSocket.ReadLimit
+	if readLimit <= 0 {
+		readLimit = DefaultWebSocketReadLimit
+	}
+	response.Data = RawHandler(func(w http.ResponseWriter, r *Request) error {
+		return webSocket.hijack(w, r, subprotocol, compress, readLimit)
+	})
+	return response
+}
+
+// hijack 接管连接、写入 101 响应并执行连接处理函数
+//
+// 参数:
+//   - w http.ResponseWriter: 响应写入器
+//   - request *Request: HTTP请求对象
+//   - subprotocol string: 选中的子协议
+//   - compress bool: 是否启用 permessage-deflate
+//   - readLimit int64: 单条消息大小上限
+//
+// 返回:
+//   - error: 接管连接过程中的错误信息，连接处理函数的错误仅记录日志
+func (webSocket *WebSocket) hijack(w http.ResponseWriter, request *Request, subprotocol string, compress bool, readLimit int64) error {
+	netConn, readWriter, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return err
+	}
+	setResponseStatus(w, http.StatusSwitchingProtocols)
+
+	header := w.Header().Clone()
+	for _, name := range []string{"Content-Length", "Content-Type", "Transfer-Encoding"} {
+		header.Del(name)
+	}
+	_ = netConn.SetWriteDeadline(time.Now().Add(WebSocketCloseTimeout))
+	_, err = readWriter.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	if err == nil {
+		err = header.Write(readWriter)
+	}
+	if err == nil {
+		_, err = readWriter.WriteString("\r\n")
+	}
+	if err == nil {
+		err = readWriter.Flush()
+	}
+	if err != nil {
+		netConn.Close()
+		return err
+	}
+	_ = netConn.SetWriteDeadline(time.Time{})
+
+	// 连接独立于请求上下文，路由超时与视图返回后不取消
+	ctx, cancel := context.WithCancel(context.WithoutCancel(request.Context()))
+	conn := &WebSocketConn{
+		request:     request,
+		conn:        netConn,
+		reader:      readWriter.Reader,
+		writer:      bufio.NewWriter(netConn),
+		ctx:         ctx,
+		cancel:      cancel,
+		subprotocol: subprotocol,
+		compress:    compress,
+		readLimit:   readLimit,
+	}
+	if request.engine != nil {
+		request.engine.webSockets.add(conn)
+		defer request.engine.webSockets.remove(conn)
+	}
+
+	err = webSocket.Handler(conn)
+	conn.finish(err)
+	if err != nil && !isWebSocketClosed(err) {
+		request.Log().Warning(err)
+	}
+	return nil
+}
+
+// selectSubprotocol 按服务端偏好选择子协议
+//
+// 参数:
+//   - r *http.Request: HTTP请求对象
+//
+// 返回:
+//   - string: 选中的子协议，没有匹配时为空
+func (webSocket *WebSocket) selectSubprotocol(r *http.Request) string {
+	for _, subprotocol := range webSocket.Subprotocols {
+		if headerContainsToken(r.Header, "Sec-WebSocket-Protocol", subprotocol) {
+			return subprotocol
+		}
+	}
+	return ""
+}
+
+// sameOrigin 默认来源校验，允许无 Origin 或 Origin 主机与 Host 相同的请求
+func sameOrigin(request *Request) bool {
+	origin := request.Object.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(originURL.Host, request.Object.Host)
+}
+
+// acceptDeflate 检查客户端的 permessage-deflate 请求是否可以接受
+//
+// 参数:
+//   - extensions []string: Sec-WebSocket-Extensions 请求头
+//
+// 返回:
+//   - bool: 存在可接受的 permessage-deflate 请求
+//
+// 说明:
+//   - 服务端固定使用 32KB 窗口且不保留上下文，请求缩小 server_max_window_bits 时不接受
+func acceptDeflate(extensions []string) bool {
+	for _, value := range extensions {
+		for _, offer := range strings.Split(value, ",") {
+			params := strings.Split(offer, ";")
+			if strings.TrimSpace(params[0]) != "permessage-deflate" {
+				continue
+			}
+			accepted := true
+			for _, param := range params[1:] {
+				name, bits, _ := strings.Cut(strings.TrimSpace(param), "=")
+				switch name {
+				case "client_no_context_takeover", "server_no_context_takeover", "client_max_window_bits":
+				case "server_max_window_bits":
+					accepted = strings.Trim(bits, `"`) == "15"
+				default:
+					accepted = false
+				}
+			}
+			if accepted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setResponseStatus 记录接管连接后的状态码，供访问日志使用
+//
+// 参数:
+//   - w http.ResponseWriter: 响应写入器，逐层 Unwrap 查找 *ResponseWriter
+//   - code int: HTTP状态码
+func setResponseStatus(w http.ResponseWriter, code int) {
+	for {
+		switch writer := w.(type) {
+		case *ResponseWriter:
+			writer.Status = code
+			return
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = writer.Unwrap()
+		default:
+			return
+		}
+	}
+}
+
+// webSocketManager Engine 已建立的 WebSocket 连接，服务停止时统一关闭
+type webSocketManager struct {
+	lock      sync.Mutex
+	conns     map[*WebSocketConn]struct{}
+	waitGroup sync.WaitGroup
+}
+
+func newWebSocketManager() *webSocketManager {
+	return &webSocketManager{conns: make(map[*WebSocketConn]struct{})}
+}
+
+// add 登记连接
+func (manager *webSocketManager) add(conn *WebSocketConn) {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	manager.conns[conn] = struct{}{}
+	manager.waitGroup.Add(1)
+}
+
+// remove 注销连接
+func (manager *webSocketManager) remove(conn *WebSocketConn) {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	if _, ok := manager.conns[conn]; ok {
+		delete(manager.conns, conn)
+		manager.waitGroup.Done()
+	}
+}
+
+// closeAll 向所有连接发送 1001 关闭帧并等待连接处理函数返回
+//
+// 参数:
+//   - ctx context.Context: 取消后强制断开剩余连接
+//
+// 返回:
+//   - int: 关闭的连接数量
+func (manager *webSocketManager) closeAll(ctx context.Context) int {
+	manager.lock.Lock()
+	conns := make([]*WebSocketConn, 0, len(manager.conns))
+	for conn := range manager.conns {
+		conns = append(conns, conn)
+	}
+	manager.lock.Unlock()
+	if len(conns) == 0 {
+		return 0
+	}
+
+	for _, conn := range conns {
+		_ = conn.Close(WebSocketCloseGoingAway, "server shutting down")
+	}
+	done := make(chan struct{})
+	go func() {
+		manager.waitGroup.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		for _, conn := range conns {
+			conn.conn.Close()
+		}
+	}
+	return len(conns)
+}
@@ -0,0 +1,527 @@
+package goi
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NeverStopDreamingWang/goi/v2/internal/i18n"
+)
+
+// WebSocketMessageType WebSocket 数据消息类型
+type WebSocketMessageType int
+
+// WebSocket 数据消息类型
+const (
+	WebSocketText   WebSocketMessageType = 1 // 文本消息，内容为 UTF-8
+	WebSocketBinary WebSocketMessageType = 2 // 二进制消息
+)
+
+// WebSocket 关闭状态码（RFC 6455 7.4.1）
+const (
+	WebSocketCloseNormal          = 1000 // 正常关闭
+	WebSocketCloseGoingAway       = 1001 // 服务停止或页面离开
+	WebSocketCloseProtocolError   = 1002 // 协议错误
+	WebSocketCloseUnsupportedData = 1003 // 不支持的数据类型
+	WebSocketCloseNoStatus        = 1005 // 关闭帧未包含状态码
+	WebSocketCloseAbnormal        = 1006 // 连接异常断开，不会出现在关闭帧中
+	WebSocketCloseInvalidPayload  = 1007 // 消息内容与类型不符，如文本不是 UTF-8
+	WebSocketClosePolicyViolation = 1008 // 违反策略
+	WebSocketCloseMessageTooBig   = 1009 // 消息超过大小上限
+	WebSocketCloseInternalError   = 1011 // 服务端内部错误
+)
+
+// 帧操作码
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// deflateTail permessage-deflate 消息省略的同步块尾部，解压时补回并追加一个结束块
+const deflateTail = "\x00\x00\xff\xff\x01\x00\x00\xff\xff"
+
+// WebSocketCloseError 连接关闭错误，对端发送关闭帧或协议错误时由 ReadMessage 返回
+//
+// 字段:
+//   - Code int: 关闭状态码
+//   - Reason string: 关闭原因
+type WebSocketCloseError struct {
+	Code   int
+	Reason string
+}
+
+// Error 实现 error 接口
+func (err *WebSocketCloseError) Error() string {
+	return i18n.T("server.websocket_closed", map[string]any{
+		"code":   err.Code,
+		"reason": err.Reason,
+	})
+}
+
+// isWebSocketClosed 检查错误是否为正常的连接关闭
+func isWebSocketClosed(err error) bool {
+	var closeErr *WebSocketCloseError
+	if errors.As(err, &closeErr) {
+		return closeErr.Code == WebSocketCloseNormal || closeErr.Code == WebSocketCloseGoingAway || closeErr.Code == WebSocketCloseNoStatus
+	}
+	return errors.Is(err, net.ErrClosed) || errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) || errors.Is(err, os.ErrDeadlineExceeded)
+}
+
+// WebSocketConn WebSocket 连接
+//
+// 说明:
+//   - 读取方法不能并发调用，应在连接处理函数中循环读取
+//   - 写入方法可在多个 goroutine 中并发调用
+//   - 收到 ping 时自动回复 pong
+type WebSocketConn struct {
+	request     *Request
+	conn        net.Conn
+	reader      *bufio.Reader
+	ctx         context.Context
+	cancel      context.CancelFunc
+	subprotocol string
+	compress    bool
+	readLimit   int64
+
+	writeLock     sync.Mutex
+	writer        *bufio.Writer
+	closeSent     bool // 已发送关闭帧
+	closeReceived bool // 已收到关闭帧，仅读取方访问
+}
+
+// Request 获取握手请求
+//
+// 返回:
+//   - *Request: HTTP请求对象
+func (conn *WebSocketConn) Request() *Request {
+	return conn.request
+}
+
+// Context 获取连接上下文
+//
+// 返回:
+//   - context.Context: 连接关闭或服务停止时取消
+func (conn *WebSocketConn) Context() context.Context {
+	return conn.ctx
+}
+
+// Subprotocol 获取协商的子协议
+//
+// 返回:
+//   - string: 子协议，未协商时为空
+func (conn *WebSocketConn) Subprotocol() string {
+	return conn.subprotocol
+}
+
+// RemoteAddr 获取客户端地址
+//
+// 返回:
+//   - net.Addr: 客户端地址
+func (conn *WebSocketConn) RemoteAddr() net.Addr {
+	return conn.conn.RemoteAddr()
+}
+
+// frame 已解码的帧
+type frame struct {
+	fin     bool
+	rsv1    bool
+	opcode  byte
+	payload []byte
+}
+
+// protocolError 协议错误，读取时以 code 关闭连接
+type protocolError struct {
+	code   int
+	reason string
+}
+
+// Error 实现 error 接口
+func (err *protocolError) Error() string {
+	return err.reason
+}
+
+// readFrame 读取一个客户端帧
+//
+// 参数:
+//   - remaining int64: 当前消息剩余可读取的字节数
+//
+// 返回:
+//   - frame: 解除掩码后的帧
+//   - error: 读取或协议错误
+func (conn *WebSocketConn) readFrame(remaining int64) (frame, error) {
+	var header [2]byte
+	_, err := io.ReadFull(conn.reader, header[:])
+	if err != nil {
+		return frame{}, err
+	}
+	current := frame{
+		fin:    header[0]&0x80 != 0,
+		rsv1:   header[0]&0x40 != 0,
+		opcode: header[0] & 0x0F,
+	}
+	if header[0]&0x30 != 0 {
+		return frame{}, &protocolError{WebSocketCloseProtocolError, "unexpected reserved bits"}
+	}
+	if header[1]&0x80 == 0 {
+		return frame{}, &protocolError{WebSocketCloseProtocolError, "client frame is not masked"}
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err = io.ReadFull(conn.reader, extended[:]); err != nil {
+			return frame{}, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err = io.ReadFull(conn.reader, extended[:]); err != nil {
+			return frame{}, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if current.opcode >= opClose {
+		if !current.fin || length > 125 {
+			return frame{}, &protocolError{WebSocketCloseProtocolError, "invalid control frame"}
+		}
+		if current.rsv1 {
+			return frame{}, &protocolError{WebSocketCloseProtocolError, "unexpected reserved bits"}
+		}
+	} else if length > uint64(remaining) {
+		return frame{}, &protocolError{WebSocketCloseMessageTooBig, "message too big"}
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(conn.reader, mask[:]); err != nil {
+		return frame{}, err
+	}
+	current.payload = make([]byte, length)
+	if _, err = io.ReadFull(conn.reader, current.payload); err != nil {
+		return frame{}, err
+	}
+	for i := range current.payload {
+		current.payload[i] ^= mask[i%4]
+	}
+	return current, nil
+}
+
+// ReadMessage 读取一条数据消息
+//
+// 返回:
+//   - WebSocketMessageType: 消息类型
+//   - []byte: 消息内容，分片与压缩消息已合并解压
+//   - error: 读取过程中的错误信息，对端关闭或协议错误时为 *WebSocketCloseError
+//
+// 说明:
+//   - 自动回复 ping，忽略 pong
+//   - 协议错误、消息过大或文本不是 UTF-8 时发送对应关闭帧后返回
+func (conn *WebSocketConn) ReadMessage() (WebSocketMessageType, []byte, error) {
+	var messageType WebSocketMessageType
+	var message []byte
+	compressed := false
+	for {
+		current, err := conn.readFrame(conn.readLimit - int64(len(message)))
+		if err != nil {
+			return 0, nil, conn.fail(err)
+		}
+		switch current.opcode {
+		case opPing:
+			err = conn.writeFrame(opPong, false, current.payload)
+			if err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			return 0, nil, conn.receiveClose(current.payload)
+		case opText, opBinary:
+			if messageType != 0 {
+				return 0, nil, conn.fail(&protocolError{WebSocketCloseProtocolError, "expected continuation frame"})
+			}
+			if current.rsv1 && !conn.compress {
+				return 0, nil, conn.fail(&protocolError{WebSocketCloseProtocolError, "unexpected reserved bits"})
+			}
+			messageType = WebSocketMessageType(current.opcode)
+			compressed = current.rsv1
+		case opContinuation:
+			if messageType == 0 || current.rsv1 {
+				return 0, nil, conn.fail(&protocolError{WebSocketCloseProtocolError, "unexpected continuation frame"})
+			}
+		default:
+			return 0, nil, conn.fail(&protocolError{WebSocketCloseProtocolError, "unknown opcode"})
+		}
+		message = append(message, current.payload...)
+		if current.fin {
+			break
+		}
+	}
+
+	if compressed {
+		reader := flate.NewReader(io.MultiReader(bytes.NewReader(message), strings.NewReader(deflateTail)))
+		decompressed, err := io.ReadAll(io.LimitReader(reader, conn.readLimit+1))
+		reader.Close()
+		if err != nil {
+			return 0, nil, conn.fail(&protocolError{WebSocketCloseInvalidPayload, "invalid compressed data"})
+		}
+		if int64(len(decompressed)) > conn.readLimit {
+			return 0, nil, conn.fail(&protocolError{WebSocketCloseMessageTooBig, "message too big"})
+		}
+		message = decompressed
+	}
+	if messageType == WebSocketText && !utf8.Valid(message) {
+		return 0, nil, conn.fail(&protocolError{WebSocketCloseInvalidPayload, "invalid UTF-8"})
+	}
+	return messageType, message, nil
+}
+
+// ReadJSON 读取一条消息并解码 JSON
+//
+// 参数:
+//   - value any: 解码目标，需为指针
+//
+// 返回:
+//   - error: 读取或解码过程中的错误信息
+func (conn *WebSocketConn) ReadJSON(value any) error {
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+// WriteMessage 发送一条数据消息
+//
+// 参数:
+//   - messageType WebSocketMessageType: 消息类型
+//   - data []byte: 消息内容
+//
+// 返回:
+//   - error: 写入过程中的错误信息，已发送关闭帧时为 net.ErrClosed
+func (conn *WebSocketConn) WriteMessage(messageType WebSocketMessageType, data []byte) error {
+	if messageType != WebSocketText && messageType != WebSocketBinary {
+		return &protocolError{WebSocketCloseProtocolError, "invalid message type"}
+	}
+	if !conn.compress {
+		return conn.writeFrame(byte(messageType), false, data)
+	}
+	var buffer bytes.Buffer
+	writer, err := flate.NewWriter(&buffer, flate.DefaultCompression)
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(data); err != nil {
+		return err
+	}
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+	compressed := bytes.TrimSuffix(buffer.Bytes(), []byte(deflateTail[:4]))
+	return conn.writeFrame(byte(messageType), true, compressed)
+}
+
+// WriteJSON 将数据编码为 JSON 并作为文本消息发送
+//
+// 参数:
+//   - value any: 消息数据
+//
+// 返回:
+//   - error: 编码或写入过程中的错误信息
+func (conn *WebSocketConn) WriteJSON(value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(WebSocketText, data)
+}
+
+// Ping 发送 ping 帧
+//
+// 参数:
+//   - data []byte: 附带数据，最多 125 字节
+//
+// 返回:
+//   - error: 写入过程中的错误信息
+func (conn *WebSocketConn) Ping(data []byte) error {
+	if len(data) > 125 {
+		return &protocolError{WebSocketCloseProtocolError, "control frame too long"}
+	}
+	return conn.writeFrame(opPing, false, data)
+}
+
+// Close 发送关闭帧并取消连接上下文
+//
+// 参数:
+//   - code int: 关闭状态码，如 WebSocketCloseNormal
+//   - reason string: 关闭原因，最多 123 字节
+//
+// 返回:
+//   - error: 写入过程中的错误信息
+//
+// 说明:
+//   - 正在阻塞的 ReadMessage 会在收到对端关闭帧或 WebSocketCloseTimeout 后返回
+//   - 连接由框架在处理函数返回后断开
+func (conn *WebSocketConn) Close(code int, reason string) error {
+	err := conn.writeClose(code, reason)
+	conn.cancel()
+	_ = conn.conn.SetReadDeadline(time.Now().Add(WebSocketCloseTimeout))
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// writeFrame 写入一个未分片的服务端帧
+//
+// 参数:
+//   - opcode byte: 操作码
+//   - rsv1 bool: 是否为压缩消息
+//   - payload []byte: 帧数据
+//
+// 返回:
+//   - error: 写入过程中的错误信息
+func (conn *WebSocketConn) writeFrame(opcode byte, rsv1 bool, payload []byte) error {
+	conn.writeLock.Lock()
+	defer conn.writeLock.Unlock()
+	if conn.closeSent {
+		return net.ErrClosed
+	}
+	if opcode == opClose {
+		conn.closeSent = true
+	}
+
+	header := []byte{0x80 | opcode, 0}
+	if rsv1 {
+		header[0] |= 0x40
+	}
+	switch length := len(payload); {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+	_ = conn.conn.SetWriteDeadline(time.Now().Add(WebSocketCloseTimeout))
+	defer conn.conn.SetWriteDeadline(time.Time{})
+	if _, err := conn.writer.Write(header); err != nil {
+		return err
+	}
+	if _, err := conn.writer.Write(payload); err != nil {
+		return err
+	}
+	return conn.writer.Flush()
+}
+
+// writeClose 发送关闭帧，仅首次调用生效
+//
+// 参数:
+//   - code int: 关闭状态码，WebSocketCloseNoStatus 时发送空关闭帧
+//   - reason string: 关闭原因
+//
+// 返回:
+//   - error: 写入过程中的错误信息
+func (conn *WebSocketConn) writeClose(code int, reason string) error {
+	var payload []byte
+	if code != WebSocketCloseNoStatus {
+		if len(reason) > 123 {
+			reason = reason[:123]
+		}
+		payload = binary.BigEndian.AppendUint16(nil, uint16(code))
+		payload = append(payload, reason...)
+	}
+	return conn.writeFrame(opClose, false, payload)
+}
+
+// receiveClose 处理对端关闭帧，回复关闭帧后返回关闭错误
+//
+// 参数:
+//   - payload []byte: 关闭帧数据
+//
+// 返回:
+//   - error: *WebSocketCloseError 或协议错误
+func (conn *WebSocketConn) receiveClose(payload []byte) error {
+	conn.closeReceived = true
+	closeErr := &WebSocketCloseError{Code: WebSocketCloseNoStatus}
+	if len(payload) == 1 || (len(payload) >= 2 && !utf8.Valid(payload[2:])) {
+		return conn.fail(&protocolError{WebSocketCloseProtocolError, "invalid close frame"})
+	}
+	if len(payload) >= 2 {
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+		if !validCloseCode(closeErr.Code) {
+			return conn.fail(&protocolError{WebSocketCloseProtocolError, "invalid close code"})
+		}
+	}
+	_ = conn.writeClose(closeErr.Code, "")
+	conn.cancel()
+	return closeErr
+}
+
+// validCloseCode 检查关闭帧中的状态码是否合法
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011, code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+// fail 处理读取错误，协议错误时发送关闭帧
+//
+// 参数:
+//   - err error: 读取错误
+//
+// 返回:
+//   - error: 协议错误转换为 *WebSocketCloseError，其它错误原样返回
+func (conn *WebSocketConn) fail(err error) error {
+	conn.cancel()
+	var protoErr *protocolError
+	if errors.As(err, &protoErr) {
+		_ = conn.writeClose(protoErr.code, protoErr.reason)
+		return &WebSocketCloseError{Code: protoErr.code, Reason: protoErr.reason}
+	}
+	return err
+}
+
+// finish 连接处理函数返回后完成关闭握手并断开连接
+//
+// 参数:
+//   - err error: 连接处理函数返回的错误，非关闭错误时以 1011 关闭
+func (conn *WebSocketConn) finish(err error) {
+	conn.cancel()
+	code, reason := WebSocketCloseNormal, ""
+	if err != nil && !isWebSocketClosed(err) {
+		code, reason = WebSocketCloseInternalError, "internal error"
+	}
+	_ = conn.writeClose(code, reason)
+	if !conn.closeReceived {
+		// 等待对端关闭帧，丢弃期间的数据帧
+		_ = conn.conn.SetReadDeadline(time.Now().Add(WebSocketCloseTimeout))
+		for {
+			current, readErr := conn.readFrame(conn.readLimit)
+			if readErr != nil || current.opcode == opClose {
+				break
+			}
+		}
+	}
+	conn.conn.Close()
+}
@@ -0,0 +1,228 @@
+package goi
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// statusMiddleware 记录 ProcessResponse 收到的状态码并设置响应头
+type statusMiddleware struct {
+	status chan int
+}
+
+func (middleware statusMiddleware) ProcessRequest(request *Request) any { return nil }
+
+func (middleware statusMiddleware) ProcessException(request *Request, exception any) any { return nil }
+
+func (middleware statusMiddleware) ProcessResponse(request *Request, response *Response) {
+	response.Header().Set("X-Middleware", "ok")
+	middleware.status <- response.Status
+}
+
+// wsClient 测试用的 WebSocket 客户端
+type wsClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// dialWebSocket 发起握手请求
+func dialWebSocket(t *testing.T, server *httptest.Server, path string, header http.Header) (*wsClient, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Sec-WebSocket-Version", "13")
+	request.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	for name, values := range header {
+		request.Header[name] = values
+	}
+	if err = request.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return &wsClient{t: t, conn: conn, reader: reader}, response
+}
+
+// write 发送带掩码的客户端帧
+func (client *wsClient) write(fin bool, rsv1 bool, opcode byte, payload []byte) {
+	client.t.Helper()
+	header := []byte{opcode, 0x80}
+	if fin {
+		header[0] |= 0x80
+	}
+	if rsv1 {
+		header[0] |= 0x40
+	}
+	if len(payload) <= 125 {
+		header[1] |= byte(len(payload))
+	} else {
+		header[1] |= 126
+		header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+	}
+	mask := []byte{1, 2, 3, 4}
+	masked := make([]byte, len(payload))
+	for i := range payload {
+		masked[i] = payload[i] ^ mask[i%4]
+	}
+	if _, err := client.conn.Write(append(append(header, mask...), masked...)); err != nil {
+		client.t.Fatal(err)
+	}
+}
+
+// read 读取服务端帧
+func (client *wsClient) read() (byte, bool, []byte) {
+	client.t.Helper()
+	var header [2]byte
+	if _, err := io.ReadFull(client.reader, header[:]); err != nil {
+		client.t.Fatal(err)
+	}
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		var extended [2]byte
+		if _, err := io.ReadFull(client.reader, extended[:]); err != nil {
+			client.t.Fatal(err)
+		}
+		length = int(binary.BigEndian.Uint16(extended[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(client.reader, payload); err != nil {
+		client.t.Fatal(err)
+	}
+	return header[0] & 0x0F, header[0]&0x40 != 0, payload
+}
+
+// expectClose 读取关闭帧并校验状态码
+func (client *wsClient) expectClose(code int) {
+	client.t.Helper()
+	opcode, _, payload := client.read()
+	if opcode != opClose || len(payload) < 2 || int(binary.BigEndian.Uint16(payload)) != code {
+		client.t.Fatalf("frame = %x %q, want close %d", opcode, payload, code)
+	}
+}
+
+// TestWebSocket 验证握手、中间件、回显、分片、ping、压缩、读取上限、来源校验与服务停止
+func TestWebSocket(t *testing.T) {
+	engine := NewHTTPServer()
+	engine.Log = NewLogger(filepath.Join(t.TempDir(), "server.log"))
+	defer engine.Log.File.Close()
+
+	echo := func(conn *WebSocketConn) error {
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return err
+			}
+			if err = conn.WriteMessage(messageType, data); err != nil {
+				return err
+			}
+		}
+	}
+	status := make(chan int, 8)
+	engine.Router.Use(statusMiddleware{status: status})
+	engine.Router.Path("ws", "回显", ViewSet{WebSocket: &WebSocket{
+		Handler:      echo,
+		Subprotocols: []string{"json", "chat"},
+		ReadLimit:    64,
+		Compression:  true,
+	}})
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	client, response := dialWebSocket(t, server, "/ws", http.Header{"Sec-Websocket-Protocol": {"chat, other"}})
+	if response.StatusCode != http.StatusSwitchingProtocols ||
+		response.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" ||
+		response.Header.Get("Sec-WebSocket-Protocol") != "chat" ||
+		response.Header.Get("X-Middleware") != "ok" {
+		t.Fatalf("handshake = %d %v", response.StatusCode, response.Header)
+	}
+	if code := <-status; code != http.StatusSwitchingProtocols {
+		t.Errorf("ProcessResponse status = %d", code)
+	}
+
+	client.write(true, false, opText, []byte("hello"))
+	if opcode, _, payload := client.read(); opcode != opText || string(payload) != "hello" {
+		t.Errorf("echo = %x %q", opcode, payload)
+	}
+	client.write(false, false, opBinary, []byte("frag"))
+	client.write(true, false, opPing, []byte("p"))
+	client.write(true, false, opContinuation, []byte("ment"))
+	if opcode, _, payload := client.read(); opcode != opPong || string(payload) != "p" {
+		t.Errorf("pong = %x %q", opcode, payload)
+	}
+	if opcode, _, payload := client.read(); opcode != opBinary || string(payload) != "fragment" {
+		t.Errorf("fragmented echo = %x %q", opcode, payload)
+	}
+	client.write(true, false, opText, bytes.Repeat([]byte("x"), 65))
+	client.expectClose(WebSocketCloseMessageTooBig)
+	client.conn.Close()
+
+	// permessage-deflate
+	client, response = dialWebSocket(t, server, "/ws", http.Header{"Sec-Websocket-Extensions": {"permessage-deflate; client_max_window_bits"}})
+	if !strings.HasPrefix(response.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate") {
+		t.Fatalf("extensions = %q", response.Header.Get("Sec-WebSocket-Extensions"))
+	}
+	<-status
+	var buffer bytes.Buffer
+	writer, _ := flate.NewWriter(&buffer, flate.BestCompression)
+	writer.Write([]byte(strings.Repeat("goi ", 16)))
+	writer.Flush()
+	client.write(true, true, opText, bytes.TrimSuffix(buffer.Bytes(), []byte{0, 0, 0xff, 0xff}))
+	opcode, rsv1, payload := client.read()
+	decompressed, _ := io.ReadAll(flate.NewReader(io.MultiReader(bytes.NewReader(payload), strings.NewReader(deflateTail))))
+	if opcode != opText || !rsv1 || string(decompressed) != strings.Repeat("goi ", 16) {
+		t.Errorf("compressed echo = %x %v %q", opcode, rsv1, decompressed)
+	}
+
+	// 服务停止时发送 1001 关闭帧
+	closed := make(chan int, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { closed <- engine.webSockets.closeAll(ctx) }()
+	client.expectClose(WebSocketCloseGoingAway)
+	client.write(true, false, opClose, binary.BigEndian.AppendUint16(nil, WebSocketCloseGoingAway))
+	select {
+	case count := <-closed:
+		if count != 1 {
+			t.Errorf("closeAll = %d", count)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("closeAll did not return")
+	}
+	client.conn.Close()
+
+	// 来源校验与非升级请求
+	_, response = dialWebSocket(t, server, "/ws", http.Header{"Origin": {"http://evil.example"}})
+	if response.StatusCode != http.StatusForbidden {
+		t.Errorf("cross origin status = %d", response.StatusCode)
+	}
+	<-status
+	plain, err := http.Get(server.URL + "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain.Body.Close()
+	if plain.StatusCode != http.StatusUpgradeRequired || plain.Header.Get("Upgrade") != "websocket" {
+		t.Errorf("plain GET = %d %v", plain.StatusCode, plain.Header)
+	}
+}